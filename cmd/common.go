@@ -8,6 +8,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/bootstrap"
 	"github.com/OpenListTeam/OpenList/v4/internal/bootstrap/data"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
@@ -23,6 +24,9 @@ func Init() {
 }
 
 func Release() {
+	if err := traffic.Flush(); err != nil {
+		log.Errorf("failed flush traffic: %+v", err)
+	}
 	db.Close()
 }
 
@@ -0,0 +1,57 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/bootstrap"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fuse"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/spf13/cobra"
+)
+
+var mountRoot string
+var mountOptions []string
+
+// MountCmd represents the mount command, it needs the libfuse headers
+// at build time, so it is only built with the fuse tag
+var MountCmd = &cobra.Command{
+	Use:   "mount <mountpoint>",
+	Short: "Mount the storages as a local file system with FUSE",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		bootstrap.LoadStorages()
+		bootstrap.InitTaskManager()
+		admin, err := op.GetAdmin()
+		if err != nil {
+			utils.Log.Errorf("failed get admin user: %+v", err)
+			return
+		}
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		ctx = context.WithValue(ctx, conf.UserKey, admin)
+		ctx = context.WithValue(ctx, conf.ApiUrlKey, common.GetApiUrlFromRequest(nil))
+		opts := make([]string, 0, len(mountOptions)*2)
+		for _, o := range mountOptions {
+			opts = append(opts, "-o", o)
+		}
+		utils.Log.Infof("mount [%s] on %s", mountRoot, args[0])
+		if !fuse.Mount(ctx, mountRoot, args[0], opts) {
+			utils.Log.Errorf("failed to mount on %s", args[0])
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(MountCmd)
+	MountCmd.Flags().StringVar(&mountRoot, "path", "/", "the OpenList path to mount")
+	MountCmd.Flags().StringArrayVarP(&mountOptions, "option", "o", nil, "FUSE mount options, e.g. -o allow_other")
+}
@@ -19,7 +19,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server"
-	"github.com/OpenListTeam/sftpd-openlist"
+	"github.com/OpenListTeam/OpenList/v4/server/sftp"
 	ftpserver "github.com/fclairamb/ftpserverlib"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -42,6 +42,8 @@ the address is defined in config file`,
 		}
 		bootstrap.InitOfflineDownloadTools()
 		bootstrap.LoadStorages()
+		bootstrap.InitRecycleBin()
+		bootstrap.InitAuditLog()
 		bootstrap.InitTaskManager()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
@@ -140,7 +142,7 @@ the address is defined in config file`,
 			}
 		}
 		var sftpDriver *server.SftpDriver
-		var sftpServer *sftpd.SftpServer
+		var sftpServer *sftp.Server
 		if conf.Conf.SFTP.Listen != "" && conf.Conf.SFTP.Enable {
 			var err error
 			sftpDriver, err = server.NewSftpDriver()
@@ -149,7 +151,7 @@ the address is defined in config file`,
 			} else {
 				utils.Log.Infof("start sftp server on %s", conf.Conf.SFTP.Listen)
 				go func() {
-					sftpServer = sftpd.NewSftpServer(sftpDriver)
+					sftpServer = sftp.NewServer(sftpDriver)
 					err = sftpServer.RunServer()
 					if err != nil {
 						utils.Log.Fatalf("problem sftp server listening: %s", err.Error())
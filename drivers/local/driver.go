@@ -24,6 +24,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/OpenListTeam/times"
 	cp "github.com/otiai10/copy"
+	"github.com/shirou/gopsutil/v3/disk"
 	log "github.com/sirupsen/logrus"
 	_ "golang.org/x/image/webp"
 )
@@ -351,4 +352,43 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	return nil
 }
 
+func (d *Local) SetModTime(ctx context.Context, obj model.Obj, modTime time.Time) error {
+	return os.Chtimes(obj.GetPath(), modTime, modTime)
+}
+
+func (d *Local) Symlink(ctx context.Context, parentDir model.Obj, linkName, target string) error {
+	// the links are relative, so they still work if the root folder is moved
+	rel, err := filepath.Rel(parentDir.GetPath(), filepath.Join(d.GetRootPath(), target))
+	if err != nil {
+		return err
+	}
+	return os.Symlink(rel, filepath.Join(parentDir.GetPath(), linkName))
+}
+
+func (d *Local) ReadLink(ctx context.Context, obj model.Obj) (string, error) {
+	target, err := os.Readlink(obj.GetPath())
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(obj.GetPath()), target)
+	}
+	rel, err := filepath.Rel(d.GetRootPath(), target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errs.NotSupport
+	}
+	return "/" + filepath.ToSlash(rel), nil
+}
+
+func (d *Local) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	usage, err := disk.UsageWithContext(ctx, d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{TotalSpace: usage.Total, FreeSpace: usage.Free}, nil
+}
+
 var _ driver.Driver = (*Local)(nil)
+var _ driver.SetModTime = (*Local)(nil)
+var _ driver.Symlink = (*Local)(nil)
+var _ driver.WithDetails = (*Local)(nil)
@@ -17,12 +17,13 @@ type Addition struct {
 }
 
 var config = driver.Config{
-	Name:          "Local",
-	OnlyLinkMFile: false,
-	LocalSort:     true,
-	NoCache:       true,
-	DefaultRoot:   "/",
-	NoLinkURL:     true,
+	Name:           "Local",
+	OnlyLinkMFile:  false,
+	LocalSort:      true,
+	NoCache:        true,
+	DefaultRoot:    "/",
+	NoLinkURL:      true,
+	UnknownSizePut: true,
 }
 
 func init() {
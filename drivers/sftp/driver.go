@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -127,4 +128,25 @@ func (d *SFTP) Put(ctx context.Context, dstDir model.Obj, stream model.FileStrea
 	return err
 }
 
+func (d *SFTP) SetModTime(ctx context.Context, obj model.Obj, modTime time.Time) error {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return err
+	}
+	return d.client.Chtimes(obj.GetPath(), modTime, modTime)
+}
+
+func (d *SFTP) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return nil, err
+	}
+	// the remote server may not support the statvfs@openssh.com extension
+	stat, err := d.client.StatVFS(d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{TotalSpace: stat.TotalSpace(), FreeSpace: stat.FreeSpace()}, nil
+}
+
 var _ driver.Driver = (*SFTP)(nil)
+var _ driver.SetModTime = (*SFTP)(nil)
+var _ driver.WithDetails = (*SFTP)(nil)
@@ -14,12 +14,13 @@ type Addition struct {
 }
 
 var config = driver.Config{
-	Name:          "SMB",
-	LocalSort:     true,
-	OnlyLinkMFile: false,
-	DefaultRoot:   ".",
-	NoCache:       true,
-	NoLinkURL:     true,
+	Name:           "SMB",
+	LocalSort:      true,
+	OnlyLinkMFile:  false,
+	DefaultRoot:    ".",
+	NoCache:        true,
+	NoLinkURL:      true,
+	UnknownSizePut: true,
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package archives
 
 import (
+	"context"
 	"io"
 	"io/fs"
 	"os"
@@ -8,9 +9,12 @@ import (
 	"strings"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/archive/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/mholt/archives"
+	"github.com/pkg/errors"
 )
 
 type Archives struct {
@@ -134,6 +138,34 @@ func (Archives) Decompress(ss []*stream.SeekableStream, outputPath string, args
 	return filterPassword(err)
 }
 
+func (Archives) AcceptedCompressExtensions() []string {
+	return []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tar.xz", ".tar.zst"}
+}
+
+func (Archives) Compress(files []tool.CompressFile, w io.Writer, args model.ArchiveCompressArgs, up model.UpdateProgress) error {
+	format, ok := compressFormats[args.Format]
+	if !ok {
+		return errs.UnknownArchiveFormat
+	}
+	if args.Password != "" {
+		return errors.WithMessagef(errs.NotSupport, "%s archive can't be encrypted", args.Format)
+	}
+	opened := 0
+	infos := make([]archives.FileInfo, 0, len(files))
+	for _, file := range files {
+		infos = append(infos, toArchivesFileInfo(file, func() {
+			up(float64(opened) * 100.0 / float64(len(files)))
+			opened++
+		}))
+	}
+	err := format.Archive(context.Background(), w, infos)
+	if err != nil {
+		return err
+	}
+	up(100.0)
+	return nil
+}
+
 var _ tool.Tool = (*Archives)(nil)
 
 func init() {
@@ -7,6 +7,7 @@ import (
 	stdpath "path"
 	"strings"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/archive/tool"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
@@ -83,3 +84,40 @@ func decompress(fsys fs2.FS, filePath, targetPath string, up model.UpdateProgres
 	})
 	return err
 }
+
+var compressFormats = map[string]archives.Archiver{
+	".tar":     archives.Tar{},
+	".tar.gz":  archives.CompressedArchive{Archival: archives.Tar{}, Compression: archives.Gz{}},
+	".tgz":     archives.CompressedArchive{Archival: archives.Tar{}, Compression: archives.Gz{}},
+	".tar.bz2": archives.CompressedArchive{Archival: archives.Tar{}, Compression: archives.Bz2{}},
+	".tar.xz":  archives.CompressedArchive{Archival: archives.Tar{}, Compression: archives.Xz{}},
+	".tar.zst": archives.CompressedArchive{Archival: archives.Tar{}, Compression: archives.Zstd{}},
+}
+
+type compressFile struct {
+	io.ReadCloser
+	info fs2.FileInfo
+}
+
+func (f *compressFile) Stat() (fs2.FileInfo, error) {
+	return f.info, nil
+}
+
+func toArchivesFileInfo(file tool.CompressFile, onOpen func()) archives.FileInfo {
+	info := tool.WrapFileInfo{Obj: file.Obj}
+	ret := archives.FileInfo{
+		FileInfo:      info,
+		NameInArchive: file.NameInArchive,
+	}
+	if file.Open != nil {
+		ret.Open = func() (fs2.File, error) {
+			onOpen()
+			rc, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			return &compressFile{ReadCloser: rc, info: info}, nil
+		}
+	}
+	return ret
+}
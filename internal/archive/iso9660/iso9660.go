@@ -94,6 +94,14 @@ func (ISO9660) Decompress(ss []*stream.SeekableStream, outputPath string, args m
 	return err
 }
 
+func (ISO9660) AcceptedCompressExtensions() []string {
+	return []string{}
+}
+
+func (ISO9660) Compress(files []tool.CompressFile, w io.Writer, args model.ArchiveCompressArgs, up model.UpdateProgress) error {
+	return errs.NotSupport
+}
+
 var _ tool.Tool = (*ISO9660)(nil)
 
 func init() {
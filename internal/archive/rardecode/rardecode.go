@@ -134,6 +134,14 @@ func (RarDecoder) Decompress(ss []*stream.SeekableStream, outputPath string, arg
 	return nil
 }
 
+func (RarDecoder) AcceptedCompressExtensions() []string {
+	return []string{}
+}
+
+func (RarDecoder) Compress(files []tool.CompressFile, w io.Writer, args model.ArchiveCompressArgs, up model.UpdateProgress) error {
+	return errs.NotSupport
+}
+
 var _ tool.Tool = (*RarDecoder)(nil)
 
 func init() {
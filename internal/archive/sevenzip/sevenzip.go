@@ -65,6 +65,14 @@ func (SevenZip) Decompress(ss []*stream.SeekableStream, outputPath string, args
 	return tool.DecompressFromFolderTraversal(&WrapReader{Reader: reader}, outputPath, args, up)
 }
 
+func (SevenZip) AcceptedCompressExtensions() []string {
+	return []string{}
+}
+
+func (SevenZip) Compress(files []tool.CompressFile, w io.Writer, args model.ArchiveCompressArgs, up model.UpdateProgress) error {
+	return errs.NotSupport
+}
+
 var _ tool.Tool = (*SevenZip)(nil)
 
 func init() {
@@ -19,4 +19,17 @@ type Tool interface {
 	List(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) ([]model.Obj, error)
 	Extract(ss []*stream.SeekableStream, args model.ArchiveInnerArgs) (io.ReadCloser, int64, error)
 	Decompress(ss []*stream.SeekableStream, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error
+	AcceptedCompressExtensions() []string
+	// Compress writes files into w as an archive of args.Format,
+	// tools that can only read archives should return errs.NotSupport
+	Compress(files []CompressFile, w io.Writer, args model.ArchiveCompressArgs, up model.UpdateProgress) error
+}
+
+// CompressFile is an entry that will be written into an archive
+type CompressFile struct {
+	model.Obj
+	// NameInArchive is the slash separated path of the entry in the archive
+	NameInArchive string
+	// Open returns the content of the entry, it's nil for folders
+	Open func() (io.ReadCloser, error)
 }
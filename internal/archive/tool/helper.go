@@ -113,6 +113,25 @@ type WrapFileInfo struct {
 	model.Obj
 }
 
+func (f WrapFileInfo) Name() string {
+	return f.GetName()
+}
+
+func (f WrapFileInfo) Size() int64 {
+	return f.GetSize()
+}
+
+func (f WrapFileInfo) Mode() fs.FileMode {
+	if f.IsDir() {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (f WrapFileInfo) Sys() any {
+	return f.Obj
+}
+
 func DecompressFromFolderTraversal(r ArchiveReader, outputPath string, args model.ArchiveInnerArgs, up model.UpdateProgress) error {
 	var err error
 	files := r.Files()
@@ -7,6 +7,7 @@ import (
 var (
 	Tools               = make(map[string]Tool)
 	MultipartExtensions = make(map[string]MultipartExtension)
+	CompressTools       = make(map[string]Tool)
 )
 
 func RegisterTool(tool Tool) {
@@ -17,6 +18,9 @@ func RegisterTool(tool Tool) {
 		MultipartExtensions[mainFile] = ext
 		Tools[mainFile] = tool
 	}
+	for _, ext := range tool.AcceptedCompressExtensions() {
+		CompressTools[ext] = tool
+	}
 }
 
 func GetArchiveTool(ext string) (*MultipartExtension, Tool, error) {
@@ -30,3 +34,11 @@ func GetArchiveTool(ext string) (*MultipartExtension, Tool, error) {
 	}
 	return &partExt, t, nil
 }
+
+func GetCompressTool(ext string) (Tool, error) {
+	t, ok := CompressTools[ext]
+	if !ok {
+		return nil, errs.UnknownArchiveFormat
+	}
+	return t, nil
+}
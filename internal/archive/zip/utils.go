@@ -10,6 +10,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/archive/tool"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/saintfish/chardet"
 	"github.com/yeka/zip"
 	"golang.org/x/text/encoding"
@@ -193,3 +194,33 @@ func getEncoding(name string) (enc encoding.Encoding) {
 	}
 	return
 }
+
+func writeFile(zipWriter *zip.Writer, file tool.CompressFile, password string) error {
+	header := &zip.FileHeader{
+		Name:   file.NameInArchive,
+		Method: zip.Deflate,
+		Flags:  0x800, // names are always encoded in utf-8
+	}
+	header.SetModTime(file.ModTime())
+	if file.IsDir() {
+		header.Name = strings.TrimSuffix(header.Name, "/") + "/"
+		header.Method = zip.Store
+		_, err := zipWriter.CreateHeader(header)
+		return err
+	}
+	if password != "" {
+		header.SetPassword(password)
+		header.SetEncryptionMethod(zip.AES256Encryption)
+	}
+	w, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = utils.CopyWithBuffer(w, rc)
+	return err
+}
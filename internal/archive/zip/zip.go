@@ -9,6 +9,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/yeka/zip"
 )
 
 type Zip struct {
@@ -125,6 +126,23 @@ func (Zip) Decompress(ss []*stream.SeekableStream, outputPath string, args model
 	return tool.DecompressFromFolderTraversal(&WrapReader{Reader: zipReader}, outputPath, args, up)
 }
 
+func (Zip) AcceptedCompressExtensions() []string {
+	return []string{".zip"}
+}
+
+func (Zip) Compress(files []tool.CompressFile, w io.Writer, args model.ArchiveCompressArgs, up model.UpdateProgress) error {
+	zipWriter := zip.NewWriter(w)
+	for i, file := range files {
+		err := writeFile(zipWriter, file, args.Password)
+		if err != nil {
+			_ = zipWriter.Close()
+			return err
+		}
+		up(float64(i+1) * 100.0 / float64(len(files)))
+	}
+	return zipWriter.Close()
+}
+
 var _ tool.Tool = (*Zip)(nil)
 
 func init() {
@@ -0,0 +1,20 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// InitAuditLog deletes the audit logs older than the retention days hourly
+func InitAuditLog() {
+	cron.NewCron(time.Hour).Do(func() {
+		if err := op.ExpireAuditLogs(setting.GetInt(conf.AuditLogRetention, 30)); err != nil {
+			utils.Log.Errorf("failed expire audit logs: %+v", err)
+		}
+	})
+}
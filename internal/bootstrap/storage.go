@@ -2,11 +2,13 @@ package bootstrap
 
 import (
 	"context"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 )
 
@@ -28,3 +30,10 @@ func LoadStorages() {
 		conf.StoragesLoaded = true
 	}(storages)
 }
+
+// InitRecycleBin purges the expired objects in the recycle bins hourly
+func InitRecycleBin() {
+	cron.NewCron(time.Hour).Do(func() {
+		op.ExpireRecycleItems(context.Background())
+	})
+}
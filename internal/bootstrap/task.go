@@ -3,10 +3,13 @@ package bootstrap
 import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/duplicate"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/schedule"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/syncjob"
 	"github.com/OpenListTeam/tache"
 )
 
@@ -49,4 +52,12 @@ func InitTaskManager() {
 	op.RegisterSettingChangingCallback(func() {
 		fs.ArchiveContentUploadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)))
 	})
+	fs.ArchiveCompressTaskManager = tache.NewManager[*fs.ArchiveCompressTask](tache.WithWorks(setting.GetInt(conf.TaskCompressThreadsNum, conf.Conf.Tasks.Compress.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("compress", conf.Conf.Tasks.Compress.TaskPersistant), db.UpdateTaskDataFunc("compress", conf.Conf.Tasks.Compress.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Compress.MaxRetry))
+	op.RegisterSettingChangingCallback(func() {
+		fs.ArchiveCompressTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskCompressThreadsNum, conf.Conf.Tasks.Compress.Workers)))
+	})
+	duplicate.FindTaskManager = tache.NewManager[*duplicate.FindTask](tache.WithWorks(1)) //the result is only kept in memory
+	syncjob.TaskManager = tache.NewManager[*syncjob.SyncTask](tache.WithWorks(1))
+	schedule.RunTaskManager = tache.NewManager[*schedule.RunTask](tache.WithWorks(conf.Conf.Tasks.Schedule.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("schedule", conf.Conf.Tasks.Schedule.TaskPersistant), db.UpdateTaskDataFunc("schedule", conf.Conf.Tasks.Schedule.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Schedule.MaxRetry))
+	schedule.Init()
 }
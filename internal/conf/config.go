@@ -61,6 +61,8 @@ type TasksConfig struct {
 	Move               TaskConfig `json:"move" envPrefix:"MOVE_"`
 	Decompress         TaskConfig `json:"decompress" envPrefix:"DECOMPRESS_"`
 	DecompressUpload   TaskConfig `json:"decompress_upload" envPrefix:"DECOMPRESS_UPLOAD_"`
+	Compress           TaskConfig `json:"compress" envPrefix:"COMPRESS_"`
+	Schedule           TaskConfig `json:"schedule" envPrefix:"SCHEDULE_"`
 	AllowRetryCanceled bool       `json:"allow_retry_canceled" env:"ALLOW_RETRY_CANCELED"`
 }
 
@@ -76,6 +78,11 @@ type S3 struct {
 	SSL    bool `json:"ssl" env:"SSL"`
 }
 
+type WebDAV struct {
+	// LockSystem is where the WebDAV locks are kept, "memory" or "database"
+	LockSystem string `json:"lock_system" env:"LOCK_SYSTEM"`
+}
+
 type FTP struct {
 	Enable                  bool   `json:"enable" env:"ENABLE"`
 	Listen                  string `json:"listen" env:"LISTEN"`
@@ -100,6 +107,8 @@ type Config struct {
 	Cdn                   string      `json:"cdn" env:"CDN"`
 	JwtSecret             string      `json:"jwt_secret" env:"JWT_SECRET"`
 	TokenExpiresIn        int         `json:"token_expires_in" env:"TOKEN_EXPIRES_IN"`
+	SessionStore          string      `json:"session_store" env:"SESSION_STORE"`
+	AuditLogFile          string      `json:"audit_log_file" env:"AUDIT_LOG_FILE"`
 	Database              Database    `json:"database" envPrefix:"DB_"`
 	Meilisearch           Meilisearch `json:"meilisearch" envPrefix:"MEILISEARCH_"`
 	Scheme                Scheme      `json:"scheme"`
@@ -114,6 +123,7 @@ type Config struct {
 	Tasks                 TasksConfig `json:"tasks" envPrefix:"TASKS_"`
 	Cors                  Cors        `json:"cors" envPrefix:"CORS_"`
 	S3                    S3          `json:"s3" envPrefix:"S3_"`
+	WebDAV                WebDAV      `json:"webdav" envPrefix:"WEBDAV_"`
 	FTP                   FTP         `json:"ftp" envPrefix:"FTP_"`
 	SFTP                  SFTP        `json:"sftp" envPrefix:"SFTP_"`
 	LastLaunchedVersion   string      `json:"last_launched_version"`
@@ -136,6 +146,7 @@ func DefaultConfig() *Config {
 		},
 		JwtSecret:      random.String(16),
 		TokenExpiresIn: 48,
+		SessionStore:   "database",
 		TempDir:        tempDir,
 		Database: Database{
 			Type:        "sqlite3",
@@ -190,6 +201,15 @@ func DefaultConfig() *Config {
 				Workers:  5,
 				MaxRetry: 2,
 			},
+			Compress: TaskConfig{
+				Workers:  5,
+				MaxRetry: 2,
+				// TaskPersistant: true,
+			},
+			Schedule: TaskConfig{
+				Workers: 3,
+				// TaskPersistant: true,
+			},
 			AllowRetryCanceled: false,
 		},
 		Cors: Cors{
@@ -202,6 +222,9 @@ func DefaultConfig() *Config {
 			Port:   5246,
 			SSL:    false,
 		},
+		WebDAV: WebDAV{
+			LockSystem: "memory",
+		},
 		FTP: FTP{
 			Enable:                  false,
 			Listen:                  ":5221",
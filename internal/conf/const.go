@@ -45,6 +45,8 @@ const (
 	ForwardDirectLinkParams = "forward_direct_link_params"
 	IgnoreDirectLinkParams  = "ignore_direct_link_params"
 	WebauthnLoginEnabled    = "webauthn_login_enabled"
+	AuditLogEnabled         = "audit_log_enabled"
+	AuditLogRetention       = "audit_log_retention"
 
 	// index
 	SearchIndex     = "search_index"
@@ -132,12 +134,17 @@ const (
 	TaskMoveThreadsNum                    = "move_task_threads_num"
 	TaskDecompressDownloadThreadsNum      = "decompress_download_task_threads_num"
 	TaskDecompressUploadThreadsNum        = "decompress_upload_task_threads_num"
+	TaskCompressThreadsNum                = "compress_task_threads_num"
 	StreamMaxClientDownloadSpeed          = "max_client_download_speed"
 	StreamMaxClientUploadSpeed            = "max_client_upload_speed"
 	StreamMaxServerDownloadSpeed          = "max_server_download_speed"
 	StreamMaxServerUploadSpeed            = "max_server_upload_speed"
 )
 
+// S3MultipartDir is the dir in the temp dir where the parts of the multipart
+// uploads of the S3 server are staged, it's kept when the temp dir is cleaned
+const S3MultipartDir = "s3_multipart"
+
 const (
 	UNKNOWN = iota
 	FOLDER
@@ -164,4 +171,8 @@ const (
 	RequestHeaderKey
 	UserAgentKey
 	PathKey
+	APITokenKey
+	ShareKey
+	ProtocolKey
+	SignerKey
 )
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetACLRules() ([]model.ACLRule, error) {
+	var rules []model.ACLRule
+	if err := db.Order(columnName("path")).Find(&rules).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find acl rules")
+	}
+	return rules, nil
+}
+
+func GetACLRuleById(id uint) (*model.ACLRule, error) {
+	var r model.ACLRule
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get acl rule")
+	}
+	return &r, nil
+}
+
+func CreateACLRule(r *model.ACLRule) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateACLRule(r *model.ACLRule) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func DeleteACLRuleById(id uint) error {
+	return errors.WithStack(db.Delete(&model.ACLRule{}, id).Error)
+}
+
+func DeleteACLRulesByUserId(userID uint) error {
+	return errors.WithStack(db.Where(columnName("user_id")+" = ?", userID).Delete(&model.ACLRule{}).Error)
+}
+
+func DeleteACLRulesByGroupId(groupID uint) error {
+	return errors.WithStack(db.Where(columnName("group_id")+" = ?", groupID).Delete(&model.ACLRule{}).Error)
+}
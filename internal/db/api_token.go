@@ -0,0 +1,47 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetAPITokenByKeyID(keyID string) (*model.APIToken, error) {
+	var t model.APIToken
+	if err := db.Where(columnName("key_id")+" = ?", keyID).First(&t).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get api token")
+	}
+	return &t, nil
+}
+
+func GetAPITokensByUserId(userID uint) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	if err := db.Where(columnName("user_id")+" = ?", userID).Order(columnName("id")).Find(&tokens).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find api tokens")
+	}
+	return tokens, nil
+}
+
+func CreateAPIToken(t *model.APIToken) error {
+	return errors.WithStack(db.Create(t).Error)
+}
+
+func UpdateAPITokenLastUsed(id uint, lastUsed time.Time) error {
+	return errors.WithStack(db.Model(&model.APIToken{}).Where(columnName("id")+" = ?", id).Update("last_used", lastUsed).Error)
+}
+
+func DeleteAPIToken(userID, id uint) error {
+	res := db.Where(columnName("user_id")+" = ? AND "+columnName("id")+" = ?", userID, id).Delete(&model.APIToken{})
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("api token not found")
+	}
+	return nil
+}
+
+func DeleteAPITokensByUserId(userID uint) error {
+	return errors.WithStack(db.Where(columnName("user_id")+" = ?", userID).Delete(&model.APIToken{}).Error)
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateAuditLogs(logs []*model.AuditLog) error {
+	return errors.WithStack(db.Create(logs).Error)
+}
+
+func GetAuditLogs(filter model.AuditLogFilter, pageIndex, pageSize int) (logs []model.AuditLog, count int64, err error) {
+	logDB := db.Model(&model.AuditLog{})
+	if filter.Username != "" {
+		logDB = logDB.Where(columnName("username")+" = ?", filter.Username)
+	}
+	if filter.Protocol != "" {
+		logDB = logDB.Where(columnName("protocol")+" = ?", filter.Protocol)
+	}
+	if filter.Operation != "" {
+		logDB = logDB.Where(columnName("operation")+" = ?", filter.Operation)
+	}
+	if filter.Path != "" {
+		pattern := "%" + filter.Path + "%"
+		logDB = logDB.Where("("+columnName("path")+" LIKE ? OR "+columnName("dst_path")+" LIKE ?)", pattern, pattern)
+	}
+	if filter.Success != nil {
+		logDB = logDB.Where(columnName("success")+" = ?", *filter.Success)
+	}
+	if !filter.From.IsZero() {
+		logDB = logDB.Where(columnName("time")+" >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		logDB = logDB.Where(columnName("time")+" < ?", filter.To)
+	}
+	if err = logDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	if err = logDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+// DeleteAuditLogsBefore deletes the entries older than t
+func DeleteAuditLogsBefore(t time.Time) error {
+	return errors.WithStack(db.Where(columnName("time")+" < ?", t).Delete(&model.AuditLog{}).Error)
+}
@@ -12,7 +12,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.WebDAVLock), new(model.WebDAVLockGuard), new(model.WebDAVProp), new(model.SyncJob), new(model.SyncEntry), new(model.Schedule), new(model.RecycleItem), new(model.APIToken), new(model.Session), new(model.Share), new(model.Group), new(model.ACLRule), new(model.Quota), new(model.QuotaFile), new(model.Traffic), new(model.AuditLog), new(model.Webhook), new(model.WebhookDelivery), new(model.S3MultipartUpload), new(model.S3MultipartPart), new(model.FTPCert), new(model.FTPPassword))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetFTPCertsByUserId(userId uint, pageIndex, pageSize int) (certs []model.FTPCert, count int64, err error) {
+	certDB := db.Model(&model.FTPCert{})
+	query := model.FTPCert{UserId: userId}
+	if err := certDB.Where(query).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get user's certs count")
+	}
+	if err := certDB.Where(query).Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&certs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find user's certs")
+	}
+	return certs, count, nil
+}
+
+func GetFTPCertById(id uint) (*model.FTPCert, error) {
+	var c model.FTPCert
+	if err := db.First(&c, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get ftp cert")
+	}
+	return &c, nil
+}
+
+func GetFTPCertByFingerprint(userId uint, fingerprint string) (*model.FTPCert, error) {
+	c := model.FTPCert{UserId: userId, Fingerprint: fingerprint}
+	if err := db.Where(c).First(&c).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find cert with fingerprint of user")
+	}
+	return &c, nil
+}
+
+func GetFTPCertByUserTitle(userId uint, title string) (*model.FTPCert, error) {
+	c := model.FTPCert{UserId: userId, Title: title}
+	if err := db.Where(c).First(&c).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find cert with title of user")
+	}
+	return &c, nil
+}
+
+func CreateFTPCert(c *model.FTPCert) error {
+	return errors.WithStack(db.Create(c).Error)
+}
+
+func UpdateFTPCert(c *model.FTPCert) error {
+	return errors.WithStack(db.Save(c).Error)
+}
+
+func DeleteFTPCertById(id uint) error {
+	return errors.WithStack(db.Delete(&model.FTPCert{}, id).Error)
+}
+
+func GetFTPPasswordsByUserId(userId uint, pageIndex, pageSize int) (pwds []model.FTPPassword, count int64, err error) {
+	pwdDB := db.Model(&model.FTPPassword{})
+	query := model.FTPPassword{UserId: userId}
+	if err := pwdDB.Where(query).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get user's ftp passwords count")
+	}
+	if err := pwdDB.Where(query).Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&pwds).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find user's ftp passwords")
+	}
+	return pwds, count, nil
+}
+
+// GetAllFTPPasswordsByUserId returns all the ftp passwords of the user
+func GetAllFTPPasswordsByUserId(userId uint) ([]model.FTPPassword, error) {
+	var pwds []model.FTPPassword
+	if err := db.Where(model.FTPPassword{UserId: userId}).Find(&pwds).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find user's ftp passwords")
+	}
+	return pwds, nil
+}
+
+func GetFTPPasswordById(id uint) (*model.FTPPassword, error) {
+	var p model.FTPPassword
+	if err := db.First(&p, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get ftp password")
+	}
+	return &p, nil
+}
+
+func GetFTPPasswordByUserTitle(userId uint, title string) (*model.FTPPassword, error) {
+	p := model.FTPPassword{UserId: userId, Title: title}
+	if err := db.Where(p).First(&p).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find ftp password with title of user")
+	}
+	return &p, nil
+}
+
+func CreateFTPPassword(p *model.FTPPassword) error {
+	return errors.WithStack(db.Create(p).Error)
+}
+
+func UpdateFTPPassword(p *model.FTPPassword) error {
+	return errors.WithStack(db.Save(p).Error)
+}
+
+func DeleteFTPPasswordById(id uint) error {
+	return errors.WithStack(db.Delete(&model.FTPPassword{}, id).Error)
+}
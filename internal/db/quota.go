@@ -0,0 +1,129 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func GetQuotaByUserId(userID uint) (*model.Quota, error) {
+	var q model.Quota
+	if err := db.Where(columnName("user_id")+" = ?", userID).First(&q).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get quota")
+	}
+	return &q, nil
+}
+
+func GetQuotas() ([]model.Quota, error) {
+	var quotas []model.Quota
+	if err := db.Order(columnName("user_id")).Find(&quotas).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find quotas")
+	}
+	return quotas, nil
+}
+
+func SaveQuota(q *model.Quota) error {
+	return errors.WithStack(db.Save(q).Error)
+}
+
+// ReserveQuotaUsage adds the deltas to the usage of the quota only if the limits
+// are not exceeded, it returns false if not added or the user has no quota
+func ReserveQuotaUsage(userID uint, bytes, files int64) (bool, error) {
+	usedBytes, maxBytes := columnName("used_bytes"), columnName("max_bytes")
+	usedFiles, maxFiles := columnName("used_files"), columnName("max_files")
+	res := db.Model(&model.Quota{}).
+		Where(columnName("user_id")+" = ?", userID).
+		Where("? <= 0 OR "+maxBytes+" = 0 OR "+usedBytes+" + ? <= "+maxBytes, bytes, bytes).
+		Where("? <= 0 OR "+maxFiles+" = 0 OR "+usedFiles+" + ? <= "+maxFiles, files, files).
+		Updates(map[string]any{
+			"used_bytes": gorm.Expr(usedBytes+" + ?", bytes),
+			"used_files": gorm.Expr(usedFiles+" + ?", files),
+		})
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// AddQuotaUsage adds the deltas to the usage of the quota, the usage never goes below 0
+func AddQuotaUsage(userID uint, bytes, files int64) error {
+	clamp := func(column string, delta int64) clause.Expr {
+		c := columnName(column)
+		return gorm.Expr("CASE WHEN "+c+" + ? < 0 THEN 0 ELSE "+c+" + ? END", delta, delta)
+	}
+	return errors.WithStack(db.Model(&model.Quota{}).Where(columnName("user_id")+" = ?", userID).Updates(map[string]any{
+		"used_bytes": clamp("used_bytes", bytes),
+		"used_files": clamp("used_files", files),
+	}).Error)
+}
+
+func DeleteQuotaByUserId(userID uint) error {
+	return errors.WithStack(db.Where(columnName("user_id")+" = ?", userID).Delete(&model.Quota{}).Error)
+}
+
+func GetQuotaFile(storageID uint, path string) (*model.QuotaFile, error) {
+	var f model.QuotaFile
+	if err := db.Where(columnName("storage_id")+" = ? AND "+columnName("path")+" = ?", storageID, path).First(&f).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get quota file")
+	}
+	return &f, nil
+}
+
+// SaveQuotaFile replaces the record of the file at the same path
+func SaveQuotaFile(f *model.QuotaFile) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := quotaFilesAt(tx, f.StorageID, f.Path, false).Delete(&model.QuotaFile{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(f).Error
+	}))
+}
+
+func DeleteQuotaFile(storageID uint, path string) error {
+	return errors.WithStack(quotaFilesAt(db, storageID, path, false).Delete(&model.QuotaFile{}).Error)
+}
+
+// GetQuotaFilesByPath returns the records of the files at path or under it
+func GetQuotaFilesByPath(storageID uint, path string) ([]model.QuotaFile, error) {
+	var files []model.QuotaFile
+	if err := quotaFilesAt(db, storageID, path, true).Find(&files).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find quota files")
+	}
+	return files, nil
+}
+
+func DeleteQuotaFilesByPath(storageID uint, path string) error {
+	return errors.WithStack(quotaFilesAt(db, storageID, path, true).Delete(&model.QuotaFile{}).Error)
+}
+
+// MoveQuotaFiles changes the paths of the records at srcPath or under it to dstPath
+func MoveQuotaFiles(storageID uint, srcPath, dstPath string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		var files []model.QuotaFile
+		if err := quotaFilesAt(tx, storageID, srcPath, true).Find(&files).Error; err != nil {
+			return err
+		}
+		for _, f := range files {
+			p := dstPath + strings.TrimPrefix(f.Path, srcPath)
+			if err := tx.Model(&f).Update("path", p).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func DeleteQuotaFilesByStorage(storageID uint) error {
+	return errors.WithStack(db.Where(columnName("storage_id")+" = ?", storageID).Delete(&model.QuotaFile{}).Error)
+}
+
+func quotaFilesAt(tx *gorm.DB, storageID uint, path string, sub bool) *gorm.DB {
+	tx = tx.Where(columnName("storage_id")+" = ?", storageID)
+	if !sub {
+		return tx.Where(columnName("path")+" = ?", path)
+	}
+	return tx.Where("("+columnName("path")+" = ? OR "+columnName("path")+" LIKE ? ESCAPE ?)", path, subPathPattern(path), likeEscapeChar)
+}
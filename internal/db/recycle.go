@@ -0,0 +1,65 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetRecycleItemById(id uint) (*model.RecycleItem, error) {
+	var item model.RecycleItem
+	if err := db.First(&item, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get recycle item")
+	}
+	return &item, nil
+}
+
+func CreateRecycleItem(item *model.RecycleItem) error {
+	return errors.WithStack(db.Create(item).Error)
+}
+
+// GetRecycleItems returns the items of a storage, or of all storages if storageID is 0
+func GetRecycleItems(storageID uint, pageIndex, pageSize int) (items []model.RecycleItem, count int64, err error) {
+	itemDB := db.Model(&model.RecycleItem{})
+	if storageID != 0 {
+		itemDB = itemDB.Where(columnName("storage_id")+" = ?", storageID)
+	}
+	if err = itemDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get recycle items count")
+	}
+	if err = itemDB.Order(columnName("deleted") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find recycle items")
+	}
+	return items, count, nil
+}
+
+func GetAllRecycleItems(storageID uint) ([]model.RecycleItem, error) {
+	var items []model.RecycleItem
+	if err := db.Where(columnName("storage_id")+" = ?", storageID).Find(&items).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find recycle items")
+	}
+	return items, nil
+}
+
+func GetExpiredRecycleItems(storageID uint, before time.Time) ([]model.RecycleItem, error) {
+	var items []model.RecycleItem
+	if err := db.Where(columnName("storage_id")+" = ? AND "+columnName("deleted")+" < ?", storageID, before).Find(&items).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find expired recycle items")
+	}
+	return items, nil
+}
+
+func DeleteRecycleItemById(id uint) error {
+	return errors.WithStack(db.Delete(&model.RecycleItem{}, id).Error)
+}
+
+// DeleteRecycleItemsByPath deletes the items in the recycle bin at path or under it
+func DeleteRecycleItemsByPath(storageID uint, path string) error {
+	return errors.WithStack(db.Where(columnName("storage_id")+" = ? AND ("+columnName("trash_path")+" = ? OR "+columnName("trash_path")+" LIKE ? ESCAPE ?)",
+		storageID, path, subPathPattern(path), likeEscapeChar).Delete(&model.RecycleItem{}).Error)
+}
+
+func DeleteRecycleItemsByStorage(storageID uint) error {
+	return errors.WithStack(db.Where(columnName("storage_id")+" = ?", storageID).Delete(&model.RecycleItem{}).Error)
+}
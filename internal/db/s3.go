@@ -0,0 +1,75 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateS3MultipartUpload(u *model.S3MultipartUpload) error {
+	return errors.WithStack(db.Create(u).Error)
+}
+
+func GetS3MultipartUpload(id string) (*model.S3MultipartUpload, error) {
+	var u model.S3MultipartUpload
+	if err := db.Where("id = ?", id).First(&u).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find s3 multipart upload")
+	}
+	return &u, nil
+}
+
+// GetS3MultipartUploads returns the uploads of the user in the bucket ordered by key and id
+func GetS3MultipartUploads(userID uint, bucket string) ([]model.S3MultipartUpload, error) {
+	var uploads []model.S3MultipartUpload
+	err := db.Where(fmt.Sprintf("%s = ? AND bucket = ?", columnName("user_id")), userID, bucket).
+		Order(columnName("object_key")).Order("id").Find(&uploads).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 multipart uploads")
+	}
+	return uploads, nil
+}
+
+// GetS3MultipartUploadsBefore returns the uploads that haven't been updated since t
+func GetS3MultipartUploadsBefore(t time.Time) ([]model.S3MultipartUpload, error) {
+	var uploads []model.S3MultipartUpload
+	if err := db.Where("updated < ?", t).Find(&uploads).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 multipart uploads")
+	}
+	return uploads, nil
+}
+
+func GetS3MultipartParts(uploadID string) ([]model.S3MultipartPart, error) {
+	var parts []model.S3MultipartPart
+	err := db.Where(fmt.Sprintf("%s = ?", columnName("upload_id")), uploadID).
+		Order(columnName("part_number")).Find(&parts).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get s3 multipart parts")
+	}
+	return parts, nil
+}
+
+// SaveS3MultipartPart creates or replaces the part and touches its upload
+func SaveS3MultipartPart(p *model.S3MultipartPart) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(p).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.S3MultipartUpload{}).Where("id = ?", p.UploadID).
+			Update("updated", p.Updated).Error
+	}))
+}
+
+// DeleteS3MultipartUpload deletes the upload and its parts
+func DeleteS3MultipartUpload(id string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(fmt.Sprintf("%s = ?", columnName("upload_id")), id).
+			Delete(&model.S3MultipartPart{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.S3MultipartUpload{}).Error
+	}))
+}
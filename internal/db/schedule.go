@@ -0,0 +1,45 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetScheduleById(id uint) (*model.Schedule, error) {
+	var s model.Schedule
+	if err := db.First(&s, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get schedule")
+	}
+	return &s, nil
+}
+
+func CreateSchedule(s *model.Schedule) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateSchedule(s *model.Schedule) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+func GetSchedules(pageIndex, pageSize int) (schedules []model.Schedule, count int64, err error) {
+	scheduleDB := db.Model(&model.Schedule{})
+	if err = scheduleDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get schedules count")
+	}
+	if err = scheduleDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&schedules).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get find schedules")
+	}
+	return schedules, count, nil
+}
+
+func GetEnabledSchedules() ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	if err := db.Where(columnName("disabled")+" = ?", false).Find(&schedules).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get enabled schedules")
+	}
+	return schedules, nil
+}
+
+func DeleteScheduleById(id uint) error {
+	return errors.WithStack(db.Delete(&model.Schedule{}, id).Error)
+}
@@ -56,7 +56,8 @@ func GetSearchNodesByParent(parent string) ([]model.SearchNode, error) {
 
 func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int64, error) {
 	var searchDB *gorm.DB
-	if !useFullText || conf.Conf.Database.Type == "sqlite3" {
+	// full text search can't match all without keywords
+	if !useFullText || conf.Conf.Database.Type == "sqlite3" || len(strings.Fields(req.Keywords)) == 0 {
 		keywordsClause := db.Where("1 = 1")
 		for _, keyword := range strings.Fields(req.Keywords) {
 			keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
@@ -77,15 +78,47 @@ func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int6
 		isDir := req.Scope == 1
 		searchDB.Where(db.Where("is_dir = ?", isDir))
 	}
+	searchDB = whereSearchFilters(searchDB, req)
 
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
+	orderBy, desc := req.Order()
+	order := columnName(orderBy) + " asc"
+	if desc {
+		order = columnName(orderBy) + " desc"
+	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Order(order).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
 	return files, count, nil
 }
+
+func whereSearchFilters(searchDB *gorm.DB, req model.SearchReq) *gorm.DB {
+	if req.MinSize > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("size")), req.MinSize)
+	}
+	if req.MaxSize > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("size")), req.MaxSize)
+	}
+	if req.ModifiedAfter != nil {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("modified")), *req.ModifiedAfter)
+	}
+	if req.ModifiedBefore != nil {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("modified")), *req.ModifiedBefore)
+	}
+	if len(req.Exts) > 0 {
+		extsClause := db.Where("1 = 0")
+		for _, ext := range req.Exts {
+			extsClause = extsClause.Or("LOWER(name) LIKE ?", fmt.Sprintf("%%.%s", ext))
+		}
+		searchDB = searchDB.Where(extsClause)
+	}
+	if len(req.Types) > 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s IN ?", columnName("type")), req.Types)
+	}
+	return searchDB
+}
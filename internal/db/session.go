@@ -0,0 +1,41 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetSessionById(id string) (*model.Session, error) {
+	var s model.Session
+	if err := db.Where(columnName("id")+" = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get session")
+	}
+	return &s, nil
+}
+
+func CreateSession(s *model.Session) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func GetSessionsByUserId(userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+	if err := db.Where(columnName("user_id")+" = ? AND "+columnName("expires")+" > ?", userID, time.Now()).
+		Order(columnName("created") + " DESC").Find(&sessions).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find sessions")
+	}
+	return sessions, nil
+}
+
+func DeleteSessionById(id string) error {
+	return errors.WithStack(db.Where(columnName("id")+" = ?", id).Delete(&model.Session{}).Error)
+}
+
+func DeleteSessionsByUserId(userID uint) error {
+	return errors.WithStack(db.Where(columnName("user_id")+" = ?", userID).Delete(&model.Session{}).Error)
+}
+
+func DeleteExpiredSessions(now time.Time) error {
+	return errors.WithStack(db.Where(columnName("expires")+" <= ?", now).Delete(&model.Session{}).Error)
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetShareById(id string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where(columnName("id")+" = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func GetShares(pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	shareDB := db.Model(&model.Share{})
+	if err = shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get shares count")
+	}
+	if err = shareDB.Order(columnName("created") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find shares")
+	}
+	return shares, count, nil
+}
+
+func GetSharesByUserId(userID uint) ([]model.Share, error) {
+	var shares []model.Share
+	if err := db.Where(columnName("user_id")+" = ?", userID).Order(columnName("created") + " DESC").Find(&shares).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find shares")
+	}
+	return shares, nil
+}
+
+func CreateShare(s *model.Share) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateShare(s *model.Share) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+// IncreaseShareViews counts a visit to a folder of the share
+func IncreaseShareViews(id string, now time.Time) error {
+	return errors.WithStack(db.Model(&model.Share{}).Where(columnName("id")+" = ?", id).Updates(map[string]any{
+		"views":       gorm.Expr(columnName("views") + " + 1"),
+		"last_access": now,
+	}).Error)
+}
+
+// IncreaseShareDownloads counts a download of the share, it returns false
+// without counting if the max downloads has been reached
+func IncreaseShareDownloads(id string, now time.Time) (bool, error) {
+	res := db.Model(&model.Share{}).
+		Where(columnName("id")+" = ? AND ("+columnName("max_downloads")+" = 0 OR "+
+			columnName("downloads")+" < "+columnName("max_downloads")+")", id).
+		Updates(map[string]any{
+			"downloads":   gorm.Expr(columnName("downloads") + " + 1"),
+			"last_access": now,
+		})
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func DeleteShareById(id string) error {
+	return errors.WithStack(db.Where(columnName("id")+" = ?", id).Delete(&model.Share{}).Error)
+}
+
+func DeleteSharesByUserId(userID uint) error {
+	return errors.WithStack(db.Where(columnName("user_id")+" = ?", userID).Delete(&model.Share{}).Error)
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetSyncJobById(id uint) (*model.SyncJob, error) {
+	var j model.SyncJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get sync job")
+	}
+	return &j, nil
+}
+
+func CreateSyncJob(j *model.SyncJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+func UpdateSyncJob(j *model.SyncJob) error {
+	return errors.WithStack(db.Save(j).Error)
+}
+
+func GetSyncJobs(pageIndex, pageSize int) (jobs []model.SyncJob, count int64, err error) {
+	jobDB := db.Model(&model.SyncJob{})
+	if err = jobDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get sync jobs count")
+	}
+	if err = jobDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&jobs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get find sync jobs")
+	}
+	return jobs, count, nil
+}
+
+func DeleteSyncJobById(id uint) error {
+	return errors.WithStack(db.Delete(&model.SyncJob{}, id).Error)
+}
+
+func GetSyncEntries(jobID uint) ([]model.SyncEntry, error) {
+	var entries []model.SyncEntry
+	if err := db.Where(columnName("job_id")+" = ?", jobID).Find(&entries).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find sync entries")
+	}
+	return entries, nil
+}
+
+// SaveSyncEntries replaces the entries of the job
+func SaveSyncEntries(jobID uint, entries []model.SyncEntry) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("job_id")+" = ?", jobID).Delete(&model.SyncEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(entries, 100).Error
+	}))
+}
+
+func DeleteSyncEntries(jobID uint) error {
+	return errors.WithStack(db.Where(columnName("job_id")+" = ?", jobID).Delete(&model.SyncEntry{}).Error)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddTraffic adds the bytes to the traffic of the user in all the periods
+func AddTraffic(userID uint, download, upload int64, periods ...string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		for _, period := range periods {
+			t := model.Traffic{UserID: userID, Period: period, Download: download, Upload: upload}
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "user_id"}, {Name: "period"}},
+				DoUpdates: clause.Assignments(map[string]any{
+					"download": gorm.Expr(columnName("download")+" + ?", download),
+					"upload":   gorm.Expr(columnName("upload")+" + ?", upload),
+				}),
+			}).Create(&t).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func GetTraffic(userID uint, period string) (*model.Traffic, error) {
+	var t model.Traffic
+	err := db.Where(columnName("user_id")+" = ? AND "+columnName("period")+" = ?", userID, period).
+		Take(&t).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get traffic")
+	}
+	return &t, nil
+}
+
+// GetTraffics returns the traffic of the periods, of all users if userID is 0
+func GetTraffics(userID uint, periods ...string) ([]model.Traffic, error) {
+	var traffics []model.Traffic
+	tx := db.Where(columnName("period")+" IN ?", periods)
+	if userID != 0 {
+		tx = tx.Where(columnName("user_id")+" = ?", userID)
+	}
+	if err := tx.Order(columnName("user_id")).Order(columnName("period")).Find(&traffics).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find traffics")
+	}
+	return traffics, nil
+}
+
+func DeleteTrafficsByUserId(userID uint) error {
+	return errors.WithStack(db.Where(columnName("user_id")+" = ?", userID).Delete(&model.Traffic{}).Error)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"gorm.io/gorm"
@@ -17,3 +18,15 @@ func columnName(name string) string {
 func addStorageOrder(db *gorm.DB) *gorm.DB {
 	return db.Order(fmt.Sprintf("%s, %s", columnName("order"), columnName("id")))
 }
+
+// likeEscapeChar is the escape char of the patterns made by subPathPattern, it's
+// passed as an argument of ESCAPE so that no database parses it as a literal
+const likeEscapeChar = `\`
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// subPathPattern returns the pattern of LIKE that matches the paths under path,
+// it must be used with ESCAPE likeEscapeChar
+func subPathPattern(path string) string {
+	return strings.TrimSuffix(likeEscaper.Replace(path), "/") + "/%"
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"fmt"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func whereWebDAVLockNotExpired(tx *gorm.DB, now time.Time) *gorm.DB {
+	return tx.Where(fmt.Sprintf("%s < 0 OR %s > ?", columnName("duration"), columnName("expiry")), now)
+}
+
+func GetWebDAVLockByToken(token string, now time.Time) (*model.WebDAVLock, error) {
+	var l model.WebDAVLock
+	if err := whereWebDAVLockNotExpired(db, now).Where("token = ?", token).First(&l).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find webdav lock")
+	}
+	return &l, nil
+}
+
+// CreateWebDAVLock creates l unless it conflicts with an existing lock, ancestors
+// are the names from the parent of l.Root up to "/". It returns false if l conflicts.
+func CreateWebDAVLock(l *model.WebDAVLock, ancestors []string, now time.Time) (bool, error) {
+	created := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// the conflicts can't be checked by a unique index, since a lock conflicts
+		// with the locks of its ancestors and descendants, so the creations are
+		// serialized by the row lock of the guard until the transaction ends
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.WebDAVLockGuard{ID: 1}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.WebDAVLockGuard{ID: 1}).Update("version", gorm.Expr("version + 1")).Error; err != nil {
+			return err
+		}
+		if err := deleteExpiredWebDAVLocks(tx, now); err != nil {
+			return err
+		}
+		// an existing lock of the same name, or an infinite-depth lock of an ancestor
+		conflict := tx.Where("root = ?", l.Root).
+			Or(fmt.Sprintf("root IN ? AND %s = ?", columnName("zero_depth")), ancestors, false)
+		if !l.ZeroDepth {
+			// an infinite-depth lock conflicts with the locks of its descendants
+			if l.Root == "/" {
+				conflict = conflict.Or("root <> ?", "/")
+			} else {
+				conflict = conflict.Or("root LIKE ? ESCAPE ?", subPathPattern(l.Root), likeEscapeChar)
+			}
+		}
+		var count int64
+		if err := tx.Model(&model.WebDAVLock{}).Where(conflict).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Create(l).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, errors.WithStack(err)
+}
+
+func UpdateWebDAVLock(l *model.WebDAVLock) error {
+	return errors.WithStack(db.Save(l).Error)
+}
+
+func DeleteWebDAVLockByToken(token string) error {
+	return errors.WithStack(db.Where("token = ?", token).Delete(&model.WebDAVLock{}).Error)
+}
+
+func DeleteExpiredWebDAVLocks(now time.Time) error {
+	return errors.WithStack(deleteExpiredWebDAVLocks(db, now))
+}
+
+func deleteExpiredWebDAVLocks(tx *gorm.DB, now time.Time) error {
+	return tx.Where(fmt.Sprintf("%s >= 0 AND %s <= ?", columnName("duration"), columnName("expiry")), now).
+		Delete(&model.WebDAVLock{}).Error
+}
+
+func whereWebDAVPropIn(tx *gorm.DB, path string) *gorm.DB {
+	if path == "/" {
+		return tx.Where("1 = 1")
+	}
+	return tx.Where("path = ? OR path LIKE ? ESCAPE ?", path, subPathPattern(path), likeEscapeChar)
+}
+
+func GetWebDAVPropsByPath(path string) ([]model.WebDAVProp, error) {
+	var props []model.WebDAVProp
+	if err := db.Where("path = ?", path).Find(&props).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webdav props")
+	}
+	return props, nil
+}
+
+// GetWebDAVPropsByParent returns the props of the objects in the dir parent
+func GetWebDAVPropsByParent(parent string) ([]model.WebDAVProp, error) {
+	var props []model.WebDAVProp
+	if err := db.Where("parent = ?", parent).Find(&props).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webdav props")
+	}
+	return props, nil
+}
+
+// PatchWebDAVProps sets the props of path and removes the props named by remove at once
+func PatchWebDAVProps(path string, set []model.WebDAVProp, remove []model.WebDAVProp) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		for _, p := range append(set, remove...) {
+			err := tx.Where("path = ? AND space = ? AND local = ?", path, p.Space, p.Local).
+				Delete(&model.WebDAVProp{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		for i := range set {
+			set[i].ID = 0
+			set[i].Path = path
+			set[i].Parent = stdpath.Dir(path)
+		}
+		if len(set) == 0 {
+			return nil
+		}
+		return tx.Create(&set).Error
+	}))
+}
+
+// DeleteWebDAVProps deletes the props of path and all its descendants
+func DeleteWebDAVProps(path string) error {
+	return errors.WithStack(whereWebDAVPropIn(db, path).Delete(&model.WebDAVProp{}).Error)
+}
+
+// CopyWebDAVProps copies the props of srcPath and its descendants to dstPath,
+// the props that have been at dstPath are replaced. If move is true, the props
+// of srcPath are removed.
+func CopyWebDAVProps(srcPath, dstPath string, move bool) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		var props []model.WebDAVProp
+		if err := whereWebDAVPropIn(tx, srcPath).Find(&props).Error; err != nil {
+			return err
+		}
+		if len(props) == 0 {
+			return nil
+		}
+		if err := whereWebDAVPropIn(tx, dstPath).Delete(&model.WebDAVProp{}).Error; err != nil {
+			return err
+		}
+		if move {
+			if err := whereWebDAVPropIn(tx, srcPath).Delete(&model.WebDAVProp{}).Error; err != nil {
+				return err
+			}
+		}
+		for i := range props {
+			props[i].ID = 0
+			props[i].Path = stdpath.Join(dstPath, strings.TrimPrefix(props[i].Path, srcPath))
+			props[i].Parent = stdpath.Dir(props[i].Path)
+		}
+		return tx.CreateInBatches(&props, 1000).Error
+	}))
+}
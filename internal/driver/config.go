@@ -19,6 +19,9 @@ type Config struct {
 	ProxyRangeOption  bool `json:"-"`
 	// if the driver returns Link without URL, this should be set to true
 	NoLinkURL bool `json:"-"`
+	// whether Put accepts the streams of unknown size, whose size is negative,
+	// otherwise they are cached in temp files first
+	UnknownSizePut bool `json:"-"`
 }
 
 func (c Config) MustProxy() bool {
@@ -2,6 +2,7 @@ package driver
 
 import (
 	"context"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 )
@@ -110,6 +111,25 @@ type PutURL interface {
 	PutURL(ctx context.Context, dstDir model.Obj, name, url string) error
 }
 
+type SetModTime interface {
+	// SetModTime changes the modification time of the obj, used by the SetStat of SFTP and the MFMT of FTP
+	SetModTime(ctx context.Context, obj model.Obj, modTime time.Time) error
+}
+
+type Symlink interface {
+	// Symlink creates a symbolic link named linkName in parentDir, which points to target.
+	// target is an actual path in the storage, the same to the paths passed to the other methods
+	Symlink(ctx context.Context, parentDir model.Obj, linkName, target string) error
+	// ReadLink returns the actual path in the storage which the symbolic link obj points to
+	// return errs.NotSupport if it points to a path out of the storage
+	ReadLink(ctx context.Context, obj model.Obj) (string, error)
+}
+
+type WithDetails interface {
+	// GetDetails returns the space of the storage, like the statvfs of a file system
+	GetDetails(ctx context.Context) (*model.StorageDetails, error)
+}
+
 //type WriteResult interface {
 //	MkdirResult
 //	MoveResult
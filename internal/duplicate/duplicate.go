@@ -0,0 +1,326 @@
+package duplicate
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/search"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/OpenListTeam/tache"
+	log "github.com/sirupsen/logrus"
+)
+
+// Group is a group of files that have the same content
+type Group struct {
+	// hash:<hash type>:<hash> if the files are compared by hash, or
+	// name:<name> if some are only compared by size and name. The files
+	// are grouped pairwise, so they may not all have the hash of the key
+	Key   string   `json:"key"`
+	Size  int64    `json:"size"`
+	Paths []string `json:"paths"`
+}
+
+type FindArgs struct {
+	Parent  string `json:"parent"`
+	MinSize int64  `json:"min_size"`
+	// hash the files without a duplicate by a hash type the others have,
+	// instead of comparing them by name
+	HashOnDemand bool `json:"hash_on_demand"`
+}
+
+// FindTask finds the duplicate files under Parent in the search index,
+// the files of the same size are compared by any hash each pair has in common
+type FindTask struct {
+	task.TaskExtension
+	FindArgs
+	Groups []Group `json:"groups"`
+	status string
+}
+
+func (t *FindTask) GetName() string {
+	return fmt.Sprintf("find duplicate files in [%s]", t.Parent)
+}
+
+func (t *FindTask) GetStatus() string {
+	return t.status
+}
+
+func (t *FindTask) Run() error {
+	if err := t.ReinitCtx(); err != nil {
+		return err
+	}
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	t.Groups = nil
+	t.status = "getting indexed files"
+	bySize, err := t.listBySize()
+	if err != nil {
+		return err
+	}
+	sizes := make([]int64, 0, len(bySize))
+	for size, nodes := range bySize {
+		if len(nodes) > 1 {
+			sizes = append(sizes, size)
+		}
+	}
+	// the largest duplicates first, they are what's worth removing
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+	for i, size := range sizes {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		t.status = fmt.Sprintf("comparing files of %d bytes", size)
+		t.Groups = append(t.Groups, t.group(size, bySize[size])...)
+		t.SetProgress(float64(i+1) / float64(len(sizes)) * 100)
+	}
+	t.status = fmt.Sprintf("found %d groups", len(t.Groups))
+	return nil
+}
+
+func (t *FindTask) listBySize() (map[int64][]model.SearchNode, error) {
+	req := model.SearchReq{
+		Parent:  utils.FixAndCleanPath(t.Parent),
+		Scope:   2,
+		MinSize: max(t.MinSize, 1),
+		OrderBy: "size",
+		PageReq: model.PageReq{Page: 1, PerPage: 1000},
+	}
+	bySize := make(map[int64][]model.SearchNode)
+	var fetched int64
+	for {
+		nodes, total, err := search.Search(t.Ctx(), req)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			// only the db backends of search honour the parent
+			if !utils.IsSubPath(req.Parent, node.Parent) {
+				continue
+			}
+			bySize[node.Size] = append(bySize[node.Size], node)
+		}
+		fetched += int64(len(nodes))
+		if len(nodes) == 0 || fetched >= total {
+			return bySize, nil
+		}
+		req.Page++
+	}
+}
+
+func (t *FindTask) group(size int64, nodes []model.SearchNode) []Group {
+	hashes := make([]utils.HashInfo, len(nodes))
+	for i, node := range nodes {
+		if node.Hash == "" {
+			hashes[i] = utils.NewHashInfo(nil, "")
+		} else {
+			hashes[i] = utils.FromString(node.Hash)
+		}
+	}
+	s := newSets(len(nodes))
+	// the first file of each hash, the files of the same hash are in a set
+	byHash := make(map[string]int)
+	addHash := func(i int, ht *utils.HashType, hash string) {
+		k := "hash:" + ht.Name + ":" + strings.ToLower(hash)
+		if j, ok := byHash[k]; ok {
+			s.union(j, i, k)
+		} else {
+			byHash[k] = i
+		}
+	}
+	for i := range nodes {
+		for _, ht := range utils.Supported {
+			if v := hashes[i].GetHash(ht); v != "" {
+				addHash(i, ht, v)
+			}
+		}
+	}
+	if t.HashOnDemand {
+		for i, node := range nodes {
+			if s.matched(i) {
+				continue
+			}
+			ht := onDemandHashType(hashes, i)
+			if ht == nil {
+				continue
+			}
+			path := stdpath.Join(node.Parent, node.Name)
+			hash, err := hashFile(t.Ctx(), path, ht)
+			if err != nil {
+				log.Warnf("failed hash %s: %+v", path, err)
+				continue
+			}
+			hashes[i].Export()[ht] = hash
+			addHash(i, ht, hash)
+		}
+	} else {
+		// the files not matched by hash are compared by name, unless they have a different hash
+		byName := make(map[string][]int)
+		for i, node := range nodes {
+			if s.matched(i) {
+				continue
+			}
+			k := "name:" + node.Name
+			if slices.ContainsFunc(byName[k], func(j int) bool { return differ(hashes[i], hashes[j]) }) {
+				continue
+			}
+			if len(byName[k]) > 0 {
+				s.union(byName[k][0], i, k)
+			}
+			byName[k] = append(byName[k], i)
+		}
+	}
+	byRoot := make(map[int]*Group)
+	var roots []int
+	for i, node := range nodes {
+		if !s.matched(i) {
+			continue
+		}
+		r := s.find(i)
+		g, ok := byRoot[r]
+		if !ok {
+			g = &Group{Key: s.keys[r], Size: size}
+			byRoot[r] = g
+			roots = append(roots, r)
+		}
+		g.Paths = append(g.Paths, stdpath.Join(node.Parent, node.Name))
+	}
+	groups := make([]Group, 0, len(roots))
+	for _, r := range roots {
+		groups = append(groups, *byRoot[r])
+	}
+	return groups
+}
+
+// sets is a disjoint set of the files of the same content,
+// the key of a set is the hash or the name that first joined two of its files
+type sets struct {
+	parent []int
+	size   []int
+	keys   []string
+}
+
+func newSets(n int) *sets {
+	s := &sets{parent: make([]int, n), size: make([]int, n), keys: make([]string, n)}
+	for i := range s.parent {
+		s.parent[i], s.size[i] = i, 1
+	}
+	return s
+}
+
+func (s *sets) find(i int) int {
+	for s.parent[i] != i {
+		s.parent[i] = s.parent[s.parent[i]]
+		i = s.parent[i]
+	}
+	return i
+}
+
+func (s *sets) union(i, j int, key string) {
+	ri, rj := s.find(i), s.find(j)
+	if ri == rj {
+		return
+	}
+	if s.keys[ri] == "" {
+		s.keys[ri] = s.keys[rj]
+	}
+	if s.keys[ri] == "" {
+		s.keys[ri] = key
+	}
+	s.parent[rj] = ri
+	s.size[ri] += s.size[rj]
+}
+
+// matched reports whether the file i has a duplicate
+func (s *sets) matched(i int) bool {
+	return s.size[s.find(i)] > 1
+}
+
+// differ reports whether the hashes have a hash type in common of different values
+func differ(a, b utils.HashInfo) bool {
+	for ht, v := range a.All() {
+		if bv := b.GetHash(ht); v != "" && bv != "" && !strings.EqualFold(v, bv) {
+			return true
+		}
+	}
+	return false
+}
+
+// onDemandHashTypes are the hash types that can be computed without parameters
+var onDemandHashTypes = []*utils.HashType{utils.MD5, utils.SHA1, utils.SHA256}
+
+// onDemandHashType returns the hash type to compute for the file i, the one
+// the most of the other files have and i doesn't. It's MD5 if no other file
+// has one and i has none, nil if i can't be compared by computing one
+func onDemandHashType(hashes []utils.HashInfo, i int) *utils.HashType {
+	var (
+		best  *utils.HashType
+		count int
+	)
+	for _, ht := range onDemandHashTypes {
+		if hashes[i].GetHash(ht) != "" {
+			continue
+		}
+		n := 0
+		for j, h := range hashes {
+			if j != i && h.GetHash(ht) != "" {
+				n++
+			}
+		}
+		if n > count {
+			best, count = ht, n
+		}
+	}
+	if best != nil {
+		return best
+	}
+	for _, ht := range onDemandHashTypes {
+		if hashes[i].GetHash(ht) != "" {
+			return nil
+		}
+	}
+	return utils.MD5
+}
+
+func hashFile(ctx context.Context, path string, ht *utils.HashType) (string, error) {
+	link, obj, err := fs.Link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return "", err
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{Ctx: ctx, Obj: obj}, link)
+	if err != nil {
+		_ = link.Close()
+		return "", err
+	}
+	defer ss.Close()
+	return utils.HashReader(ht, ss)
+}
+
+var FindTaskManager *tache.Manager[*FindTask]
+
+func Find(ctx context.Context, args FindArgs) (task.TaskExtensionInfo, error) {
+	if args.Parent == "" {
+		args.Parent = "/"
+	}
+	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User)
+	t := &FindTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+			ApiUrl:  common.GetApiUrl(ctx),
+		},
+		FindArgs: args,
+	}
+	FindTaskManager.Add(t)
+	return t, nil
+}
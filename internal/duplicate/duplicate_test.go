@@ -0,0 +1,80 @@
+package duplicate
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+func node(name string, hashes map[*utils.HashType]string) model.SearchNode {
+	n := model.SearchNode{Parent: "/", Name: name, Size: 1}
+	if len(hashes) > 0 {
+		m := make(map[string]string, len(hashes))
+		for ht, v := range hashes {
+			m[ht.Name] = v
+		}
+		b, _ := json.Marshal(m)
+		n.Hash = string(b)
+	}
+	return n
+}
+
+func TestGroup(t *testing.T) {
+	nodes := []model.SearchNode{
+		// 115 and aliyun files with the same sha1, and a local file without a hash
+		node("a.mkv", map[*utils.HashType]string{utils.SHA1: "AAAA"}),
+		node("b.mkv", map[*utils.HashType]string{utils.SHA1: "aaaa", utils.MD5: "11"}),
+		node("a.mkv", nil),
+		// joined to b.mkv by md5 only
+		node("c.mkv", map[*utils.HashType]string{utils.MD5: "11"}),
+		// the same name as x.mkv but a different hash
+		node("x.mkv", map[*utils.HashType]string{utils.SHA1: "bbbb"}),
+		node("x.mkv", map[*utils.HashType]string{utils.SHA1: "cccc"}),
+		node("x.mkv", nil),
+	}
+	groups := (&FindTask{}).group(1, nodes)
+	want := []Group{
+		{Key: "hash:sha1:aaaa", Size: 1, Paths: []string{"/a.mkv", "/b.mkv", "/c.mkv"}},
+		{Key: "name:x.mkv", Size: 1, Paths: []string{"/x.mkv", "/x.mkv"}},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("groups = %+v, want %+v", groups, want)
+	}
+	for i := range want {
+		if groups[i].Key != want[i].Key || !slices.Equal(groups[i].Paths, want[i].Paths) {
+			t.Errorf("group %d = %+v, want %+v", i, groups[i], want[i])
+		}
+	}
+}
+
+func TestOnDemandHashType(t *testing.T) {
+	info := func(hashes map[*utils.HashType]string) utils.HashInfo {
+		return utils.NewHashInfoByMap(hashes)
+	}
+	tests := []struct {
+		name   string
+		hashes []utils.HashInfo
+		want   *utils.HashType
+	}{
+		{"the type of the others", []utils.HashInfo{
+			info(nil),
+			info(map[*utils.HashType]string{utils.SHA1: "a"}),
+			info(map[*utils.HashType]string{utils.SHA1: "a", utils.MD5: "b"}),
+		}, utils.SHA1},
+		{"no hashes", []utils.HashInfo{info(nil), info(nil)}, utils.MD5},
+		{"nothing to compare", []utils.HashInfo{
+			info(map[*utils.HashType]string{utils.SHA1: "a"}),
+			info(map[*utils.HashType]string{utils.SHA1: "b"}),
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := onDemandHashType(tt.hashes, 0); got != tt.want {
+				t.Errorf("onDemandHashType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
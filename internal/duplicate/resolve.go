@@ -0,0 +1,76 @@
+package duplicate
+
+import (
+	"context"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	ActionRemove = "remove"
+	ActionMove   = "move"
+)
+
+type ResolveGroup struct {
+	Keep   string   `json:"keep"`
+	Others []string `json:"others"`
+}
+
+type ResolveArgs struct {
+	Groups []ResolveGroup `json:"groups"`
+	// remove or move the other copies
+	Action string `json:"action"`
+	// the dir the other copies are moved to
+	DstDir string `json:"dst_dir"`
+}
+
+type ResolveFailure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Resolve keeps one copy of each group and removes the others or moves them
+// to DstDir, the others of a group are untouched if its kept copy is missing
+func Resolve(ctx context.Context, args ResolveArgs) ([]task.TaskExtensionInfo, []ResolveFailure, error) {
+	switch args.Action {
+	case ActionRemove:
+	case ActionMove:
+		if args.DstDir == "" {
+			return nil, nil, errors.New("dst_dir is required to move")
+		}
+	default:
+		return nil, nil, errors.Errorf("invalid action: %s", args.Action)
+	}
+	var tasks []task.TaskExtensionInfo
+	var failures []ResolveFailure
+	fail := func(path string, err error) {
+		failures = append(failures, ResolveFailure{Path: path, Error: err.Error()})
+	}
+	for _, g := range args.Groups {
+		if _, err := fs.Get(ctx, g.Keep, &fs.GetArgs{}); err != nil {
+			fail(g.Keep, errors.WithMessage(err, "failed get the kept copy"))
+			continue
+		}
+		for _, other := range g.Others {
+			if utils.PathEqual(other, g.Keep) {
+				continue
+			}
+			if args.Action == ActionRemove {
+				if err := fs.Remove(ctx, other); err != nil {
+					fail(other, err)
+				}
+				continue
+			}
+			t, err := fs.MoveWithTask(ctx, other, args.DstDir)
+			if err != nil {
+				fail(other, err)
+			} else if t != nil {
+				tasks = append(tasks, t)
+			}
+		}
+	}
+	return tasks, failures, nil
+}
@@ -11,6 +11,7 @@ var (
 	NotImplement = errors.New("not implement")
 	NotSupport   = errors.New("not support")
 	RelativePath = errors.New("access using relative path is not allowed")
+	VirtualRoot  = errors.New("the virtual root of the user can't be operated")
 
 	MoveBetweenTwoStorages = errors.New("can't move files between two storages, try to copy")
 	UploadNotSupported     = errors.New("upload not supported")
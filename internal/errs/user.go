@@ -7,4 +7,6 @@ var (
 	EmptyPassword      = errors.New("password is empty")
 	WrongPassword      = errors.New("password is incorrect")
 	DeleteAdminOrGuest = errors.New("cannot delete admin or guest")
+	QuotaExceeded      = errors.New("the quota of the user is exceeded")
+	TrafficExceeded    = errors.New("the monthly traffic of the user is exceeded")
 )
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/archive/tool"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -21,6 +22,9 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/OpenListTeam/tache"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -47,6 +51,14 @@ func (t *ArchiveDownloadTask) GetStatus() string {
 	return t.status
 }
 
+func (t *ArchiveDownloadTask) OnSucceeded() {
+	webhook.EmitTask(t, true)
+}
+
+func (t *ArchiveDownloadTask) OnFailed() {
+	webhook.EmitTask(t, false)
+}
+
 func (t *ArchiveDownloadTask) Run() error {
 	if err := t.ReinitCtx(); err != nil {
 		return err
@@ -150,6 +162,14 @@ func (t *ArchiveContentUploadTask) GetStatus() string {
 	return t.status
 }
 
+func (t *ArchiveContentUploadTask) OnSucceeded() {
+	webhook.EmitTask(t, true)
+}
+
+func (t *ArchiveContentUploadTask) OnFailed() {
+	webhook.EmitTask(t, false)
+}
+
 func (t *ArchiveContentUploadTask) Run() error {
 	if err := t.ReinitCtx(); err != nil {
 		return err
@@ -399,3 +419,188 @@ func archiveInternalExtract(ctx context.Context, path string, args model.Archive
 	}
 	return op.InternalExtract(ctx, storage, actualPath, args)
 }
+
+type ArchiveCompressTask struct {
+	task.TaskExtension
+	model.ArchiveCompressArgs
+	status       string
+	SrcPaths     []string
+	DstDirPath   string
+	ArchiveName  string
+	dstStorage   driver.Driver
+	DstStorageMp string
+}
+
+func (t *ArchiveCompressTask) GetName() string {
+	return fmt.Sprintf("compress %v to [%s](%s) as %s", t.SrcPaths, t.DstStorageMp, t.DstDirPath, t.ArchiveName)
+}
+
+func (t *ArchiveCompressTask) GetStatus() string {
+	return t.status
+}
+
+func (t *ArchiveCompressTask) OnSucceeded() {
+	webhook.EmitTask(t, true)
+}
+
+func (t *ArchiveCompressTask) OnFailed() {
+	webhook.EmitTask(t, false)
+}
+
+func (t *ArchiveCompressTask) Run() error {
+	if err := t.ReinitCtx(); err != nil {
+		return err
+	}
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	return t.run()
+}
+
+// run compresses the src objects and uploads the archive at the same time,
+// the archive is piped into the upload stream without a temp file
+func (t *ArchiveCompressTask) run() error {
+	var err error
+	if t.dstStorage == nil {
+		t.dstStorage, err = op.GetStorageByMountPath(t.DstStorageMp)
+		if err != nil {
+			return errors.WithMessage(err, "failed get dst storage")
+		}
+	}
+	compressTool, err := tool.GetCompressTool(t.Format)
+	if err != nil {
+		return err
+	}
+	t.status = "walking src objects"
+	var files []tool.CompressFile
+	var total int64
+	for _, srcPath := range t.SrcPaths {
+		srcObj, err := Get(t.Ctx(), srcPath, &GetArgs{})
+		if err != nil {
+			return errors.WithMessagef(err, "failed get src [%s]", srcPath)
+		}
+		baseName := srcObj.GetName()
+		err = WalkFS(t.Ctx(), -1, srcPath, srcObj, func(reqPath string, info model.Obj) error {
+			if utils.IsCanceled(t.Ctx()) {
+				return t.Ctx().Err()
+			}
+			if !t.canRead(reqPath) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			file := tool.CompressFile{
+				Obj:           info,
+				NameInArchive: stdpath.Join(baseName, strings.TrimPrefix(reqPath, srcPath)),
+			}
+			if !info.IsDir() {
+				total += info.GetSize()
+				file.Open = func() (io.ReadCloser, error) {
+					return t.openSrc(reqPath)
+				}
+			}
+			files = append(files, file)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	t.SetTotalBytes(total)
+	t.status = "compressing"
+	pr, pw := io.Pipe()
+	go func() {
+		// the upload fails with the error of compressing, and closing pr by
+		// the upload stops compressing
+		_ = pw.CloseWithError(compressTool.Compress(files, pw, t.ArchiveCompressArgs, t.SetProgress))
+	}()
+	s := &stream.FileStream{
+		Ctx: t.Ctx(),
+		Obj: &model.Object{
+			Name: t.ArchiveName,
+			// the size of the archive is unknown until it's compressed
+			Size:     -1,
+			Modified: time.Now(),
+		},
+		Mimetype:     mime.TypeByExtension(filepath.Ext(t.ArchiveName)),
+		WebPutAsTask: true,
+		Reader:       pr,
+	}
+	s.Closers.Add(pr)
+	quotaDone, err := op.QuotaPut(t.Ctx(), t.Creator, t.dstStorage, t.DstDirPath, s)
+	if err != nil {
+		_ = s.Close()
+		return err
+	}
+	err = op.Put(t.Ctx(), t.dstStorage, t.DstDirPath, s, nil, true)
+	quotaDone(err)
+	return err
+}
+
+// canRead checks each object walked under the srcs like the handler checks the
+// srcs, since the metas and acl rules under them may differ
+func (t *ArchiveCompressTask) canRead(reqPath string) bool {
+	if t.Creator == nil {
+		return true
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return false
+	}
+	return common.CanAccess(t.Creator, meta, reqPath, "")
+}
+
+func (t *ArchiveCompressTask) openSrc(path string) (io.ReadCloser, error) {
+	link, obj, err := Link(t.Ctx(), path, model.LinkArgs{})
+	if err != nil {
+		return nil, err
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{Ctx: t.Ctx(), Obj: obj}, link)
+	if err != nil {
+		_ = link.Close()
+		return nil, err
+	}
+	return ss, nil
+}
+
+var ArchiveCompressTaskManager *tache.Manager[*ArchiveCompressTask]
+
+func archiveCompress(ctx context.Context, srcPaths []string, dstDirPath, archiveName string, args model.ArchiveCompressArgs) (task.TaskExtensionInfo, error) {
+	if _, err := tool.GetCompressTool(args.Format); err != nil {
+		return nil, err
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	if dstStorage.Config().NoUpload {
+		return nil, errors.WithStack(errs.UploadNotSupported)
+	}
+	if !strings.HasSuffix(archiveName, args.Format) {
+		archiveName += args.Format
+	}
+	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User)
+	tsk := &ArchiveCompressTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+			ApiUrl:  common.GetApiUrl(ctx),
+		},
+		ArchiveCompressArgs: args,
+		SrcPaths:            srcPaths,
+		DstDirPath:          dstDirActualPath,
+		ArchiveName:         archiveName,
+		dstStorage:          dstStorage,
+		DstStorageMp:        dstStorage.GetStorage().MountPath,
+	}
+	if ctx.Value(conf.NoTaskKey) != nil {
+		tsk.SetCtx(ctx)
+		if err = tsk.run(); err != nil {
+			return nil, errors.WithMessagef(err, "failed compress %v", srcPaths)
+		}
+		return nil, nil
+	} else {
+		ArchiveCompressTaskManager.Add(tsk)
+		return tsk, nil
+	}
+}
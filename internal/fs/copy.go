@@ -13,6 +13,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/OpenListTeam/tache"
@@ -38,6 +39,14 @@ func (t *CopyTask) GetStatus() string {
 	return t.Status
 }
 
+func (t *CopyTask) OnSucceeded() {
+	webhook.EmitTask(t, true)
+}
+
+func (t *CopyTask) OnFailed() {
+	webhook.EmitTask(t, false)
+}
+
 func (t *CopyTask) Run() error {
 	if err := t.ReinitCtx(); err != nil {
 		return err
@@ -55,7 +64,11 @@ func (t *CopyTask) Run() error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return copyBetween2Storages(t, t.srcStorage, t.dstStorage, t.SrcObjPath, t.DstDirPath)
+	err = copyBetween2Storages(t, t.srcStorage, t.dstStorage, t.SrcObjPath, t.DstDirPath)
+	if err == nil {
+		copyProps(utils.GetFullPath(t.SrcStorageMp, t.SrcObjPath), utils.GetFullPath(t.DstStorageMp, t.DstDirPath))
+	}
+	return err
 }
 
 var CopyTaskManager *tache.Manager[*CopyTask]
@@ -74,6 +87,9 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 	// copy if in the same storage, just call driver.Copy
 	if srcStorage.GetStorage() == dstStorage.GetStorage() {
 		err = op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...)
+		if err == nil {
+			copyProps(srcObjPath, dstDirPath)
+		}
 		if !errors.Is(err, errs.NotImplement) && !errors.Is(err, errs.NotSupport) {
 			return nil, err
 		}
@@ -98,7 +114,11 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 				_ = link.Close()
 				return nil, errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
 			}
-			return nil, op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			err = op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			if err == nil {
+				copyProps(srcObjPath, dstDirPath)
+			}
+			return nil, err
 		}
 	}
 	// not in the same storage
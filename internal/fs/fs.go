@@ -3,6 +3,8 @@ package fs
 import (
 	"context"
 	"io"
+	stdpath "path"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/pkg/errors"
 )
 
@@ -60,6 +63,7 @@ func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 
 func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	err := makeDir(ctx, path, lazyCache...)
+	op.Audit(ctx, model.AuditMkdir, err, path)
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
 	}
@@ -68,30 +72,42 @@ func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 
 func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
 	err := move(ctx, srcPath, dstDirPath, lazyCache...)
+	op.Audit(ctx, model.AuditMove, err, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
+	} else {
+		webhook.EmitFile(ctx, model.EventFileMove, srcPath, dstDirPath)
 	}
 	return err
 }
 
 func MoveWithTask(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) (task.TaskExtensionInfo, error) {
 	res, err := _move(ctx, srcPath, dstDirPath, lazyCache...)
+	op.Audit(ctx, model.AuditMove, err, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
+	} else if res == nil {
+		// moved in the same storage, or the MoveTask emits it when succeeded
+		webhook.EmitFile(ctx, model.EventFileMove, srcPath, dstDirPath)
 	}
 	return res, err
 }
 
 func MoveWithTaskAndValidation(ctx context.Context, srcPath, dstDirPath string, validateExistence bool, lazyCache ...bool) (task.TaskExtensionInfo, error) {
 	res, err := _moveWithValidation(ctx, srcPath, dstDirPath, validateExistence, lazyCache...)
+	op.Audit(ctx, model.AuditMove, err, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
+	} else if res == nil {
+		// moved in the same storage, or the MoveTask emits it when succeeded
+		webhook.EmitFile(ctx, model.EventFileMove, srcPath, dstDirPath)
 	}
 	return res, err
 }
 
 func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool) (task.TaskExtensionInfo, error) {
 	res, err := _copy(ctx, srcObjPath, dstDirPath, lazyCache...)
+	op.Audit(ctx, model.AuditCopy, err, srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)))
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
@@ -100,30 +116,78 @@ func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool)
 
 func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
 	err := rename(ctx, srcPath, dstName, lazyCache...)
+	op.Audit(ctx, model.AuditRename, err, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
+	} else {
+		webhook.EmitFile(ctx, model.EventFileRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
 	}
 	return err
 }
 
 func Remove(ctx context.Context, path string) error {
-	err := remove(ctx, path)
+	err := remove(ctx, path, false)
+	op.Audit(ctx, model.AuditRemove, err, path)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
+	} else {
+		webhook.EmitFile(ctx, model.EventFileRemove, path)
 	}
 	return err
 }
 
+// RemovePermanently removes the object without the recycle bin, it's used for
+// the objects being overwritten
+func RemovePermanently(ctx context.Context, path string) error {
+	err := remove(ctx, path, true)
+	op.Audit(ctx, model.AuditRemove, err, path)
+	if err != nil {
+		log.Errorf("failed remove %s: %+v", path, err)
+	} else {
+		webhook.EmitFile(ctx, model.EventFileRemove, path)
+	}
+	return err
+}
+
+func SetModTime(ctx context.Context, path string, modTime time.Time) error {
+	err := setModTime(ctx, path, modTime)
+	if err != nil {
+		log.Errorf("failed set mod time of %s: %+v", path, err)
+	}
+	return err
+}
+
+func Symlink(ctx context.Context, path, target string) error {
+	err := symlink(ctx, path, target)
+	op.Audit(ctx, model.AuditSymlink, err, path, target)
+	if err != nil {
+		log.Errorf("failed create symlink %s to %s: %+v", path, target, err)
+	}
+	return err
+}
+
+func ReadLink(ctx context.Context, path string) (string, error) {
+	res, err := readLink(ctx, path)
+	if err != nil {
+		log.Errorf("failed read link %s: %+v", path, err)
+	}
+	return res, err
+}
+
 func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
 	err := putDirectly(ctx, dstDirPath, file, lazyCache...)
+	op.Audit(ctx, model.AuditUpload, err, stdpath.Join(dstDirPath, file.GetName()))
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
+	} else {
+		webhook.EmitFile(ctx, model.EventFileUpload, stdpath.Join(dstDirPath, file.GetName()))
 	}
 	return err
 }
 
 func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskExtensionInfo, error) {
 	t, err := putAsTask(ctx, dstDirPath, file)
+	op.Audit(ctx, model.AuditUpload, err, stdpath.Join(dstDirPath, file.GetName()))
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
@@ -148,12 +212,24 @@ func ArchiveList(ctx context.Context, path string, args model.ArchiveListArgs) (
 
 func ArchiveDecompress(ctx context.Context, srcObjPath, dstDirPath string, args model.ArchiveDecompressArgs, lazyCache ...bool) (task.TaskExtensionInfo, error) {
 	t, err := archiveDecompress(ctx, srcObjPath, dstDirPath, args, lazyCache...)
+	op.Audit(ctx, model.AuditDecompress, err, srcObjPath, dstDirPath)
 	if err != nil {
 		log.Errorf("failed decompress [%s]%s: %+v", srcObjPath, args.InnerPath, err)
 	}
 	return t, err
 }
 
+func ArchiveCompress(ctx context.Context, srcPaths []string, dstDirPath, archiveName string, args model.ArchiveCompressArgs) (task.TaskExtensionInfo, error) {
+	t, err := archiveCompress(ctx, srcPaths, dstDirPath, archiveName, args)
+	for _, srcPath := range srcPaths {
+		op.Audit(ctx, model.AuditCompress, err, srcPath, stdpath.Join(dstDirPath, archiveName))
+	}
+	if err != nil {
+		log.Errorf("failed compress %v to [%s]%s: %+v", srcPaths, dstDirPath, archiveName, err)
+	}
+	return t, err
+}
+
 func ArchiveDriverExtract(ctx context.Context, path string, args model.ArchiveInnerArgs) (*model.Link, model.Obj, error) {
 	l, obj, err := archiveDriverExtract(ctx, path, args)
 	if err != nil {
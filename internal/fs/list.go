@@ -35,6 +35,7 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 				return nil, errors.WithMessage(err, "failed get objs")
 			}
 		}
+		_objs = op.HideRecycled(storage, actualPath, _objs)
 	}
 
 	om := model.NewObjMerge()
@@ -14,6 +14,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/OpenListTeam/tache"
@@ -60,6 +61,18 @@ func (t *MoveTask) GetStatus() string {
 	return t.Status
 }
 
+func (t *MoveTask) OnSucceeded() {
+	if t.IsRootTask {
+		webhook.EmitFile(t.Ctx(), model.EventFileMove,
+			stdpath.Join(t.SrcStorageMp, t.SrcObjPath), stdpath.Join(t.DstStorageMp, t.DstDirPath))
+	}
+	webhook.EmitTask(t, true)
+}
+
+func (t *MoveTask) OnFailed() {
+	webhook.EmitTask(t, false)
+}
+
 func (t *MoveTask) GetProgress() float64 {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -156,11 +169,16 @@ func (t *MoveTask) Run() error {
 		t.IsRootTask = true
 		t.RootTaskID = t.GetID()
 		t.mu.Unlock()
-		return t.runRootMoveTask()
+		err = t.runRootMoveTask()
+	} else {
+		// Use safe move logic for files
+		err = t.safeMoveOperation(srcObj)
 	}
-
-	// Use safe move logic for files
-	return t.safeMoveOperation(srcObj)
+	if err == nil {
+		moveProps(utils.GetFullPath(t.SrcStorageMp, t.SrcObjPath),
+			utils.GetFullPath(t.DstStorageMp, stdpath.Join(t.DstDirPath, srcObj.GetName())))
+	}
+	return err
 }
 
 func (t *MoveTask) runRootMoveTask() error {
@@ -419,7 +437,7 @@ func (t *MoveTask) deleteSourceRecursively(srcStorage driver.Driver, srcPath str
 
 	if !srcObj.IsDir() {
 		// Delete single file
-		err := op.Remove(t.Ctx(), srcStorage, srcPath)
+		err := op.RemovePermanently(t.Ctx(), srcStorage, srcPath)
 		if err != nil {
 			return errors.WithMessagef(err, "failed to delete src [%s] file", srcPath)
 		}
@@ -449,7 +467,7 @@ func (t *MoveTask) deleteSourceRecursively(srcStorage driver.Driver, srcPath str
 	}
 
 	// Delete the directory itself
-	err = op.Remove(t.Ctx(), srcStorage, srcPath)
+	err = op.RemovePermanently(t.Ctx(), srcStorage, srcPath)
 	if err != nil {
 		return errors.WithMessagef(err, "failed to delete src [%s] directory", srcPath)
 	}
@@ -502,7 +520,7 @@ func moveBetween2Storages(t *MoveTask, srcStorage, dstStorage driver.Driver, src
 		}
 
 		t.Status = "cleaning up source directory"
-		err = op.Remove(t.Ctx(), srcStorage, srcObjPath)
+		err = op.RemovePermanently(t.Ctx(), srcStorage, srcObjPath)
 		if err != nil {
 			t.Status = "completed (source directory cleanup pending)"
 		} else {
@@ -544,7 +562,7 @@ func moveFileBetween2Storages(tsk *MoveTask, srcStorage, dstStorage driver.Drive
 	tsk.SetProgress(50)
 
 	tsk.Status = "deleting source file"
-	err = op.Remove(tsk.Ctx(), srcStorage, srcFilePath)
+	err = op.RemovePermanently(tsk.Ctx(), srcStorage, srcFilePath)
 	if err != nil {
 		return errors.WithMessagef(err, "failed to delete src [%s] file from storage [%s] after successful copy", srcFilePath, srcStorage.GetStorage().MountPath)
 	}
@@ -582,6 +600,9 @@ func _moveWithValidation(ctx context.Context, srcObjPath, dstDirPath string, val
 	// Try native move first if in the same storage
 	if srcStorage.GetStorage() == dstStorage.GetStorage() {
 		err = op.Move(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...)
+		if err == nil {
+			moveProps(srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)))
+		}
 		if !errors.Is(err, errs.NotImplement) && !errors.Is(err, errs.NotSupport) {
 			return nil, err
 		}
@@ -2,6 +2,8 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
@@ -17,6 +19,41 @@ func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	return op.MakeDir(ctx, storage, actualPath, lazyCache...)
 }
 
+func setModTime(ctx context.Context, path string, modTime time.Time) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	return op.SetModTime(ctx, storage, actualPath, modTime)
+}
+
+func symlink(ctx context.Context, path, target string) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	targetStorage, targetActualPath, err := op.GetStorageAndActualPath(target)
+	if err != nil {
+		return errors.WithMessage(err, "failed get target storage")
+	}
+	if storage.GetStorage() != targetStorage.GetStorage() {
+		return errors.WithMessage(errs.NotSupport, "the target is in another storage")
+	}
+	return op.Symlink(ctx, storage, actualPath, targetActualPath)
+}
+
+func readLink(ctx context.Context, path string) (string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed get storage")
+	}
+	target, err := op.ReadLink(ctx, storage, actualPath)
+	if err != nil {
+		return "", err
+	}
+	return stdpath.Join(storage.GetStorage().MountPath, target), nil
+}
+
 func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
 	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
 	if err != nil {
@@ -29,7 +66,11 @@ func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 	if srcStorage.GetStorage() != dstStorage.GetStorage() {
 		return errors.WithStack(errs.MoveBetweenTwoStorages)
 	}
-	return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...)
+	err = op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...)
+	if err == nil {
+		moveProps(srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	}
+	return err
 }
 
 func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
@@ -37,15 +78,27 @@ func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
+	err = op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
+	if err == nil {
+		moveProps(srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	}
+	return err
 }
 
-func remove(ctx context.Context, path string) error {
+func remove(ctx context.Context, path string, permanently bool) error {
 	storage, actualPath, err := op.GetStorageAndActualPath(path)
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.Remove(ctx, storage, actualPath)
+	if permanently {
+		err = op.RemovePermanently(ctx, storage, actualPath)
+	} else {
+		err = op.Remove(ctx, storage, actualPath)
+	}
+	if err == nil {
+		removeProps(path)
+	}
+	return err
 }
 
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
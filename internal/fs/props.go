@@ -0,0 +1,30 @@
+package fs
+
+import (
+	stdpath "path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	log "github.com/sirupsen/logrus"
+)
+
+// the WebDAV dead props follow the objects they belong to,
+// failing to update them doesn't fail the operation on the objects
+
+func copyProps(srcPath, dstDirPath string) {
+	dstPath := stdpath.Join(dstDirPath, stdpath.Base(srcPath))
+	if err := op.CopyWebDAVProps(srcPath, dstPath); err != nil {
+		log.Warnf("failed copy webdav props of %s to %s: %+v", srcPath, dstPath, err)
+	}
+}
+
+func moveProps(srcPath, dstPath string) {
+	if err := op.MoveWebDAVProps(srcPath, dstPath); err != nil {
+		log.Warnf("failed move webdav props of %s to %s: %+v", srcPath, dstPath, err)
+	}
+}
+
+func removeProps(path string) {
+	if err := op.DeleteWebDAVProps(path); err != nil {
+		log.Warnf("failed remove webdav props of %s: %+v", path, err)
+	}
+}
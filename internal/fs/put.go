@@ -3,6 +3,8 @@ package fs
 import (
 	"context"
 	"fmt"
+	stdpath "path"
+	"sync"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
@@ -11,6 +13,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/tache"
 	"github.com/pkg/errors"
 )
@@ -20,6 +23,10 @@ type UploadTask struct {
 	storage          driver.Driver
 	dstDirActualPath string
 	file             model.FileStreamer
+	// quotaDone settles the quota reserved for the file, it's nil once settled
+	quotaDone func(error)
+	quotaMu   sync.Mutex
+	running   bool
 }
 
 func (t *UploadTask) GetName() string {
@@ -30,13 +37,71 @@ func (t *UploadTask) GetStatus() string {
 	return "uploading"
 }
 
+func (t *UploadTask) settleQuota(err error) {
+	t.quotaMu.Lock()
+	defer t.quotaMu.Unlock()
+	if t.quotaDone != nil {
+		t.quotaDone(err)
+		t.quotaDone = nil
+	}
+}
+
+func (t *UploadTask) OnSucceeded() {
+	t.settleQuota(nil)
+	webhook.EmitFile(t.Ctx(), model.EventFileUpload,
+		stdpath.Join(t.storage.GetStorage().MountPath, t.dstDirActualPath, t.file.GetName()))
+	webhook.EmitTask(t, true)
+}
+
+func (t *UploadTask) OnFailed() {
+	t.settleQuota(t.GetErr())
+	webhook.EmitTask(t, false)
+}
+
 func (t *UploadTask) Run() error {
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
+	if err := t.start(); err != nil {
+		return err
+	}
+	defer func() {
+		t.quotaMu.Lock()
+		t.running = false
+		t.quotaMu.Unlock()
+	}()
+	// the quota is settled by OnSucceeded or OnFailed, not by each retry
 	return op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
 }
 
+// start marks the task running, the quota is reserved again if it has been
+// settled by a failure or a cancel before, e.g. the task is retried by the user
+func (t *UploadTask) start() error {
+	t.quotaMu.Lock()
+	defer t.quotaMu.Unlock()
+	if t.quotaDone == nil {
+		quotaDone, err := op.QuotaPut(t.Ctx(), t.Creator, t.storage, t.dstDirActualPath, t.file)
+		if err != nil {
+			return err
+		}
+		t.quotaDone = quotaDone
+	}
+	t.running = true
+	return nil
+}
+
+// Cancel releases the quota of the task which is not running, since the
+// hooks of a task canceled in the queue are never called
+func (t *UploadTask) Cancel() {
+	t.TaskExtension.Cancel()
+	t.quotaMu.Lock()
+	defer t.quotaMu.Unlock()
+	if !t.running && t.quotaDone != nil {
+		t.quotaDone(context.Canceled)
+		t.quotaDone = nil
+	}
+}
+
 var UploadTaskManager *tache.Manager[*UploadTask]
 
 // putAsTask add as a put task and return immediately
@@ -48,15 +113,20 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 	if storage.Config().NoUpload {
 		return nil, errors.WithStack(errs.UploadNotSupported)
 	}
+	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User) // taskCreator is nil when convert failed
+	quotaDone, err := op.QuotaPut(ctx, taskCreator, storage, dstDirActualPath, file)
+	if err != nil {
+		return nil, err
+	}
 	if file.NeedStore() {
 		_, err := file.CacheFullInTempFile()
 		if err != nil {
+			quotaDone(err)
 			return nil, errors.Wrapf(err, "failed to create temp file")
 		}
 		//file.SetReader(tempFile)
 		//file.SetTmpFile(tempFile)
 	}
-	taskCreator, _ := ctx.Value(conf.UserKey).(*model.User) // taskCreator is nil when convert failed
 	t := &UploadTask{
 		TaskExtension: task.TaskExtension{
 			Creator: taskCreator,
@@ -64,6 +134,7 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 		storage:          storage,
 		dstDirActualPath: dstDirActualPath,
 		file:             file,
+		quotaDone:        quotaDone,
 	}
 	t.SetTotalBytes(file.GetSize())
 	UploadTaskManager.Add(t)
@@ -81,5 +152,13 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 		_ = file.Close()
 		return errors.WithStack(errs.UploadNotSupported)
 	}
-	return op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	user, _ := ctx.Value(conf.UserKey).(*model.User)
+	quotaDone, err := op.QuotaPut(ctx, user, storage, dstDirActualPath, file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	err = op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	quotaDone(err)
+	return err
 }
@@ -1,170 +1,420 @@
 package fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/winfsp/cgofuse/fuse"
+)
 
 type Fs struct {
 	RootFolder string
 	fuse.FileSystemBase
-}
-
-func (fs *Fs) Init() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Destroy() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mknod(path string, mode uint32, dev uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mkdir(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Unlink(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rmdir(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Link(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Symlink(target string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readlink(path string) (int, string) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rename(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chmod(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chown(path string, uid uint32, gid uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Access(path string, mask uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Open(path string, flags int) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Truncate(path string, size int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Flush(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Release(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsync(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Opendir(path string) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Releasedir(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Setxattr(path string, name string, value []byte, flags int) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getxattr(path string, name string) (int, []byte) {
-	//TODO implement me
-	panic("implement me")
-}
 
-func (fs *Fs) Removexattr(path string, name string) int {
-	//TODO implement me
-	panic("implement me")
+	ctx     context.Context
+	mu      sync.Mutex
+	nextFh  uint64
+	handles map[uint64]handle
 }
 
-func (fs *Fs) Listxattr(path string, fill func(name string) bool) int {
-	//TODO implement me
-	panic("implement me")
+func NewFs(ctx context.Context, rootFolder string) *Fs {
+	return &Fs{
+		RootFolder: utils.FixAndCleanPath(rootFolder),
+		ctx:        ctx,
+		handles:    make(map[uint64]handle),
+	}
+}
+
+// reqPath converts the path received from FUSE to an OpenList path
+func (f *Fs) reqPath(path string) string {
+	return stdpath.Join(f.RootFolder, utils.FixAndCleanPath(path))
+}
+
+func (f *Fs) addHandle(h handle) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextFh++
+	f.handles[f.nextFh] = h
+	return f.nextFh
+}
+
+func (f *Fs) getHandle(fh uint64) handle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.handles[fh]
+}
+
+func (f *Fs) removeHandle(fh uint64) handle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h := f.handles[fh]
+	delete(f.handles, fh)
+	return h
+}
+
+// getWriter returns an open write handle of path, files that have been created
+// but not uploaded yet are only visible through it
+func (f *Fs) getWriter(path string) *writeHandle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, h := range f.handles {
+		if w, ok := h.(*writeHandle); ok && w.path == path {
+			return w
+		}
+	}
+	return nil
+}
+
+func (f *Fs) Init() {
+	log.Infof("fuse: mounted %s", f.RootFolder)
+}
+
+func (f *Fs) Destroy() {
+	f.mu.Lock()
+	handles := f.handles
+	f.handles = make(map[uint64]handle)
+	f.mu.Unlock()
+	for _, h := range handles {
+		if err := h.Release(); err != nil {
+			log.Errorf("fuse: failed release %s: %+v", h.Path(), err)
+		}
+	}
+	log.Infof("fuse: unmounted %s", f.RootFolder)
+}
+
+func (f *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
+	*stat = fuse.Statfs_t{
+		Bsize:   blockSize,
+		Frsize:  blockSize,
+		Blocks:  1 << 40 / blockSize,
+		Bfree:   1 << 40 / blockSize,
+		Bavail:  1 << 40 / blockSize,
+		Namemax: 255,
+	}
+	return 0
+}
+
+func (f *Fs) Mkdir(path string, mode uint32) int {
+	return errno(fs.MakeDir(f.ctx, f.reqPath(path)))
+}
+
+func (f *Fs) Unlink(path string) int {
+	return errno(fs.Remove(f.ctx, f.reqPath(path)))
+}
+
+func (f *Fs) Rmdir(path string) int {
+	reqPath := f.reqPath(path)
+	objs, err := fs.List(f.ctx, reqPath, &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	if len(objs) > 0 {
+		return -fuse.ENOTEMPTY
+	}
+	return errno(fs.Remove(f.ctx, reqPath))
+}
+
+func (f *Fs) Rename(oldpath string, newpath string) int {
+	srcPath, dstPath := f.reqPath(oldpath), f.reqPath(newpath)
+	if utils.PathEqual(srcPath, dstPath) {
+		return 0
+	}
+	dstDir, dstName := stdpath.Split(dstPath)
+	dstObj, err := fs.Get(f.ctx, dstPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(f.move(srcPath, dstPath))
+	}
+	// an existing destination can only be replaced by an object of the same
+	// type, and a folder only if it's empty
+	srcObj, err := f.get(srcPath)
+	if err != nil {
+		return errno(err)
+	}
+	if dstObj.IsDir() {
+		if !srcObj.IsDir() {
+			return -fuse.EISDIR
+		}
+		objs, err := fs.List(f.ctx, dstPath, &fs.ListArgs{NoLog: true})
+		if err != nil {
+			return errno(err)
+		}
+		if len(objs) > 0 {
+			return -fuse.ENOTEMPTY
+		}
+	} else if srcObj.IsDir() {
+		return -fuse.ENOTDIR
+	}
+	// the destination is replaced, it's kept under a temp name until the move
+	// succeeds, so that it can be put back if the move fails
+	tmpName := fmt.Sprintf("%s%d_%s", renameTmpPrefix, time.Now().UnixNano(), dstName)
+	if err := fs.Rename(f.ctx, dstPath, tmpName); err != nil {
+		return errno(err)
+	}
+	tmpPath := stdpath.Join(dstDir, tmpName)
+	if err := f.move(srcPath, dstPath); err != nil {
+		if err := fs.Rename(f.ctx, tmpPath, dstName); err != nil {
+			log.Errorf("failed put back [%s] from [%s]: %+v", dstPath, tmpPath, err)
+		}
+		return errno(err)
+	}
+	if err := fs.RemovePermanently(f.ctx, tmpPath); err != nil {
+		log.Errorf("failed remove the replaced [%s]: %+v", tmpPath, err)
+	}
+	return 0
+}
+
+// move moves srcPath to dstPath, which doesn't exist
+func (f *Fs) move(srcPath, dstPath string) error {
+	srcDir, srcName := stdpath.Split(srcPath)
+	dstDir, dstName := stdpath.Split(dstPath)
+	if !utils.PathEqual(srcDir, dstDir) {
+		if err := fs.Move(f.ctx, srcPath, dstDir); err != nil {
+			return err
+		}
+		srcPath = stdpath.Join(dstDir, srcName)
+	}
+	if srcName != dstName {
+		return fs.Rename(f.ctx, srcPath, dstName)
+	}
+	return nil
+}
+
+func (f *Fs) Chmod(path string, mode uint32) int {
+	return 0
+}
+
+func (f *Fs) Chown(path string, uid uint32, gid uint32) int {
+	return 0
+}
+
+func (f *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
+	return 0
+}
+
+func (f *Fs) Access(path string, mask uint32) int {
+	return 0
+}
+
+func (f *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
+	reqPath := f.reqPath(path)
+	w, err := newWriteHandle(f.ctx, reqPath, nil)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	return 0, f.addHandle(w)
+}
+
+func (f *Fs) Open(path string, flags int) (int, uint64) {
+	reqPath := f.reqPath(path)
+	obj, err := f.get(reqPath)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR, ^uint64(0)
+	}
+	if flags&fuse.O_ACCMODE == fuse.O_RDONLY {
+		r, err := newReadHandle(f.ctx, reqPath)
+		if err != nil {
+			return errno(err), ^uint64(0)
+		}
+		return 0, f.addHandle(r)
+	}
+	var existing model.Obj
+	if flags&fuse.O_TRUNC == 0 {
+		existing = obj
+	}
+	w, err := newWriteHandle(f.ctx, reqPath, existing)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	return 0, f.addHandle(w)
+}
+
+func (f *Fs) get(reqPath string) (model.Obj, error) {
+	if w := f.getWriter(reqPath); w != nil {
+		return w.Obj()
+	}
+	return fs.Get(f.ctx, reqPath, &fs.GetArgs{NoLog: true})
+}
+
+func (f *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
+	obj, err := f.get(f.reqPath(path))
+	if err != nil {
+		return errno(err)
+	}
+	fillStat(stat, obj)
+	return 0
+}
+
+func (f *Fs) Truncate(path string, size int64, fh uint64) int {
+	if w, ok := f.getHandle(fh).(*writeHandle); ok {
+		return errno(w.Truncate(size))
+	}
+	reqPath := f.reqPath(path)
+	if w := f.getWriter(reqPath); w != nil {
+		return errno(w.Truncate(size))
+	}
+	obj, err := fs.Get(f.ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR
+	}
+	if obj.GetSize() == size {
+		return 0
+	}
+	var existing model.Obj
+	if size > 0 {
+		existing = obj
+	}
+	w, err := newWriteHandle(f.ctx, reqPath, existing)
+	if err != nil {
+		return errno(err)
+	}
+	if err = w.Truncate(size); err != nil {
+		_ = w.Close()
+		return errno(err)
+	}
+	return errno(w.Release())
+}
+
+func (f *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	n, err := h.ReadAt(buff, ofst)
+	if err != nil && n == 0 && !isEOF(err) {
+		return errno(err)
+	}
+	return n
+}
+
+func (f *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
+	w, ok := f.getHandle(fh).(*writeHandle)
+	if !ok {
+		return -fuse.EBADF
+	}
+	n, err := w.WriteAt(buff, ofst)
+	if err != nil {
+		return errno(err)
+	}
+	return n
+}
+
+func (f *Fs) Flush(path string, fh uint64) int {
+	if w, ok := f.getHandle(fh).(*writeHandle); ok {
+		return errno(w.Flush())
+	}
+	return 0
+}
+
+func (f *Fs) Release(path string, fh uint64) int {
+	h := f.removeHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	return errno(h.Release())
+}
+
+func (f *Fs) Fsync(path string, datasync bool, fh uint64) int {
+	return f.Flush(path, fh)
+}
+
+func (f *Fs) Opendir(path string) (int, uint64) {
+	obj, err := fs.Get(f.ctx, f.reqPath(path), &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if !obj.IsDir() {
+		return -fuse.ENOTDIR, ^uint64(0)
+	}
+	return 0, 0
+}
+
+func (f *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
+	objs, err := fs.List(f.ctx, f.reqPath(path), &fs.ListArgs{})
+	if err != nil {
+		return errno(err)
+	}
+	fill(".", nil, 0)
+	fill("..", nil, 0)
+	for _, obj := range objs {
+		stat := &fuse.Stat_t{}
+		fillStat(stat, obj)
+		if !fill(obj.GetName(), stat, 0) {
+			break
+		}
+	}
+	return 0
+}
+
+func (f *Fs) Releasedir(path string, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
+	return 0
+}
+
+const (
+	blockSize = 4096
+	// renameTmpPrefix is the prefix of the temp name of a destination being replaced by Rename
+	renameTmpPrefix = ".fuse_rename_"
+)
+
+func fillStat(stat *fuse.Stat_t, obj model.Obj) {
+	*stat = fuse.Stat_t{}
+	if obj.IsDir() {
+		stat.Mode = fuse.S_IFDIR | 0755
+		stat.Nlink = 2
+	} else {
+		stat.Mode = fuse.S_IFREG | 0644
+		stat.Nlink = 1
+		stat.Size = obj.GetSize()
+		stat.Blocks = (stat.Size + 511) / 512
+	}
+	stat.Blksize = blockSize
+	stat.Mtim = fuse.NewTimespec(obj.ModTime())
+	stat.Atim = stat.Mtim
+	stat.Ctim = fuse.NewTimespec(obj.CreateTime())
+	stat.Birthtim = stat.Ctim
+}
+
+// errno maps OpenList errors to negative FUSE error codes
+func errno(err error) int {
+	if err == nil {
+		return 0
+	}
+	switch {
+	case errs.IsNotFoundError(err):
+		return -fuse.ENOENT
+	case errors.Is(errors.Cause(err), errs.PermissionDenied):
+		return -fuse.EACCES
+	case errors.Is(errors.Cause(err), errs.UploadNotSupported):
+		return -fuse.EROFS
+	case errs.IsNotSupportError(err), errs.IsNotImplement(err):
+		return -fuse.ENOSYS
+	case errors.Is(errors.Cause(err), errs.NotFolder):
+		return -fuse.ENOTDIR
+	case errors.Is(errors.Cause(err), errs.NotFile):
+		return -fuse.EISDIR
+	case errors.Is(errors.Cause(err), errs.MoveBetweenTwoStorages):
+		return -fuse.EXDEV
+	}
+	log.Errorf("fuse: %+v", err)
+	return -fuse.EIO
 }
 
 var _ fuse.FileSystemInterface = (*Fs)(nil)
@@ -0,0 +1,190 @@
+package fuse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+type handle interface {
+	Path() string
+	ReadAt(p []byte, off int64) (int, error)
+	Release() error
+}
+
+// readHandle reads a file through the range reader of its link
+type readHandle struct {
+	mu   sync.Mutex
+	path string
+	file model.File
+	ss   *stream.SeekableStream
+}
+
+func newReadHandle(ctx context.Context, path string) (*readHandle, error) {
+	link, obj, err := fs.Link(ctx, path, model.LinkArgs{})
+	op.Audit(ctx, model.AuditDownload, err, path)
+	if err != nil {
+		return nil, err
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		_ = link.Close()
+		return nil, err
+	}
+	file, err := stream.NewReadAtSeeker(ss, 0, true)
+	if err != nil {
+		_ = ss.Close()
+		return nil, err
+	}
+	return &readHandle{path: path, file: file, ss: ss}, nil
+}
+
+func (h *readHandle) Path() string {
+	return h.path
+}
+
+func (h *readHandle) ReadAt(p []byte, off int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.file.ReadAt(p, off)
+}
+
+func (h *readHandle) Release() error {
+	return h.ss.Close()
+}
+
+// writeHandle buffers the whole file in a temp file and uploads it on flush,
+// since most drivers can only upload a complete stream
+type writeHandle struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	path     string
+	buffer   *os.File
+	dirty    bool
+	modified time.Time
+}
+
+// newWriteHandle opens path for writing, the content of existing is downloaded
+// first so that partial writes don't lose the rest of the file
+func newWriteHandle(ctx context.Context, path string, existing model.Obj) (*writeHandle, error) {
+	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	if err != nil {
+		return nil, err
+	}
+	w := &writeHandle{ctx: ctx, path: path, buffer: tmpFile, dirty: existing == nil, modified: time.Now()}
+	if existing != nil && existing.GetSize() > 0 {
+		if err = w.download(); err != nil {
+			_ = w.Close()
+			return nil, err
+		}
+		w.modified = existing.ModTime()
+	}
+	return w, nil
+}
+
+func (w *writeHandle) download() error {
+	r, err := newReadHandle(w.ctx, w.path)
+	if err != nil {
+		return err
+	}
+	defer r.Release()
+	_, err = utils.CopyWithBuffer(w.buffer, io.NewSectionReader(r.file, 0, r.ss.GetSize()))
+	return err
+}
+
+func (w *writeHandle) Path() string {
+	return w.path
+}
+
+func (w *writeHandle) Obj() (model.Obj, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	info, err := w.buffer.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &model.Object{
+		Name:     stdpath.Base(w.path),
+		Size:     info.Size(),
+		Modified: w.modified,
+	}, nil
+}
+
+func (w *writeHandle) ReadAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buffer.ReadAt(p, off)
+}
+
+func (w *writeHandle) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dirty = true
+	w.modified = time.Now()
+	return w.buffer.WriteAt(p, off)
+}
+
+func (w *writeHandle) Truncate(size int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dirty = true
+	w.modified = time.Now()
+	return w.buffer.Truncate(size)
+}
+
+// Flush uploads the buffered content if it has been changed
+func (w *writeHandle) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.dirty {
+		return nil
+	}
+	info, err := w.buffer.Stat()
+	if err != nil {
+		return err
+	}
+	dir, name := stdpath.Split(w.path)
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     info.Size(),
+			Modified: w.modified,
+		},
+		Mimetype: utils.GetMimeType(name),
+		// a SectionReader is a model.File, so the stream won't cache it again
+		// and won't remove the buffer when it's closed
+		Reader: io.NewSectionReader(w.buffer, 0, info.Size()),
+	}
+	if err = fs.PutDirectly(w.ctx, dir, s); err != nil {
+		return err
+	}
+	w.dirty = false
+	return nil
+}
+
+func (w *writeHandle) Release() error {
+	return errors.Join(w.Flush(), w.Close())
+}
+
+func (w *writeHandle) Close() error {
+	_ = w.buffer.Close()
+	return os.RemoveAll(w.buffer.Name())
+}
+
+func isEOF(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
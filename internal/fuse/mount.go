@@ -1,9 +1,24 @@
 package fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+import (
+	"context"
 
-func Mount(mountSrc, mountDst string, opts []string) {
-	fs := &Fs{RootFolder: mountSrc}
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// Mount mounts mountSrc of OpenList on mountDst, it blocks until
+// the file system is unmounted or ctx is done
+func Mount(ctx context.Context, mountSrc, mountDst string, opts []string) bool {
+	fs := NewFs(ctx, mountSrc)
 	host := fuse.NewFileSystemHost(fs)
-	go host.Mount(mountDst, opts)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			host.Unmount()
+		case <-done:
+		}
+	}()
+	return host.Mount(mountDst, opts)
 }
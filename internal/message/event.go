@@ -0,0 +1,74 @@
+package message
+
+import (
+	"sync"
+)
+
+// the buffer of a subscriber, the events are dropped for slow subscribers
+const subscriberBuffer = 64
+
+// Filter is called for each event published to a subscriber, it returns
+// the event to send, which may be rewritten for it, and whether to send
+type Filter func(event Message) (Message, bool)
+
+type subscriber struct {
+	ch     chan Message
+	filter Filter
+}
+
+var (
+	subscribersMu sync.RWMutex
+	subscribers   = make(map[*subscriber]struct{})
+)
+
+// Subscribe returns a channel receiving the published events passing filter,
+// cancel must be called to unsubscribe when the events are not needed
+func Subscribe(filter Filter) (events <-chan Message, cancel func()) {
+	s := &subscriber{
+		ch:     make(chan Message, subscriberBuffer),
+		filter: filter,
+	}
+	subscribersMu.Lock()
+	subscribers[s] = struct{}{}
+	subscribersMu.Unlock()
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			subscribersMu.Lock()
+			delete(subscribers, s)
+			subscribersMu.Unlock()
+		})
+	}
+}
+
+// HasSubscribers reports whether anyone is subscribing the events, so that
+// the publishers can skip the work to generate them
+func HasSubscribers() bool {
+	subscribersMu.RLock()
+	defer subscribersMu.RUnlock()
+	return len(subscribers) > 0
+}
+
+// Publish sends event to the subscribers without blocking, the filters are
+// called without holding the lock of the subscribers
+func Publish(event Message) {
+	subscribersMu.RLock()
+	ss := make([]*subscriber, 0, len(subscribers))
+	for s := range subscribers {
+		ss = append(ss, s)
+	}
+	subscribersMu.RUnlock()
+	for _, s := range ss {
+		e, ok := event, true
+		if s.filter != nil {
+			e, ok = s.filter(event)
+		}
+		if !ok {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the operations controlled by acl rules
+const (
+	ACLRead   int32 = 1 << iota // list, get and download
+	ACLWrite                    // mkdir and upload
+	ACLRename                   // rename
+	ACLMove                     // move
+	ACLCopy                     // copy
+	ACLRemove                   // remove
+)
+
+// ACLRule allows or denies operations in the path and its sub paths for a user
+// or the members of a group, the rule of the longest path decides
+type ACLRule struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Path    string `json:"path" gorm:"index" binding:"required"`
+	UserID  uint   `json:"user_id" gorm:"index"`  // 0 if the rule is for a group
+	GroupID uint   `json:"group_id" gorm:"index"` // 0 if the rule is for a user
+	Allow   int32  `json:"allow"`
+	Deny    int32  `json:"deny"`
+}
+
+func (r *ACLRule) Validate() error {
+	if (r.UserID == 0) == (r.GroupID == 0) {
+		return errors.New("either user or group is required")
+	}
+	if r.Allow&r.Deny != 0 {
+		return errors.New("an operation can't be both allowed and denied")
+	}
+	r.Path = utils.FixAndCleanPath(r.Path)
+	return nil
+}
+
+// Match reports whether the rule applies to user in path
+func (r *ACLRule) Match(user *User, path string) bool {
+	if r.UserID != 0 && r.UserID != user.ID {
+		return false
+	}
+	if r.GroupID != 0 && !user.InGroup(r.GroupID) {
+		return false
+	}
+	return utils.IsSubPath(r.Path, path)
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+const (
+	TokenScopeRead  = "read"
+	TokenScopeWrite = "write"
+	TokenScopeAdmin = "admin"
+)
+
+const apiTokenPrefix = "olt_"
+
+// the permissions kept by a read scoped token: see hidden files, access without password,
+// webdav read, ftp/sftp read and read archives
+const readScopePermissions int32 = 1<<0 | 1<<1 | 1<<8 | 1<<10 | 1<<12
+
+// APIToken is a credential of a user for scripts, it's accepted as the
+// Authorization header, the WebDAV password and the S3 access key pair
+type APIToken struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	Name   string `json:"name" binding:"required"`
+	// the access key id of S3
+	KeyID string `json:"key_id" gorm:"unique"`
+	// the secret access key of S3, S3 signatures need it in plain text, so it's
+	// separate from the secret of the token
+	Secret string `json:"-"`
+	// the hash of the secret of the token, the token itself isn't stored
+	TokenHash string `json:"-"`
+	Scope     string `json:"scope"`
+	// the token can only access this path under the base path of the user
+	Path     string     `json:"path"`
+	Expires  *time.Time `json:"expires"`
+	LastUsed *time.Time `json:"last_used"`
+	Created  time.Time  `json:"created"`
+}
+
+// NewToken generates the secret of the token and returns the token to put in
+// the Authorization header, only the hash of the secret is kept
+func (t *APIToken) NewToken() string {
+	secret := random.String(40)
+	t.TokenHash = hashAPITokenSecret(secret)
+	return apiTokenPrefix + t.KeyID + "_" + secret
+}
+
+// VerifySecret reports whether secret is the secret of the token
+func (t *APIToken) VerifySecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashAPITokenSecret(secret)), []byte(t.TokenHash)) == 1
+}
+
+// the secrets are random, so they are hashed without salts
+func hashAPITokenSecret(secret string) string {
+	return utils.HashData(utils.SHA256, []byte(secret))
+}
+
+func (t *APIToken) Expired() bool {
+	return t.Expires != nil && time.Now().After(*t.Expires)
+}
+
+// IsAPIToken reports whether token looks like an api token rather than a login token
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, apiTokenPrefix)
+}
+
+// ParseAPIToken splits token into the key id and the secret
+func ParseAPIToken(token string) (keyID, secret string, ok bool) {
+	return strings.Cut(strings.TrimPrefix(token, apiTokenPrefix), "_")
+}
+
+// Restrict returns a copy of user limited to the scope and the path of the token
+func (t *APIToken) Restrict(user *User) (*User, error) {
+	restricted := *user
+	// the token of the virtual root keeps all base paths
+	if !user.IsVirtualRoot(t.Path) {
+		basePath, err := user.JoinPath(t.Path)
+		if err != nil {
+			return nil, err
+		}
+		restricted.BasePath = basePath
+		restricted.BasePaths = nil
+	}
+	switch t.Scope {
+	case TokenScopeAdmin:
+		if !user.IsAdmin() {
+			return nil, errors.New("admin scope is only for admin")
+		}
+	case TokenScopeWrite:
+		if restricted.IsAdmin() {
+			restricted.Role = GENERAL
+		}
+	default:
+		if restricted.IsAdmin() {
+			restricted.Role = GENERAL
+		}
+		restricted.Permission &= readScopePermissions
+		restricted.ScopeMask = ACLRead
+	}
+	return &restricted, nil
+}
+
+func (t *APIToken) Validate() error {
+	switch t.Scope {
+	case TokenScopeRead, TokenScopeWrite, TokenScopeAdmin:
+	default:
+		return errors.Errorf("invalid scope: %s", t.Scope)
+	}
+	t.Path = utils.FixAndCleanPath(t.Path)
+	return nil
+}
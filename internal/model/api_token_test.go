@@ -0,0 +1,57 @@
+package model
+
+import "testing"
+
+func TestAPITokenRestrict(t *testing.T) {
+	admin := &User{Role: ADMIN, BasePath: "/", Permission: 0x7FFF}
+	read, err := (&APIToken{Scope: TokenScopeRead, Path: "/docs"}).Restrict(admin)
+	if err != nil {
+		t.Fatalf("failed restrict to read scope: %+v", err)
+	}
+	if read.IsAdmin() || read.BasePath != "/docs" {
+		t.Errorf("read scope: admin %v, base path %s, want a general user of /docs", read.IsAdmin(), read.BasePath)
+	}
+	if read.Permission != readScopePermissions {
+		t.Errorf("read scope: permission %b, want %b", read.Permission, readScopePermissions)
+	}
+	if !read.ScopeAllows(ACLRead) || read.ScopeAllows(ACLWrite) || read.ScopeAllows(ACLRemove) {
+		t.Errorf("read scope should only allow reading, mask %b", read.ScopeMask)
+	}
+	if !admin.IsAdmin() || admin.BasePath != "/" || admin.ScopeMask != 0 {
+		t.Errorf("the restricted user should be a copy, the user is changed: %+v", admin)
+	}
+
+	write, err := (&APIToken{Scope: TokenScopeWrite, Path: "/"}).Restrict(admin)
+	if err != nil {
+		t.Fatalf("failed restrict to write scope: %+v", err)
+	}
+	if write.IsAdmin() || write.Permission != admin.Permission || !write.ScopeAllows(ACLWrite) {
+		t.Errorf("write scope should keep the permissions except admin: %+v", write)
+	}
+
+	general := &User{Role: GENERAL, BasePath: "/home"}
+	if _, err = (&APIToken{Scope: TokenScopeAdmin, Path: "/"}).Restrict(general); err == nil {
+		t.Errorf("admin scope of a general user should fail")
+	}
+	if _, err = (&APIToken{Scope: TokenScopeRead, Path: "/../etc"}).Restrict(general); err == nil {
+		t.Errorf("the path out of the base path should fail")
+	}
+}
+
+func TestAPITokenSecret(t *testing.T) {
+	token := APIToken{KeyID: "key", Secret: "s3-secret"}
+	raw := token.NewToken()
+	keyID, secret, ok := ParseAPIToken(raw)
+	if !ok || keyID != "key" {
+		t.Fatalf("ParseAPIToken(%q) = %q, %q, %v", raw, keyID, secret, ok)
+	}
+	if !token.VerifySecret(secret) {
+		t.Errorf("the secret of the token is rejected")
+	}
+	// neither the stored hash nor the secret of S3 is accepted as the token
+	for _, s := range []string{token.TokenHash, token.Secret, ""} {
+		if token.VerifySecret(s) {
+			t.Errorf("VerifySecret(%q) = true, want false", s)
+		}
+	}
+}
@@ -84,6 +84,12 @@ type ArchiveDecompressArgs struct {
 	PutIntoNewDir bool
 }
 
+type ArchiveCompressArgs struct {
+	// Format is the extension of the archive, e.g. ".zip" or ".tar.gz"
+	Format   string
+	Password string
+}
+
 type RangeReaderIF interface {
 	RangeRead(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error)
 }
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// operations of the audit log, the admin operations are named by the kind
+// of the changed object, e.g. "user.update"
+const (
+	AuditUpload     = "upload"
+	AuditDownload   = "download"
+	AuditMkdir      = "mkdir"
+	AuditMove       = "move"
+	AuditCopy       = "copy"
+	AuditRename     = "rename"
+	AuditRemove     = "remove"
+	AuditDecompress = "decompress"
+	AuditCompress   = "compress"
+	AuditSymlink    = "symlink"
+
+	AuditMetaCreate        = "meta.create"
+	AuditMetaUpdate        = "meta.update"
+	AuditMetaDelete        = "meta.delete"
+	AuditStorageCreate     = "storage.create"
+	AuditStorageUpdate     = "storage.update"
+	AuditStorageDelete     = "storage.delete"
+	AuditStorageDisable    = "storage.disable"
+	AuditStorageEnable     = "storage.enable"
+	AuditUserCreate        = "user.create"
+	AuditUserUpdate        = "user.update"
+	AuditUserDelete        = "user.delete"
+	AuditSettingResetToken = "setting.reset_token"
+	AuditSettingSave       = "setting.save"
+	AuditSettingDelete     = "setting.delete"
+	AuditWebhookCreate     = "webhook.create"
+	AuditWebhookUpdate     = "webhook.update"
+	AuditWebhookDelete     = "webhook.delete"
+)
+
+// AuditLog records an operation of a user
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Time      time.Time `json:"time" gorm:"index"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username" gorm:"index"`
+	Protocol  string    `json:"protocol"` // web, webdav, ftp, sftp or s3
+	IP        string    `json:"ip"`
+	Operation string    `json:"operation" gorm:"index"`
+	Path      string    `json:"path"`
+	DstPath   string    `json:"dst_path"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error" gorm:"type:text"`
+}
+
+type AuditLogFilter struct {
+	Username  string `form:"username"`
+	Protocol  string `form:"protocol"`
+	Operation string `form:"operation"`
+	// Path matches the entries whose path or dst path contains it
+	Path    string    `form:"path"`
+	Success *bool     `form:"success"`
+	From    time.Time `form:"from" time_format:"unix"`
+	To      time.Time `form:"to" time_format:"unix"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FTPCert is a client certificate of a user to log in to FTPS, it's pinned by
+// its fingerprint instead of being verified by a CA
+type FTPCert struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserId       uint      `json:"-"`
+	Title        string    `json:"title"`
+	Fingerprint  string    `json:"fingerprint" gorm:"index"`
+	Subject      string    `json:"subject"`
+	NotAfter     time.Time `json:"not_after"`
+	CertStr      string    `gorm:"type:text" json:"-"`
+	AddedTime    time.Time `json:"added_time"`
+	LastUsedTime time.Time `json:"last_used_time"`
+}
+
+// ParseFTPCert parses the PEM encoded certificate
+func ParseFTPCert(certStr string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certStr))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// FTPCertFingerprint is the hex encoded SHA-256 of the DER of cert
+func FTPCertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *FTPCert) UpdateLastUsedTime() {
+	c.LastUsedTime = time.Now()
+}
+
+// FTPPassword is a password that only logs in to FTP, so that the devices
+// using FTP don't hold the password of the account
+type FTPPassword struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserId       uint      `json:"-"`
+	Title        string    `json:"title"`
+	PwdHash      string    `json:"-"`
+	Salt         string    `json:"-"`
+	AddedTime    time.Time `json:"added_time"`
+	LastUsedTime time.Time `json:"last_used_time"`
+}
+
+func (p *FTPPassword) ValidatePwdStaticHash(pwdStaticHash string) bool {
+	return pwdStaticHash != "" && p.PwdHash == HashPwd(pwdStaticHash, p.Salt)
+}
+
+func (p *FTPPassword) UpdateLastUsedTime() {
+	p.LastUsedTime = time.Now()
+}
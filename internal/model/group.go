@@ -0,0 +1,9 @@
+package model
+
+// Group is a set of users, the acl rules of a group apply to all its members
+type Group struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"unique" binding:"required"`
+	Description string `json:"description"`
+	TrafficLimit
+}
@@ -0,0 +1,22 @@
+package model
+
+// Quota limits the size and the number of the files uploaded by a user, the
+// usage is counted from the files uploaded by the user since the quota is set,
+// until they are removed permanently
+type Quota struct {
+	UserID    uint  `json:"user_id" gorm:"primaryKey"`
+	MaxBytes  int64 `json:"max_bytes"` // 0 means unlimited
+	MaxFiles  int64 `json:"max_files"` // 0 means unlimited
+	UsedBytes int64 `json:"used_bytes"`
+	UsedFiles int64 `json:"used_files"`
+}
+
+// QuotaFile records the uploader of a file counted in a quota, so that the
+// usage is given back to the uploader when the file is removed permanently
+type QuotaFile struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	StorageID uint   `json:"storage_id" gorm:"index"`
+	Path      string `json:"path" gorm:"index"`
+	UserID    uint   `json:"user_id"`
+	Size      int64  `json:"size"`
+}
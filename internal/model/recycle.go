@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// RecycleItem is an object moved to the recycle bin of a storage instead of
+// being removed, the paths are the actual paths in the storage
+type RecycleItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	StorageID uint      `json:"storage_id" gorm:"index"`
+	Path      string    `json:"path"`
+	TrashPath string    `json:"trash_path"`
+	Name      string    `json:"name"`
+	IsDir     bool      `json:"is_dir"`
+	Size      int64     `json:"size"`
+	Deleted   time.Time `json:"deleted" gorm:"index"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// S3MultipartUpload is a multipart upload of the S3 server, its parts are
+// staged in the temp dir until it's completed or aborted
+type S3MultipartUpload struct {
+	ID        string            `json:"id" gorm:"primaryKey"`
+	UserID    uint              `json:"user_id" gorm:"index"`
+	Bucket    string            `json:"bucket"`
+	ObjectKey string            `json:"object_key" gorm:"type:text"`
+	Meta      map[string]string `json:"meta" gorm:"serializer:json"`
+	Created   time.Time         `json:"created"`
+	Updated   time.Time         `json:"updated" gorm:"index"`
+}
+
+// S3MultipartPart is an uploaded part of a multipart upload, the same part
+// number uploaded again replaces it
+type S3MultipartPart struct {
+	UploadID   string    `json:"upload_id" gorm:"primaryKey"`
+	PartNumber int       `json:"part_number" gorm:"primaryKey;autoIncrement:false"`
+	Size       int64     `json:"size"`
+	ETag       string    `json:"etag"`
+	Updated    time.Time `json:"updated"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+const (
+	ScheduleCopy            = "copy"
+	ScheduleMove            = "move"
+	ScheduleOfflineDownload = "offline_download"
+	ScheduleIndexUpdate     = "index_update"
+	ScheduleStorageReload   = "storage_reload"
+	ScheduleSync            = "sync"
+)
+
+// Schedule runs an operation at the times matching its cron expression
+type Schedule struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+	// a standard cron expression, e.g. "0 3 * * *", in the local time zone
+	Cron string `json:"cron" binding:"required"`
+	Type string `json:"type" binding:"required"`
+	// the object to copy or move, the parent of the index to update
+	// or the mount path of the storage to reload
+	SrcPath string `json:"src_path"`
+	// the dir to copy, move or download to
+	DstPath string `json:"dst_path"`
+	// the arguments of offline download
+	URL          string `json:"url"`
+	Tool         string `json:"tool"`
+	DeletePolicy string `json:"delete_policy"`
+	// the sync job to run
+	SyncJobID uint `json:"sync_job_id"`
+
+	Disabled   bool       `json:"disabled"`
+	LastRun    *time.Time `json:"last_run"`
+	LastResult string     `json:"last_result"`
+}
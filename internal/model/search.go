@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -17,14 +18,33 @@ type SearchReq struct {
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
 	Scope int `json:"scope"`
+	// size range in bytes, 0 for no limit
+	MinSize int64 `json:"min_size"`
+	MaxSize int64 `json:"max_size"`
+	// modified time range, nil for no limit
+	ModifiedAfter  *time.Time `json:"modified_after"`
+	ModifiedBefore *time.Time `json:"modified_before"`
+	// file extensions without the dot, e.g. mp4
+	Exts []string `json:"exts"`
+	// obj types, see conf.FOLDER, conf.VIDEO, etc.
+	Types []int `json:"types"`
+	// name, size or modified
+	OrderBy string `json:"order_by"`
+	// asc or desc
+	OrderDirection string `json:"order_direction"`
 	PageReq
 }
 
 type SearchNode struct {
-	Parent string `json:"parent" gorm:"index"`
-	Name   string `json:"name"`
-	IsDir  bool   `json:"is_dir"`
-	Size   int64  `json:"size"`
+	Parent   string    `json:"parent" gorm:"index"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	// the json of utils.HashInfo
+	Hash string `json:"hash"`
+	// obj type, see utils.GetObjType
+	Type int `json:"type"`
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,9 +54,32 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	if p.MaxSize > 0 && p.MinSize > p.MaxSize {
+		return fmt.Errorf("min_size can't > max_size")
+	}
+	if p.ModifiedAfter != nil && p.ModifiedBefore != nil && p.ModifiedAfter.After(*p.ModifiedBefore) {
+		return fmt.Errorf("modified_after can't > modified_before")
+	}
+	switch p.OrderBy {
+	case "", "name", "size", "modified":
+	default:
+		return fmt.Errorf("invalid order_by: %s", p.OrderBy)
+	}
+	switch p.OrderDirection {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("invalid order_direction: %s", p.OrderDirection)
+	}
+	for i, ext := range p.Exts {
+		p.Exts[i] = strings.ToLower(strings.TrimPrefix(ext, "."))
+	}
 	return nil
 }
 
-func (s *SearchNode) Type() string {
-	return "SearchNode"
+// Order returns the field and whether the order is descending, name asc by default
+func (p *SearchReq) Order() (string, bool) {
+	if p.OrderBy == "" {
+		return "name", p.OrderDirection == "desc"
+	}
+	return p.OrderBy, p.OrderDirection == "desc"
 }
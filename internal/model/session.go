@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Session is a login token of a user, the token itself is not saved, the ID is its hash
+type Session struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:64"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires" gorm:"index"`
+}
+
+func (s *Session) Expired() bool {
+	return time.Now().After(s.Expires)
+}
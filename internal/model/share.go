@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// the permissions kept by the visitors of a share, see User.Permission
+const sharePermissions int32 = 1<<0 | 1<<1
+
+// Share is a public link of a file or folder, it can be visited without an account
+type Share struct {
+	ID     string `json:"id" gorm:"primaryKey;size:16"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	// Path is relative to the base path of the creator
+	Path         string     `json:"path"`
+	Password     string     `json:"-"`
+	Expires      *time.Time `json:"expires"`
+	MaxDownloads int        `json:"max_downloads"` // 0 means unlimited
+	AllowUpload  bool       `json:"allow_upload"`
+	Disabled     bool       `json:"disabled"`
+	Views        int        `json:"views"`
+	Downloads    int        `json:"downloads"`
+	LastAccess   *time.Time `json:"last_access"`
+	Created      time.Time  `json:"created"`
+}
+
+func (s *Share) Expired() bool {
+	return s.Expires != nil && time.Now().After(*s.Expires)
+}
+
+func (s *Share) DownloadsExceeded() bool {
+	return s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads
+}
+
+func (s *Share) Validate() error {
+	if s.MaxDownloads < 0 {
+		return errors.New("max downloads can't be negative")
+	}
+	return nil
+}
+
+// Visitor returns a copy of the creator restricted to the share, it's used as
+// the user of the requests to the share
+func (s *Share) Visitor(creator *User) (*User, error) {
+	basePath, err := creator.JoinPath(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	visitor := *creator
+	visitor.BasePath = basePath
+	visitor.BasePaths = nil
+	if visitor.IsAdmin() {
+		visitor.Role = GENERAL
+	}
+	perm := sharePermissions
+	if s.AllowUpload {
+		perm |= 1 << 3
+	}
+	visitor.Permission &= perm
+	return &visitor, nil
+}
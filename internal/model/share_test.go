@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareLimits(t *testing.T) {
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	expires := map[*time.Time]bool{nil: false, &past: true, &future: false}
+	for e, want := range expires {
+		if got := (&Share{Expires: e}).Expired(); got != want {
+			t.Errorf("Expired with expires %v = %v, want %v", e, got, want)
+		}
+	}
+	downloads := []struct {
+		max, downloads int
+		exceeded       bool
+	}{
+		{0, 100, false},
+		{3, 2, false},
+		{3, 3, true},
+	}
+	for _, d := range downloads {
+		s := &Share{MaxDownloads: d.max, Downloads: d.downloads}
+		if got := s.DownloadsExceeded(); got != d.exceeded {
+			t.Errorf("DownloadsExceeded of %d/%d = %v, want %v", d.downloads, d.max, got, d.exceeded)
+		}
+	}
+	b, err := json.Marshal(&Share{ID: "s", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Errorf("the password is in the json of share: %s", b)
+	}
+}
@@ -19,6 +19,7 @@ type Storage struct {
 	EnableSign      bool      `json:"enable_sign"`
 	Sort
 	Proxy
+	RecycleBin
 }
 
 type Sort struct {
@@ -30,10 +31,25 @@ type Sort struct {
 type Proxy struct {
 	WebProxy     bool   `json:"web_proxy"`
 	WebdavPolicy string `json:"webdav_policy"`
+	S3Policy     string `json:"s3_policy"`
 	ProxyRange   bool   `json:"proxy_range"`
 	DownProxyUrl string `json:"down_proxy_url"`
 }
 
+type RecycleBin struct {
+	EnableRecycleBin bool `json:"enable_recycle_bin"`
+	// the dir in the storage to keep the removed objects, /.recycle_bin if empty
+	RecycleBinPath string `json:"recycle_bin_path"`
+	// the days to keep the removed objects, 0 to keep them until purged
+	RecycleBinExpiration int `json:"recycle_bin_expiration"`
+}
+
+// StorageDetails is the space of a storage reported by driver.WithDetails
+type StorageDetails struct {
+	TotalSpace uint64 `json:"total_space"`
+	FreeSpace  uint64 `json:"free_space"`
+}
+
 func (s *Storage) GetStorage() *Storage {
 	return s
 }
@@ -57,3 +73,7 @@ func (p Proxy) WebdavProxy() bool {
 func (p Proxy) WebdavNative() bool {
 	return !p.Webdav302() && !p.WebdavProxy()
 }
+
+func (p Proxy) S3Redirect() bool {
+	return p.S3Policy == "307_redirect"
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+const (
+	SyncOneWay = "one_way"
+	SyncTwoWay = "two_way"
+)
+
+// SyncJob syncs the files of SrcPath to DstPath, they may be on different storages
+type SyncJob struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Name    string `json:"name"`
+	SrcPath string `json:"src_path" binding:"required"`
+	DstPath string `json:"dst_path" binding:"required"`
+	// one_way copies the new and changed files from src to dst,
+	// two_way also copies the new and newer files from dst back to src, and
+	// deletes the objects on one side which have been deleted on the other
+	// since the last sync, see SyncEntry
+	Mode string `json:"mode"`
+	// delete the files in dst that are not in src, only for one_way
+	DeleteExtraneous bool `json:"delete_extraneous"`
+	// the job is run on demand or by a schedule of type sync
+	LastRun    *time.Time `json:"last_run"`
+	LastResult string     `json:"last_result"`
+}
+
+// SyncEntry is an object which was in sync on both sides at the last run of a
+// two_way job, an entry missing on one side has been deleted there. The files
+// changed on the remaining side since then are copied instead of deleted
+type SyncEntry struct {
+	ID    uint `json:"id" gorm:"primaryKey"`
+	JobID uint `json:"job_id" gorm:"index"`
+	// Path is relative to the src path and the dst path of the job
+	Path        string    `json:"path"`
+	IsDir       bool      `json:"is_dir"`
+	Size        int64     `json:"size"`
+	SrcModified time.Time `json:"src_modified"`
+	DstModified time.Time `json:"dst_modified"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// TrafficLimit limits the transfer speed and the monthly traffic of a user or
+// the members of a group, 0 means unlimited
+type TrafficLimit struct {
+	DownloadSpeed  int   `json:"download_speed"`  // KB/s
+	UploadSpeed    int   `json:"upload_speed"`    // KB/s
+	MonthlyTraffic int64 `json:"monthly_traffic"` // bytes of download and upload
+}
+
+// Merge returns the stricter one of l and other for each limit
+func (l TrafficLimit) Merge(other TrafficLimit) TrafficLimit {
+	lower := func(a, b int64) int64 {
+		if a <= 0 || (b > 0 && b < a) {
+			return b
+		}
+		return a
+	}
+	return TrafficLimit{
+		DownloadSpeed:  int(lower(int64(l.DownloadSpeed), int64(other.DownloadSpeed))),
+		UploadSpeed:    int(lower(int64(l.UploadSpeed), int64(other.UploadSpeed))),
+		MonthlyTraffic: lower(l.MonthlyTraffic, other.MonthlyTraffic),
+	}
+}
+
+const (
+	TrafficDayFormat   = "2006-01-02"
+	TrafficMonthFormat = "2006-01"
+)
+
+// Traffic counts the bytes transferred by a user in a period, which is a day
+// formatted by TrafficDayFormat or a month formatted by TrafficMonthFormat
+type Traffic struct {
+	UserID   uint   `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Period   string `json:"period" gorm:"primaryKey;size:10"`
+	Download int64  `json:"download"`
+	Upload   int64  `json:"upload"`
+}
+
+func (t Traffic) Total() int64 {
+	return t.Download + t.Upload
+}
+
+func DayPeriod(t time.Time) string {
+	return t.Format(TrafficDayFormat)
+}
+
+func MonthPeriod(t time.Time) string {
+	return t.Format(TrafficMonthFormat)
+}
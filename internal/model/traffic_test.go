@@ -0,0 +1,16 @@
+package model
+
+import "testing"
+
+func TestTrafficLimitMerge(t *testing.T) {
+	user := TrafficLimit{DownloadSpeed: 100, MonthlyTraffic: 1 << 30}
+	group := TrafficLimit{DownloadSpeed: 200, UploadSpeed: 50, MonthlyTraffic: 1 << 20}
+	got := user.Merge(group)
+	want := TrafficLimit{DownloadSpeed: 100, UploadSpeed: 50, MonthlyTraffic: 1 << 20}
+	if got != want {
+		t.Errorf("Merge() = %+v, want %+v", got, want)
+	}
+	if got = (TrafficLimit{}).Merge(TrafficLimit{}); got != (TrafficLimit{}) {
+		t.Errorf("Merge() of unlimited = %+v, want unlimited", got)
+	}
+}
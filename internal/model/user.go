@@ -4,6 +4,10 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"net"
+	stdpath "path"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -54,10 +58,27 @@ type User struct {
 	//   11: ftp/sftp write
 	//   12: can read archives
 	//   13: can decompress archives
+	//   14: can create share links
 	Permission int32  `json:"permission"`
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	GroupIDs   []uint `json:"group_ids" gorm:"serializer:json"`
+	// BasePaths replace BasePath if set, they are shown as the folders of a
+	// virtual root, named by the last element of the paths
+	BasePaths []string `json:"base_paths" gorm:"serializer:json"`
+	// FTPAllowedIPs are the IPs and CIDRs the user can log in to FTP from, any
+	// address is allowed if it's empty
+	FTPAllowedIPs []string `json:"ftp_allowed_ips" gorm:"serializer:json"`
+	// ScopeMask limits the acl operations of a restricted user, e.g. by an api
+	// token, neither acl rules nor metas can allow more. 0 means no limit
+	ScopeMask int32 `json:"-" gorm:"-"`
+	TrafficLimit
+}
+
+// ScopeAllows reports whether ScopeMask allows the acl operation
+func (u *User) ScopeAllows(operation int32) bool {
+	return u.ScopeMask == 0 || u.ScopeMask&operation != 0
 }
 
 func (u *User) IsGuest() bool {
@@ -145,8 +166,131 @@ func (u *User) CanDecompress() bool {
 	return (u.Permission>>13)&1 == 1
 }
 
+func (u *User) CanShare() bool {
+	return (u.Permission>>14)&1 == 1
+}
+
+func (u *User) InGroup(groupID uint) bool {
+	return slices.Contains(u.GroupIDs, groupID)
+}
+
+// HasVirtualRoot reports whether the root of the user is made up of BasePaths
+func (u *User) HasVirtualRoot() bool {
+	return len(u.BasePaths) > 0
+}
+
+// IsVirtualRoot reports whether reqPath is the virtual root of the user
+func (u *User) IsVirtualRoot(reqPath string) bool {
+	return u.HasVirtualRoot() && utils.FixAndCleanPath(reqPath) == "/"
+}
+
+// VirtualRootObjs returns the folders in the virtual root of the user
+func (u *User) VirtualRootObjs() []Obj {
+	objs := make([]Obj, 0, len(u.BasePaths))
+	for _, basePath := range u.BasePaths {
+		objs = append(objs, &Object{
+			Name:     stdpath.Base(basePath),
+			IsFolder: true,
+		})
+	}
+	return objs
+}
+
+// JoinPath converts reqPath of the user to the actual path, the first element
+// of reqPath selects one of BasePaths if the user has a virtual root
 func (u *User) JoinPath(reqPath string) (string, error) {
-	return utils.JoinBasePath(u.BasePath, reqPath)
+	if !u.HasVirtualRoot() {
+		return utils.JoinBasePath(u.BasePath, reqPath)
+	}
+	reqPath, err := utils.JoinBasePath("/", reqPath)
+	if err != nil {
+		return "", err
+	}
+	name, subPath, _ := strings.Cut(strings.TrimPrefix(reqPath, "/"), "/")
+	if name == "" {
+		return "", errors.WithStack(errs.VirtualRoot)
+	}
+	for _, basePath := range u.BasePaths {
+		if stdpath.Base(basePath) == name {
+			return stdpath.Join(basePath, subPath), nil
+		}
+	}
+	return "", errors.WithStack(errs.ObjectNotFound)
+}
+
+// RelPath converts an actual path to the path seen by the user, it's the reverse
+// of JoinPath, ok is false if path is out of the base paths
+func (u *User) RelPath(path string) (relPath string, ok bool) {
+	path = utils.FixAndCleanPath(path)
+	if !u.HasVirtualRoot() {
+		basePath := utils.FixAndCleanPath(u.BasePath)
+		if !utils.IsSubPath(basePath, path) {
+			return "", false
+		}
+		return utils.FixAndCleanPath(strings.TrimPrefix(path, basePath)), true
+	}
+	for _, basePath := range u.BasePaths {
+		if utils.IsSubPath(basePath, path) {
+			return stdpath.Join("/", stdpath.Base(basePath), strings.TrimPrefix(path, basePath)), true
+		}
+	}
+	return "", false
+}
+
+// ValidateBasePaths cleans BasePaths, their names must be unique and they
+// can't contain each other
+func (u *User) ValidateBasePaths() error {
+	names := make(map[string]struct{}, len(u.BasePaths))
+	for i, basePath := range u.BasePaths {
+		basePath = utils.FixAndCleanPath(basePath)
+		if basePath == "/" {
+			return errors.New("the root can't be one of the base paths")
+		}
+		name := stdpath.Base(basePath)
+		if _, ok := names[name]; ok {
+			return errors.Errorf("the name [%s] of the base paths is duplicated", name)
+		}
+		names[name] = struct{}{}
+		for _, other := range u.BasePaths[:i] {
+			if utils.IsSubPath(basePath, other) || utils.IsSubPath(other, basePath) {
+				return errors.Errorf("the base paths [%s] and [%s] overlap", other, basePath)
+			}
+		}
+		u.BasePaths[i] = basePath
+	}
+	return nil
+}
+
+// ValidateFTPAllowedIPs cleans FTPAllowedIPs, they must be IPs or CIDRs
+func (u *User) ValidateFTPAllowedIPs() error {
+	for i, v := range u.FTPAllowedIPs {
+		v = strings.TrimSpace(v)
+		if _, _, err := net.ParseCIDR(v); err != nil && net.ParseIP(v) == nil {
+			return errors.Errorf("[%s] is neither an IP nor a CIDR", v)
+		}
+		u.FTPAllowedIPs[i] = v
+	}
+	return nil
+}
+
+// FTPAllowsIP reports whether the user can log in to FTP from ip
+func (u *User) FTPAllowsIP(ip net.IP) bool {
+	if len(u.FTPAllowedIPs) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, v := range u.FTPAllowedIPs {
+		if _, ipNet, err := net.ParseCIDR(v); err == nil {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		} else if allowed := net.ParseIP(v); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 func StaticHash(password string) string {
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestUserVirtualRoot(t *testing.T) {
+	user := &User{BasePaths: []string{"/team-a/", "/shared/media"}}
+	if err := user.ValidateBasePaths(); err != nil {
+		t.Fatalf("failed validate base paths: %+v", err)
+	}
+	joins := map[string]string{
+		"/team-a":          "/team-a",
+		"/media/movie.mkv": "/shared/media/movie.mkv",
+		"media/a/b":        "/shared/media/a/b",
+		"/other":           "",
+		"/":                "",
+		"/media/../team-a": "",
+	}
+	for reqPath, want := range joins {
+		got, err := user.JoinPath(reqPath)
+		if (err != nil) != (want == "") || got != want {
+			t.Errorf("JoinPath(%s) = %s, %v, want %s", reqPath, got, err, want)
+		}
+		if want == "" {
+			continue
+		}
+		if relPath, ok := user.RelPath(got); !ok || relPath != "/"+strings.TrimPrefix(reqPath, "/") {
+			t.Errorf("RelPath(%s) = %s, %v", got, relPath, ok)
+		}
+	}
+	if _, ok := user.RelPath("/shared/other"); ok {
+		t.Errorf("RelPath of a path out of the base paths should fail")
+	}
+	for _, basePaths := range [][]string{{"/a/x", "/b/x"}, {"/a", "/a/b"}, {"/"}} {
+		if err := (&User{BasePaths: basePaths}).ValidateBasePaths(); err == nil {
+			t.Errorf("ValidateBasePaths(%v) should fail", basePaths)
+		}
+	}
+}
+
+func TestUserFTPAllowsIP(t *testing.T) {
+	user := &User{FTPAllowedIPs: []string{" 192.168.1.0/24", "10.0.0.5", "fd00::/8"}}
+	if err := user.ValidateFTPAllowedIPs(); err != nil {
+		t.Fatalf("failed validate ftp allowed ips: %+v", err)
+	}
+	ips := map[string]bool{
+		"192.168.1.20": true,
+		"192.168.2.20": false,
+		"10.0.0.5":     true,
+		"10.0.0.6":     false,
+		"fd00::1":      true,
+		"::1":          false,
+	}
+	for ip, want := range ips {
+		if got := user.FTPAllowsIP(net.ParseIP(ip)); got != want {
+			t.Errorf("FTPAllowsIP(%s) = %v, want %v", ip, got, want)
+		}
+	}
+	if !(&User{}).FTPAllowsIP(net.ParseIP("1.2.3.4")) {
+		t.Errorf("any ip should be allowed without the allowed ips")
+	}
+	if err := (&User{FTPAllowedIPs: []string{"example.com"}}).ValidateFTPAllowedIPs(); err == nil {
+		t.Errorf("ValidateFTPAllowedIPs of a host name should fail")
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// WebDAVLock is a lock of the WebDAV LOCK method, it's persisted so that
+// locks survive restarts and can be shared by several instances
+type WebDAVLock struct {
+	Token     string        `json:"token" gorm:"primaryKey"`
+	Root      string        `json:"root" gorm:"index"`
+	Duration  time.Duration `json:"duration"`
+	Expiry    time.Time     `json:"expiry"`
+	OwnerXML  string        `json:"owner_xml" gorm:"type:text"`
+	ZeroDepth bool          `json:"zero_depth"`
+}
+
+// WebDAVLockGuard is the row updated first by each creation of the locks, the
+// row lock serializes the conflict checks of the instances sharing the database
+type WebDAVLockGuard struct {
+	ID      uint  `json:"id" gorm:"primaryKey"`
+	Version int64 `json:"version"`
+}
+
+// Expired reports whether the lock has a finite duration and has expired at now
+func (l *WebDAVLock) Expired(now time.Time) bool {
+	return l.Duration >= 0 && !now.Before(l.Expiry)
+}
+
+// WebDAVProp is a dead property set by PROPPATCH on the object at Path
+type WebDAVProp struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Path string `json:"path" gorm:"uniqueIndex:idx_webdav_prop"`
+	// Parent is the dir of Path, the props of a listing are loaded by it
+	Parent   string `json:"parent" gorm:"index"`
+	Space    string `json:"space" gorm:"uniqueIndex:idx_webdav_prop"`
+	Local    string `json:"local" gorm:"uniqueIndex:idx_webdav_prop"`
+	Lang     string `json:"lang"`
+	InnerXML string `json:"inner_xml" gorm:"type:text"`
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	EventFileUpload    = "file.upload"
+	EventFileRemove    = "file.remove"
+	EventFileRename    = "file.rename"
+	EventFileMove      = "file.move"
+	EventTaskSucceeded = "task.succeeded"
+	EventTaskFailed    = "task.failed"
+	EventLoginFailed   = "login.failed"
+	EventStorageStatus = "storage.status"
+	// EventPing is only sent by testing a webhook
+	EventPing = "ping"
+)
+
+var WebhookEvents = []string{
+	EventFileUpload, EventFileRemove, EventFileRename, EventFileMove,
+	EventTaskSucceeded, EventTaskFailed, EventLoginFailed, EventStorageStatus,
+}
+
+// Webhook receives the events as signed POST requests
+type Webhook struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" binding:"required"`
+	URL  string `json:"url" binding:"required"`
+	// Secret signs the body of the requests if set
+	Secret string `json:"secret"`
+	// Events are the events sent to the webhook, all events if empty
+	Events   []string `json:"events" gorm:"serializer:json"`
+	Disabled bool     `json:"disabled"`
+}
+
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid webhook url: %s", w.URL)
+	}
+	for _, event := range w.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return errors.Errorf("unknown webhook event: %s", event)
+		}
+	}
+	return nil
+}
+
+func (w *Webhook) Subscribed(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// WebhookDelivery records the delivery of an event to a webhook
+type WebhookDelivery struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WebhookID  uint      `json:"webhook_id" gorm:"index"`
+	Event      string    `json:"event"`
+	Payload    string    `json:"payload" gorm:"type:text"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error" gorm:"type:text"`
+	Created    time.Time `json:"created" gorm:"index"`
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/tache"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -208,4 +209,12 @@ func (t *DownloadTask) GetStatus() string {
 	return t.Status
 }
 
+func (t *DownloadTask) OnSucceeded() {
+	webhook.EmitTask(t, true)
+}
+
+func (t *DownloadTask) OnFailed() {
+	webhook.EmitTask(t, false)
+}
+
 var DownloadTaskManager *tache.Manager[*DownloadTask]
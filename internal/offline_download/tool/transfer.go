@@ -14,6 +14,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/pkg/http_range"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/tache"
@@ -65,7 +66,7 @@ func (t *TransferTask) Run() error {
 				Mimetype: mimetype,
 				Closers:  utils.NewClosers(r),
 			}
-			return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, s, t.SetProgress)
+			return t.put(s)
 		}
 		return transferStdPath(t)
 	} else {
@@ -73,6 +74,18 @@ func (t *TransferTask) Run() error {
 	}
 }
 
+// put uploads file to the destination, the quota of the task creator is enforced
+func (t *TransferTask) put(file model.FileStreamer) error {
+	quotaDone, err := op.QuotaPut(t.Ctx(), t.Creator, t.DstStorage, t.DstDirPath, file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	err = op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, file, t.SetProgress)
+	quotaDone(err)
+	return err
+}
+
 func (t *TransferTask) GetName() string {
 	if t.DeletePolicy == UploadDownloadStream {
 		return fmt.Sprintf("upload [%s](%s) to [%s](%s)", t.SrcObjPath, t.Url, t.DstStorageMp, t.DstDirPath)
@@ -92,6 +105,7 @@ func (t *TransferTask) OnSucceeded() {
 			removeObjTemp(t)
 		}
 	}
+	webhook.EmitTask(t, true)
 }
 
 func (t *TransferTask) OnFailed() {
@@ -102,6 +116,7 @@ func (t *TransferTask) OnFailed() {
 			removeObjTemp(t)
 		}
 	}
+	webhook.EmitTask(t, false)
 }
 
 var (
@@ -191,7 +206,7 @@ func transferStdFile(t *TransferTask) error {
 		Closers:  utils.NewClosers(rc),
 	}
 	t.SetTotalBytes(info.Size())
-	return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, s, t.SetProgress)
+	return t.put(s)
 }
 
 func removeStdTemp(t *TransferTask) {
@@ -292,7 +307,7 @@ func transferObjFile(t *TransferTask) error {
 		return errors.WithMessagef(err, "failed get [%s] stream", t.SrcObjPath)
 	}
 	t.SetTotalBytes(srcFile.GetSize())
-	return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, ss, t.SetProgress)
+	return t.put(ss)
 }
 
 func removeObjTemp(t *TransferTask) {
@@ -300,7 +315,7 @@ func removeObjTemp(t *TransferTask) {
 	if err != nil || srcObj.IsDir() {
 		return
 	}
-	if err := op.Remove(t.Ctx(), t.SrcStorage, t.SrcObjPath); err != nil {
+	if err := op.RemovePermanently(t.Ctx(), t.SrcStorage, t.SrcObjPath); err != nil {
 		log.Errorf("failed to delete temp obj %s, error: %s", t.SrcObjPath, err.Error())
 	}
 }
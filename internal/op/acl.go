@@ -0,0 +1,170 @@
+package op
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// all acl rules are cached since they are checked on every operation
+var (
+	aclMu     sync.RWMutex
+	aclRules  []model.ACLRule
+	aclLoaded bool
+)
+
+func getACLRules() ([]model.ACLRule, error) {
+	aclMu.RLock()
+	if aclLoaded {
+		defer aclMu.RUnlock()
+		return aclRules, nil
+	}
+	aclMu.RUnlock()
+	aclMu.Lock()
+	defer aclMu.Unlock()
+	if !aclLoaded {
+		rules, err := db.GetACLRules()
+		if err != nil {
+			return nil, err
+		}
+		aclRules, aclLoaded = rules, true
+	}
+	return aclRules, nil
+}
+
+func clearACLCache() {
+	aclMu.Lock()
+	defer aclMu.Unlock()
+	aclRules, aclLoaded = nil, false
+}
+
+// CheckACL evaluates the acl rules of user for operation in path, matched is false
+// if no rule is about it. The rule of the longest path decides, then the rules of
+// the user take precedence over the rules of groups, then deny over allow.
+// The rules don't apply to admin, and can't allow more than the scope of user
+func CheckACL(user *model.User, path string, operation int32) (allowed, matched bool) {
+	if user.IsAdmin() {
+		return false, false
+	}
+	rules, err := getACLRules()
+	if err != nil {
+		log.Errorf("failed get acl rules: %+v", err)
+		return false, true
+	}
+	var best *model.ACLRule
+	for i := range rules {
+		r := &rules[i]
+		if (r.Allow|r.Deny)&operation == 0 || !r.Match(user, path) {
+			continue
+		}
+		if best == nil || aclRuleBefore(r, best, operation) {
+			best = r
+		}
+	}
+	if best == nil {
+		return false, false
+	}
+	return best.Deny&operation == 0 && user.ScopeAllows(operation), true
+}
+
+func aclRuleBefore(a, b *model.ACLRule, operation int32) bool {
+	if len(a.Path) != len(b.Path) {
+		return len(a.Path) > len(b.Path)
+	}
+	if (a.UserID != 0) != (b.UserID != 0) {
+		return a.UserID != 0
+	}
+	return a.Deny&operation != 0 && b.Deny&operation == 0
+}
+
+func GetACLRules() ([]model.ACLRule, error) {
+	return db.GetACLRules()
+}
+
+func checkACLRule(r *model.ACLRule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if r.UserID != 0 {
+		_, err := db.GetUserById(r.UserID)
+		return err
+	}
+	_, err := db.GetGroupById(r.GroupID)
+	return err
+}
+
+func CreateACLRule(r *model.ACLRule) error {
+	if err := checkACLRule(r); err != nil {
+		return err
+	}
+	defer clearACLCache()
+	return db.CreateACLRule(r)
+}
+
+func UpdateACLRule(r *model.ACLRule) error {
+	if _, err := db.GetACLRuleById(r.ID); err != nil {
+		return err
+	}
+	if err := checkACLRule(r); err != nil {
+		return err
+	}
+	defer clearACLCache()
+	return db.UpdateACLRule(r)
+}
+
+func DeleteACLRuleById(id uint) error {
+	defer clearACLCache()
+	return db.DeleteACLRuleById(id)
+}
+
+func GetGroups() ([]model.Group, error) {
+	return db.GetGroups()
+}
+
+func GetGroupById(id uint) (*model.Group, error) {
+	return db.GetGroupById(id)
+}
+
+func CreateGroup(g *model.Group) error {
+	defer clearGroupCache()
+	return db.CreateGroup(g)
+}
+
+func UpdateGroup(g *model.Group) error {
+	if _, err := db.GetGroupById(g.ID); err != nil {
+		return err
+	}
+	defer clearGroupCache()
+	return db.UpdateGroup(g)
+}
+
+// DeleteGroupById deletes the group with its acl rules, and removes it from its members
+func DeleteGroupById(id uint) error {
+	if _, err := db.GetGroupById(id); err != nil {
+		return err
+	}
+	users, err := db.GetAllUsers()
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		u := &users[i]
+		if !u.InGroup(id) {
+			continue
+		}
+		u.GroupIDs = slices.DeleteFunc(u.GroupIDs, func(gid uint) bool { return gid == id })
+		if err = UpdateUser(u); err != nil {
+			return errors.WithMessagef(err, "failed remove user [%s] from group", u.Username)
+		}
+	}
+	defer clearACLCache()
+	defer clearGroupCache()
+	if err = db.DeleteACLRulesByGroupId(id); err != nil {
+		return err
+	}
+	return db.DeleteGroupById(id)
+}
@@ -0,0 +1,69 @@
+package op_test
+
+import (
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+func TestCheckACL(t *testing.T) {
+	group := model.Group{Name: "team"}
+	if err := op.CreateGroup(&group); err != nil {
+		t.Fatalf("failed create group: %+v", err)
+	}
+	user := model.User{Username: "acl", Permission: 0xFF, GroupIDs: []uint{group.ID}}
+	if err := op.CreateUser(&user); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	rules := []model.ACLRule{
+		{Path: "/team", GroupID: group.ID, Deny: model.ACLWrite | model.ACLRemove},
+		{Path: "/team/upload", GroupID: group.ID, Allow: model.ACLWrite},
+		{Path: "/team/upload/private", GroupID: group.ID, Allow: model.ACLRead},
+		{Path: "/team/upload/private", UserID: user.ID, Deny: model.ACLRead},
+	}
+	for i := range rules {
+		if err := op.CreateACLRule(&rules[i]); err != nil {
+			t.Fatalf("failed create acl rule: %+v", err)
+		}
+	}
+	tests := []struct {
+		path      string
+		operation int32
+		allowed   bool
+		matched   bool
+	}{
+		{"/other", model.ACLWrite, false, false},
+		{"/team", model.ACLWrite, false, true},
+		{"/team/a", model.ACLRemove, false, true},
+		{"/team/a", model.ACLRename, false, false},
+		{"/teams", model.ACLWrite, false, false},
+		{"/team/upload/a", model.ACLWrite, true, true},
+		{"/team/upload/a", model.ACLRemove, false, true},
+		{"/team/upload/private", model.ACLRead, false, true},
+	}
+	for _, tt := range tests {
+		allowed, matched := op.CheckACL(&user, tt.path, tt.operation)
+		if allowed != tt.allowed || matched != tt.matched {
+			t.Errorf("CheckACL(%s, %d) = %v, %v, want %v, %v", tt.path, tt.operation, allowed, matched, tt.allowed, tt.matched)
+		}
+	}
+	// an allow rule can't give back what the scope of a token takes away
+	token := model.APIToken{Scope: model.TokenScopeRead, Path: "/"}
+	restricted, err := token.Restrict(&user)
+	if err != nil {
+		t.Fatalf("failed restrict user: %+v", err)
+	}
+	if allowed, matched := op.CheckACL(restricted, "/team/upload/a", model.ACLWrite); allowed || !matched {
+		t.Errorf("CheckACL of read scope = %v, %v, want false, true", allowed, matched)
+	}
+	if allowed, _ := op.CheckACL(restricted, "/team/upload/private", model.ACLRead); allowed {
+		t.Errorf("the user deny rule doesn't apply to the restricted user")
+	}
+	if err := op.DeleteGroupById(group.ID); err != nil {
+		t.Fatalf("failed delete group: %+v", err)
+	}
+	if _, matched := op.CheckACL(&user, "/team", model.ACLWrite); matched {
+		t.Errorf("the rules of the deleted group still apply")
+	}
+}
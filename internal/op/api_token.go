@@ -0,0 +1,104 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the last used time is saved at most once in this duration
+const apiTokenLastUsedPrecision = time.Minute
+
+// CreateAPIToken creates a token of user and returns it, the secrets are
+// generated. The token can't be got again, since only its hash is stored
+func CreateAPIToken(user *model.User, t *model.APIToken) (string, error) {
+	if user.IsGuest() {
+		return "", errors.New("guest can't create api tokens")
+	}
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	if t.Scope == model.TokenScopeAdmin && !user.IsAdmin() {
+		return "", errors.New("admin scope is only for admin")
+	}
+	if !user.IsVirtualRoot(t.Path) {
+		if _, err := user.JoinPath(t.Path); err != nil {
+			return "", err
+		}
+	}
+	t.ID = 0
+	t.UserID = user.ID
+	t.KeyID = random.String(20)
+	t.Secret = random.String(40)
+	token := t.NewToken()
+	t.LastUsed = nil
+	t.Created = time.Now()
+	if err := db.CreateAPIToken(t); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func GetAPITokensByUser(userID uint) ([]model.APIToken, error) {
+	return db.GetAPITokensByUserId(userID)
+}
+
+func DeleteAPIToken(userID, id uint) error {
+	return db.DeleteAPIToken(userID, id)
+}
+
+// GetAPITokenUser returns the token of keyID and its user restricted to the token,
+// the secret is not verified
+func GetAPITokenUser(keyID string) (*model.APIToken, *model.User, error) {
+	t, err := db.GetAPITokenByKeyID(keyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.Expired() {
+		return nil, nil, errors.New("api token has expired")
+	}
+	user, err := GetUserById(t.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Disabled {
+		return nil, nil, errors.New("the user of api token is disabled")
+	}
+	restricted, err := t.Restrict(user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, restricted, nil
+}
+
+// TouchAPIToken saves the last used time of the token after it's verified
+func TouchAPIToken(t *model.APIToken) {
+	now := time.Now()
+	if t.LastUsed != nil && now.Sub(*t.LastUsed) < apiTokenLastUsedPrecision {
+		return
+	}
+	if err := db.UpdateAPITokenLastUsed(t.ID, now); err != nil {
+		log.Warnf("failed update last used time of api token %d: %+v", t.ID, err)
+	}
+}
+
+// ValidateAPIToken checks the token and returns its user restricted to the token
+func ValidateAPIToken(token string) (*model.User, error) {
+	keyID, secret, ok := model.ParseAPIToken(token)
+	if !ok {
+		return nil, errors.New("invalid api token")
+	}
+	t, user, err := GetAPITokenUser(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.VerifySecret(secret) {
+		return nil, errors.New("invalid api token")
+	}
+	TouchAPIToken(t)
+	return user, nil
+}
@@ -0,0 +1,108 @@
+package op
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const auditBatchSize = 100
+
+// the entries are written by a worker in batches, so that the operations
+// are not slowed down by the database
+var (
+	auditCh   = make(chan *model.AuditLog, 1024)
+	auditOnce sync.Once
+)
+
+// Audit records an operation of the user in ctx on paths, which are the
+// source path and the optional destination path, err is the result
+func Audit(ctx context.Context, operation string, err error, paths ...string) {
+	if item, _ := GetSettingItemByKey(conf.AuditLogEnabled); item == nil || item.Value != "true" {
+		return
+	}
+	entry := &model.AuditLog{
+		Time:      time.Now(),
+		Operation: operation,
+		Success:   err == nil,
+	}
+	if user, ok := ctx.Value(conf.UserKey).(*model.User); ok {
+		entry.UserID = user.ID
+		entry.Username = user.Username
+	}
+	entry.Protocol, _ = ctx.Value(conf.ProtocolKey).(string)
+	entry.IP, _ = ctx.Value(conf.ClientIPKey).(string)
+	if len(paths) > 0 {
+		entry.Path = paths[0]
+	}
+	if len(paths) > 1 {
+		entry.DstPath = paths[1]
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	auditOnce.Do(func() { go auditWorker() })
+	select {
+	case auditCh <- entry:
+	default:
+		log.Warnf("audit log queue is full, drop entry: %+v", entry)
+	}
+}
+
+func auditWorker() {
+	var sink *os.File
+	if conf.Conf != nil && conf.Conf.AuditLogFile != "" {
+		var err error
+		if err = os.MkdirAll(filepath.Dir(conf.Conf.AuditLogFile), 0o700); err == nil {
+			sink, err = os.OpenFile(conf.Conf.AuditLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		}
+		if err != nil {
+			log.Errorf("failed open audit log file: %+v", err)
+		}
+	}
+	batch := make([]*model.AuditLog, 0, auditBatchSize)
+	for entry := range auditCh {
+		batch = append(batch[:0], entry)
+	drain:
+		for len(batch) < auditBatchSize {
+			select {
+			case entry = <-auditCh:
+				batch = append(batch, entry)
+			default:
+				break drain
+			}
+		}
+		if err := db.CreateAuditLogs(batch); err != nil {
+			log.Errorf("failed write %d audit logs: %+v", len(batch), err)
+		}
+		if sink != nil {
+			enc := json.NewEncoder(sink)
+			for _, entry := range batch {
+				if err := enc.Encode(entry); err != nil {
+					log.Errorf("failed write audit log file: %+v", err)
+					break
+				}
+			}
+		}
+	}
+}
+
+func GetAuditLogs(filter model.AuditLogFilter, pageIndex, pageSize int) ([]model.AuditLog, int64, error) {
+	return db.GetAuditLogs(filter, pageIndex, pageSize)
+}
+
+// ExpireAuditLogs deletes the entries older than the retention days, 0 keeps all entries
+func ExpireAuditLogs(days int) error {
+	if days <= 0 {
+		return nil
+	}
+	return db.DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -days))
+}
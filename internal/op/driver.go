@@ -99,6 +99,12 @@ func getMainItems(config driver.Config) []driver.Item {
 			Options:  "302_redirect,use_proxy_url,native_proxy",
 			Default:  "302_redirect",
 			Required: true,
+		}, {
+			Name:     "s3_policy",
+			Type:     conf.TypeSelect,
+			Options:  "307_redirect,native_proxy",
+			Default:  "native_proxy",
+			Required: true,
 		},
 		}...)
 		if config.ProxyRangeOption {
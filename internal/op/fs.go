@@ -25,6 +25,7 @@ var listG singleflight.Group[[]model.Obj]
 
 func updateCacheObj(storage driver.Driver, path string, oldObj model.Obj, newObj model.Obj) {
 	key := Key(storage, path)
+	defer HandleDirChangeHook(key)
 	objs, ok := listCache.Get(key)
 	if ok {
 		for i, obj := range objs {
@@ -45,6 +46,7 @@ func updateCacheObj(storage driver.Driver, path string, oldObj model.Obj, newObj
 
 func delCacheObj(storage driver.Driver, path string, obj model.Obj) {
 	key := Key(storage, path)
+	defer HandleDirChangeHook(key)
 	objs, ok := listCache.Get(key)
 	if ok {
 		for i, oldObj := range objs {
@@ -61,6 +63,7 @@ var addSortDebounceMap generic_sync.MapOf[string, func(func())]
 
 func addCacheObj(storage driver.Driver, path string, newObj model.Obj) {
 	key := Key(storage, path)
+	defer HandleDirChangeHook(key)
 	objs, ok := listCache.Get(key)
 	if ok {
 		for i, obj := range objs {
@@ -92,11 +95,16 @@ func addCacheObj(storage driver.Driver, path string, newObj model.Obj) {
 }
 
 func ClearCache(storage driver.Driver, path string) {
+	clearCache(storage, path)
+	HandleDirChangeHook(Key(storage, path))
+}
+
+func clearCache(storage driver.Driver, path string) {
 	objs, ok := listCache.Get(Key(storage, path))
 	if ok {
 		for _, obj := range objs {
 			if obj.IsDir() {
-				ClearCache(storage, stdpath.Join(path, obj.GetName()))
+				clearCache(storage, stdpath.Join(path, obj.GetName()))
 			}
 		}
 	}
@@ -412,6 +420,9 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		quotaMove(storage, srcPath, stdpath.Join(dstDirPath, srcObj.GetName()))
+	}
 	return errors.WithStack(err)
 }
 
@@ -446,6 +457,9 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		quotaMove(storage, srcPath, stdpath.Join(srcDirPath, dstName))
+	}
 	return errors.WithStack(err)
 }
 
@@ -487,7 +501,19 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	return errors.WithStack(err)
 }
 
+// Remove moves the object to the recycle bin if it's enabled for the storage,
+// otherwise it removes the object permanently
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
+	return remove(ctx, storage, path, false)
+}
+
+// RemovePermanently removes the object without the recycle bin, it's used for
+// the objects that have been copied or moved elsewhere
+func RemovePermanently(ctx context.Context, storage driver.Driver, path string) error {
+	return remove(ctx, storage, path, true)
+}
+
+func remove(ctx context.Context, storage driver.Driver, path string, permanently bool) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
@@ -505,6 +531,11 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 		return errors.WithMessage(err, "failed to get object")
 	}
 	dirPath := stdpath.Dir(path)
+	if !permanently && storage.GetStorage().EnableRecycleBin {
+		if recycled, err := recycle(ctx, storage, path, rawObj); recycled || err != nil {
+			return err
+		}
+	}
 
 	switch s := storage.(type) {
 	case driver.Remove:
@@ -515,6 +546,8 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 			if rawObj.IsDir() {
 				ClearCache(storage, path)
 			}
+			forgetRecycled(storage, path)
+			quotaRelease(storage, path)
 		}
 	default:
 		return errs.NotImplement
@@ -538,6 +571,11 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 		dstDirPath, link = urlTreeSplitLineFormPath(stdpath.Join(dstDirPath, file.GetName()))
 		file = &stream.FileStream{Obj: &model.Object{Name: link}}
 	}
+	if file.GetSize() < 0 && !storage.Config().UnknownSizePut {
+		if _, err := file.CacheFullInTempFile(); err != nil {
+			return errors.WithMessage(err, "failed cache the stream of unknown size")
+		}
+	}
 	// if file exist and size = 0, delete it
 	dstDirPath = utils.FixAndCleanPath(dstDirPath)
 	dstPath := stdpath.Join(dstDirPath, file.GetName())
@@ -546,7 +584,7 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	fi, err := GetUnwrap(ctx, storage, dstPath)
 	if err == nil {
 		if fi.GetSize() == 0 {
-			err = Remove(ctx, storage, dstPath)
+			err = RemovePermanently(ctx, storage, dstPath)
 			if err != nil {
 				return errors.WithMessagef(err, "while uploading, failed remove existing file which size = 0")
 			}
@@ -603,7 +641,7 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 			}
 		} else {
 			// upload success, remove old obj
-			err := Remove(ctx, storage, tempPath)
+			err := RemovePermanently(ctx, storage, tempPath)
 			if err != nil {
 				return err
 			} else {
@@ -654,3 +692,76 @@ func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url
 	log.Debugf("put url [%s](%s) done", dstName, url)
 	return errors.WithStack(err)
 }
+
+func SetModTime(ctx context.Context, storage driver.Driver, path string, modTime time.Time) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	path = utils.FixAndCleanPath(path)
+	s, ok := storage.(driver.SetModTime)
+	if !ok {
+		return errs.NotImplement
+	}
+	obj, err := GetUnwrap(ctx, storage, path)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get object")
+	}
+	err = s.SetModTime(ctx, obj, modTime)
+	if err == nil {
+		ClearCache(storage, stdpath.Dir(path))
+	}
+	return errors.WithStack(err)
+}
+
+// Symlink creates a symbolic link at path which points to target, both of them are actual paths.
+// The target must be a file, since the metas and acl rules of the objects under
+// a linked dir would be checked by the paths through the link instead of theirs
+func Symlink(ctx context.Context, storage driver.Driver, path, target string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	path = utils.FixAndCleanPath(path)
+	target = utils.FixAndCleanPath(target)
+	s, ok := storage.(driver.Symlink)
+	if !ok {
+		return errs.NotImplement
+	}
+	targetObj, err := Get(ctx, storage, target)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get target")
+	}
+	if targetObj.IsDir() {
+		return errors.WithMessage(errs.NotSupport, "can't link to a dir")
+	}
+	dirPath, linkName := stdpath.Split(path)
+	parentDir, err := GetUnwrap(ctx, storage, dirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get parent dir")
+	}
+	err = s.Symlink(ctx, parentDir, linkName, target)
+	if err == nil {
+		ClearCache(storage, dirPath)
+	}
+	return errors.WithStack(err)
+}
+
+// ReadLink returns the actual path which the symbolic link at path points to
+func ReadLink(ctx context.Context, storage driver.Driver, path string) (string, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return "", errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	path = utils.FixAndCleanPath(path)
+	s, ok := storage.(driver.Symlink)
+	if !ok {
+		return "", errs.NotImplement
+	}
+	obj, err := GetUnwrap(ctx, storage, path)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to get object")
+	}
+	target, err := s.ReadLink(ctx, obj)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return utils.FixAndCleanPath(target), nil
+}
@@ -0,0 +1,54 @@
+package op_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+func TestSymlink(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "private", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "private", "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/symlink",
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/symlink")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	// a link to a dir would expose the objects under it through another path
+	if err = op.Symlink(ctx, storage, "/dir-link", "/private"); err == nil {
+		t.Errorf("Symlink to a dir succeeded")
+	}
+	if err = op.Symlink(ctx, storage, "/file-link", "/private/a.txt"); err != nil {
+		t.Fatalf("failed symlink to a file: %+v", err)
+	}
+	target, err := op.ReadLink(ctx, storage, "/file-link")
+	if err != nil || target != "/private/a.txt" {
+		t.Errorf("ReadLink = %s, %v, want /private/a.txt", target, err)
+	}
+	// a link to a link to a dir is rejected too
+	if err = os.Symlink("private", filepath.Join(root, "raw-link")); err != nil {
+		t.Fatal(err)
+	}
+	op.ClearCache(storage, "/")
+	if err = op.Symlink(ctx, storage, "/chained-link", "/raw-link"); err == nil {
+		t.Errorf("Symlink to a link to a dir succeeded")
+	}
+}
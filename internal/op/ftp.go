@@ -0,0 +1,111 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+func CreateFTPCert(c *model.FTPCert) (error, bool) {
+	_, err := db.GetFTPCertByUserTitle(c.UserId, c.Title)
+	if err == nil {
+		return errors.New("cert with the same title already exists"), true
+	}
+	cert, err := model.ParseFTPCert(c.CertStr)
+	if err != nil {
+		return err, false
+	}
+	c.Fingerprint = model.FTPCertFingerprint(cert)
+	if _, err = db.GetFTPCertByFingerprint(c.UserId, c.Fingerprint); err == nil {
+		return errors.New("the cert has been added"), true
+	}
+	c.Subject = cert.Subject.String()
+	c.NotAfter = cert.NotAfter
+	c.AddedTime = time.Now()
+	c.LastUsedTime = c.AddedTime
+	return db.CreateFTPCert(c), true
+}
+
+func GetFTPCertsByUserId(userId uint, pageIndex, pageSize int) (certs []model.FTPCert, count int64, err error) {
+	return db.GetFTPCertsByUserId(userId, pageIndex, pageSize)
+}
+
+func GetFTPCertByIdAndUserId(id uint, userId uint) (*model.FTPCert, error) {
+	cert, err := db.GetFTPCertById(id)
+	if err != nil {
+		return nil, err
+	}
+	if cert.UserId != userId {
+		return nil, errors.New("failed get ftp cert")
+	}
+	return cert, nil
+}
+
+// GetFTPCertByFingerprint returns the cert of the user with the fingerprint
+func GetFTPCertByFingerprint(userId uint, fingerprint string) (*model.FTPCert, error) {
+	return db.GetFTPCertByFingerprint(userId, fingerprint)
+}
+
+func UpdateFTPCert(c *model.FTPCert) error {
+	return db.UpdateFTPCert(c)
+}
+
+func DeleteFTPCertById(id uint) error {
+	return db.DeleteFTPCertById(id)
+}
+
+// CreateFTPPassword generates the password of p, which is only returned here
+func CreateFTPPassword(p *model.FTPPassword) (string, error) {
+	_, err := db.GetFTPPasswordByUserTitle(p.UserId, p.Title)
+	if err == nil {
+		return "", errors.New("ftp password with the same title already exists")
+	}
+	pwd := random.String(24)
+	p.Salt = random.String(16)
+	p.PwdHash = model.TwoHashPwd(pwd, p.Salt)
+	p.AddedTime = time.Now()
+	p.LastUsedTime = p.AddedTime
+	return pwd, db.CreateFTPPassword(p)
+}
+
+func GetFTPPasswordsByUserId(userId uint, pageIndex, pageSize int) (pwds []model.FTPPassword, count int64, err error) {
+	return db.GetFTPPasswordsByUserId(userId, pageIndex, pageSize)
+}
+
+func GetFTPPasswordByIdAndUserId(id uint, userId uint) (*model.FTPPassword, error) {
+	pwd, err := db.GetFTPPasswordById(id)
+	if err != nil {
+		return nil, err
+	}
+	if pwd.UserId != userId {
+		return nil, errors.New("failed get ftp password")
+	}
+	return pwd, nil
+}
+
+// ValidateFTPPassword reports whether pwdStaticHash is one of the ftp passwords of the user
+func ValidateFTPPassword(userId uint, pwdStaticHash string) bool {
+	pwds, err := db.GetAllFTPPasswordsByUserId(userId)
+	if err != nil {
+		utils.Log.Errorf("failed get ftp passwords: %+v", err)
+		return false
+	}
+	for _, p := range pwds {
+		if p.ValidatePwdStaticHash(pwdStaticHash) {
+			p.UpdateLastUsedTime()
+			if err = db.UpdateFTPPassword(&p); err != nil {
+				utils.Log.Errorf("failed update ftp password: %+v", err)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func DeleteFTPPasswordById(id uint) error {
+	return db.DeleteFTPPasswordById(id)
+}
@@ -29,6 +29,25 @@ func HandleObjsUpdateHook(parent string, objs []model.Obj) {
 	}
 }
 
+// Dir
+type DirChangeHook = func(dirPath string)
+
+var (
+	dirChangeHooks = make([]DirChangeHook, 0)
+)
+
+// RegisterDirChangeHook registers a hook called with the full path of the dir
+// when its cache is updated or invalidated, the hook must not block
+func RegisterDirChangeHook(hook DirChangeHook) {
+	dirChangeHooks = append(dirChangeHooks, hook)
+}
+
+func HandleDirChangeHook(dirPath string) {
+	for _, hook := range dirChangeHooks {
+		hook(dirPath)
+	}
+}
+
 // Setting
 type SettingItemHook func(item *model.SettingItem) error
 
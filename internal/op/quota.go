@@ -0,0 +1,169 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// GetUserQuota returns the quota of user, it returns nil if the user has no quota
+func GetUserQuota(userID uint) (*model.Quota, error) {
+	q, err := db.GetQuotaByUserId(userID)
+	if err != nil {
+		if errors.Is(errors.Cause(err), gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return q, nil
+}
+
+func GetQuotas() ([]model.Quota, error) {
+	return db.GetQuotas()
+}
+
+// SetUserQuota sets the limits of the quota of a user, the usage is kept
+func SetUserQuota(q *model.Quota) error {
+	if q.MaxBytes < 0 || q.MaxFiles < 0 {
+		return errors.New("the limits of quota can't be negative")
+	}
+	if _, err := db.GetUserById(q.UserID); err != nil {
+		return err
+	}
+	old, err := GetUserQuota(q.UserID)
+	if err != nil {
+		return err
+	}
+	q.UsedBytes, q.UsedFiles = 0, 0
+	if old != nil {
+		q.UsedBytes, q.UsedFiles = old.UsedBytes, old.UsedFiles
+	}
+	return db.SaveQuota(q)
+}
+
+func DeleteUserQuota(userID uint) error {
+	return db.DeleteQuotaByUserId(userID)
+}
+
+// QuotaPut reserves the usage of file in the quota of user before it's put into
+// dstDirPath of storage, done must be called with the result of the put so that
+// the reservation is released if the put failed. A stream of unknown size is
+// cached first if the user has a quota. The user is recorded as the
+// uploader of the file, whose usage is given back when it's removed permanently
+func QuotaPut(ctx context.Context, user *model.User, storage driver.Driver, dstDirPath string, file model.FileStreamer) (done func(error), err error) {
+	storageID, path := storage.GetStorage().ID, stdpath.Join(dstDirPath, file.GetName())
+	// the uploader of an overwritten file gets its usage back, whoever overwrites it
+	old, _ := db.GetQuotaFile(storageID, path)
+	var q *model.Quota
+	if user != nil {
+		if q, err = GetUserQuota(user.ID); err != nil {
+			return func(error) {}, err
+		}
+	}
+	if q != nil && file.GetSize() < 0 {
+		// the stream of unknown size is cached to reserve its actual size, or
+		// it would never be counted in the quota
+		if _, err := file.CacheFullInTempFile(); err != nil {
+			return func(error) {}, errors.WithMessage(err, "failed cache the stream of unknown size")
+		}
+	}
+	bytes, files := file.GetSize(), int64(1)
+	if q == nil {
+		bytes, files = 0, 0
+	} else if old != nil && old.UserID == user.ID {
+		// an overwritten file of the same uploader only changes the usage by the difference of the size
+		bytes -= old.Size
+		files = 0
+	}
+	reserved := max(bytes, 0)
+	if q != nil {
+		ok, err := db.ReserveQuotaUsage(user.ID, reserved, files)
+		if err != nil {
+			return func(error) {}, err
+		}
+		if !ok {
+			return func(error) {}, errors.WithStack(errs.QuotaExceeded)
+		}
+	}
+	return func(err error) {
+		if err != nil {
+			if reserved != 0 || files != 0 {
+				addQuotaUsage(user.ID, -reserved, -files)
+			}
+			return
+		}
+		if delta := bytes - reserved; delta != 0 {
+			addQuotaUsage(user.ID, delta, 0)
+		}
+		if old != nil && (q == nil || old.UserID != user.ID) {
+			addQuotaUsage(old.UserID, -old.Size, -1)
+		}
+		if q == nil {
+			if old != nil {
+				if err := db.DeleteQuotaFile(storageID, path); err != nil {
+					log.Errorf("failed delete uploader of [%s]: %+v", path, err)
+				}
+			}
+			return
+		}
+		if err := db.SaveQuotaFile(&model.QuotaFile{
+			StorageID: storageID,
+			Path:      path,
+			UserID:    user.ID,
+			Size:      file.GetSize(),
+		}); err != nil {
+			log.Errorf("failed save uploader of [%s]: %+v", path, err)
+		}
+	}, nil
+}
+
+// quotaRelease gives the usage of the files at path or under it back to their
+// uploaders, it's called after the files are removed permanently
+func quotaRelease(storage driver.Driver, path string) {
+	files, err := db.GetQuotaFilesByPath(storage.GetStorage().ID, path)
+	if err != nil {
+		log.Errorf("failed get uploaders of [%s]: %+v", path, err)
+		return
+	}
+	if len(files) == 0 {
+		return
+	}
+	type usage struct{ bytes, files int64 }
+	usages := make(map[uint]*usage)
+	for _, f := range files {
+		u, ok := usages[f.UserID]
+		if !ok {
+			u = &usage{}
+			usages[f.UserID] = u
+		}
+		u.bytes += f.Size
+		u.files++
+	}
+	for userID, u := range usages {
+		addQuotaUsage(userID, -u.bytes, -u.files)
+	}
+	if err := db.DeleteQuotaFilesByPath(storage.GetStorage().ID, path); err != nil {
+		log.Errorf("failed delete uploaders of [%s]: %+v", path, err)
+	}
+}
+
+// quotaMove keeps the uploaders of the files moved from srcPath to dstPath,
+// including the files moved into and out of the recycle bin
+func quotaMove(storage driver.Driver, srcPath, dstPath string) {
+	if err := db.MoveQuotaFiles(storage.GetStorage().ID, srcPath, dstPath); err != nil {
+		log.Errorf("failed move uploaders of [%s]: %+v", srcPath, err)
+	}
+}
+
+func addQuotaUsage(userID uint, bytes, files int64) {
+	if err := db.AddQuotaUsage(userID, bytes, files); err != nil {
+		log.Errorf("failed update quota usage of user %d: %+v", userID, err)
+	}
+}
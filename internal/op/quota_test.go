@@ -0,0 +1,106 @@
+package op_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+)
+
+func TestQuotaPut(t *testing.T) {
+	root := t.TempDir()
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/quota",
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/quota")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	user := model.User{Username: "quota", BasePath: "/"}
+	if err = op.CreateUser(&user); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	if err = op.SetUserQuota(&model.Quota{UserID: user.ID, MaxBytes: 15}); err != nil {
+		t.Fatalf("failed set quota: %+v", err)
+	}
+	put := func(name string, size int64) (func(error), error) {
+		file := &stream.FileStream{Obj: &model.Object{Name: name, Size: size}}
+		return op.QuotaPut(ctx, &user, storage, "/", file)
+	}
+	usage := func(bytes, files int64) {
+		t.Helper()
+		q, err := op.GetUserQuota(user.ID)
+		if err != nil || q.UsedBytes != bytes || q.UsedFiles != files {
+			t.Errorf("quota = %+v, %v, want %d bytes of %d files", q, err, bytes, files)
+		}
+	}
+
+	done, err := put("a.txt", 10)
+	if err != nil {
+		t.Fatalf("failed put a.txt: %+v", err)
+	}
+	usage(10, 1)
+	done(nil)
+	usage(10, 1)
+
+	if _, err = put("b.txt", 10); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("put b.txt = %v, want %v", err, errs.QuotaExceeded)
+	}
+	usage(10, 1)
+
+	// a failed put releases its reservation
+	done, err = put("c.txt", 5)
+	if err != nil {
+		t.Fatalf("failed put c.txt: %+v", err)
+	}
+	usage(15, 2)
+	done(errors.New("failed"))
+	usage(10, 1)
+
+	// an overwritten file is charged by the difference of the size
+	done, err = put("a.txt", 12)
+	if err != nil {
+		t.Fatalf("failed overwrite a.txt: %+v", err)
+	}
+	done(nil)
+	usage(12, 1)
+
+	tempDir := conf.Conf.TempDir
+	conf.Conf.TempDir = t.TempDir()
+	defer func() { conf.Conf.TempDir = tempDir }()
+	// a stream of unknown size, e.g. a chunked request body, is charged by its actual size
+	file := &stream.FileStream{Obj: &model.Object{Name: "d.txt", Size: -1}, Reader: io.MultiReader(strings.NewReader("abc"))}
+	done, err = op.QuotaPut(ctx, &user, storage, "/", file)
+	if err != nil {
+		t.Fatalf("failed put d.txt: %+v", err)
+	}
+	usage(15, 2)
+	done(nil)
+	_ = file.Close()
+	usage(15, 2)
+
+	// the usage is given back when the file is removed
+	if err = os.WriteFile(filepath.Join(root, "a.txt"), make([]byte, 12), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = op.Remove(ctx, storage, "/a.txt"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	usage(3, 1)
+}
@@ -0,0 +1,271 @@
+package op
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/driver"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultRecycleBinPath = "/.recycle_bin"
+
+// the storages which can rename but can't move objects recycle them in place,
+// by renaming them to recycledPrefix<unix nano>_<name>
+const recycledPrefix = ".recycled_"
+
+var recycledNameReg = regexp.MustCompile(`^` + regexp.QuoteMeta(recycledPrefix) + `\d+_`)
+
+func recycleBinPath(storage driver.Driver) string {
+	if p := storage.GetStorage().RecycleBinPath; p != "" {
+		return utils.FixAndCleanPath(p)
+	}
+	return defaultRecycleBinPath
+}
+
+func canMove(storage driver.Driver) bool {
+	switch storage.(type) {
+	case driver.Move, driver.MoveResult:
+		return true
+	}
+	return false
+}
+
+func canRename(storage driver.Driver) bool {
+	switch storage.(type) {
+	case driver.Rename, driver.RenameResult:
+		return true
+	}
+	return false
+}
+
+// inRecycleBin reports whether path is in the recycle bin of storage or in an
+// object recycled in place
+func inRecycleBin(storage driver.Driver, path string) bool {
+	if utils.IsSubPath(recycleBinPath(storage), path) {
+		return true
+	}
+	for _, name := range strings.Split(path, "/") {
+		if recycledNameReg.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// trashRoot returns the object to remove to purge item, the dir of its own in
+// the recycle bin, or the object itself if it's recycled in place
+func trashRoot(storage driver.Driver, item *model.RecycleItem) string {
+	if utils.IsSubPath(recycleBinPath(storage), item.TrashPath) {
+		return stdpath.Dir(item.TrashPath)
+	}
+	return item.TrashPath
+}
+
+// HideRecycled removes the recycle bin and the objects recycled in place from
+// objs listed in dirPath, they are managed by the recycle bin api instead
+func HideRecycled(storage driver.Driver, dirPath string, objs []model.Obj) []model.Obj {
+	if !storage.GetStorage().EnableRecycleBin {
+		return objs
+	}
+	binPath := recycleBinPath(storage)
+	ret := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		path := stdpath.Join(dirPath, obj.GetName())
+		if utils.PathEqual(path, binPath) || recycledNameReg.MatchString(obj.GetName()) {
+			continue
+		}
+		ret = append(ret, obj)
+	}
+	return ret
+}
+
+// moveRecycledProps moves the WebDAV dead props of the object along with it,
+// failing to move them doesn't fail recycling
+func moveRecycledProps(storage driver.Driver, srcPath, dstPath string) {
+	mountPath := storage.GetStorage().MountPath
+	srcPath, dstPath = utils.GetFullPath(mountPath, srcPath), utils.GetFullPath(mountPath, dstPath)
+	if err := MoveWebDAVProps(srcPath, dstPath); err != nil {
+		log.Warnf("failed move webdav props of %s to %s: %+v", srcPath, dstPath, err)
+	}
+}
+
+// recycle moves the object at path to a dir of its own in the recycle bin, so that
+// the objects of the same name don't conflict and the name is kept for restoring.
+// It returns false if the object should be removed permanently instead
+func recycle(ctx context.Context, storage driver.Driver, path string, obj model.Obj) (bool, error) {
+	binPath := recycleBinPath(storage)
+	if inRecycleBin(storage, path) {
+		// the objects in the recycle bin are purged
+		return false, nil
+	}
+	if utils.IsSubPath(path, binPath) {
+		return true, errors.Errorf("can't remove %s, the recycle bin is in it", path)
+	}
+	now := time.Now()
+	var trashPath string
+	switch {
+	case canMove(storage):
+		trashDir := stdpath.Join(binPath, fmt.Sprintf("%d", now.UnixNano()))
+		if err := MakeDir(ctx, storage, trashDir); err != nil {
+			return true, errors.WithMessage(err, "failed make dir in recycle bin")
+		}
+		if err := Move(ctx, storage, path, trashDir); err != nil {
+			if e := RemovePermanently(ctx, storage, trashDir); e != nil {
+				log.Errorf("failed remove %s: %+v", trashDir, e)
+			}
+			return true, errors.WithMessage(err, "failed move to recycle bin")
+		}
+		trashPath = stdpath.Join(trashDir, obj.GetName())
+	case canRename(storage):
+		trashName := fmt.Sprintf("%s%d_%s", recycledPrefix, now.UnixNano(), obj.GetName())
+		if err := Rename(ctx, storage, path, trashName); err != nil {
+			return true, errors.WithMessage(err, "failed rename to recycle")
+		}
+		trashPath = stdpath.Join(stdpath.Dir(path), trashName)
+	default:
+		log.Warnf("storage %s can't move or rename objects, remove %s permanently", storage.GetStorage().MountPath, path)
+		return false, nil
+	}
+	moveRecycledProps(storage, path, trashPath)
+	err := db.CreateRecycleItem(&model.RecycleItem{
+		StorageID: storage.GetStorage().ID,
+		Path:      path,
+		TrashPath: trashPath,
+		Name:      obj.GetName(),
+		IsDir:     obj.IsDir(),
+		Size:      obj.GetSize(),
+		Deleted:   now,
+	})
+	return true, errors.WithMessage(err, "failed save recycle item")
+}
+
+// forgetRecycled deletes the records of the recycled objects removed from the recycle bin directly
+func forgetRecycled(storage driver.Driver, path string) {
+	if !inRecycleBin(storage, path) {
+		return
+	}
+	if err := db.DeleteRecycleItemsByPath(storage.GetStorage().ID, path); err != nil {
+		log.Errorf("failed delete recycle items of %s: %+v", path, err)
+	}
+}
+
+func getStorageById(id uint) (driver.Driver, error) {
+	for _, storage := range GetAllStorages() {
+		if storage.GetStorage().ID == id {
+			return storage, nil
+		}
+	}
+	return nil, errors.Errorf("storage %d is not loaded", id)
+}
+
+func GetRecycleItems(storageID uint, pageIndex, pageSize int) ([]model.RecycleItem, int64, error) {
+	return db.GetRecycleItems(storageID, pageIndex, pageSize)
+}
+
+// RestoreRecycleItem moves the object back to its original path, it fails if
+// the path has been taken by another object
+func RestoreRecycleItem(ctx context.Context, id uint) error {
+	item, err := db.GetRecycleItemById(id)
+	if err != nil {
+		return err
+	}
+	storage, err := getStorageById(item.StorageID)
+	if err != nil {
+		return err
+	}
+	if _, err = Get(ctx, storage, item.Path); err == nil {
+		return errors.Errorf("%s already exists", item.Path)
+	} else if !errs.IsObjectNotFound(err) {
+		return err
+	}
+	if root := trashRoot(storage, item); root == item.TrashPath {
+		// recycled in place
+		if err = Rename(ctx, storage, item.TrashPath, item.Name); err != nil {
+			return errors.WithMessage(err, "failed rename from recycle")
+		}
+	} else {
+		dstDir := stdpath.Dir(item.Path)
+		if err = MakeDir(ctx, storage, dstDir); err != nil {
+			return errors.WithMessage(err, "failed make dir")
+		}
+		if err = Move(ctx, storage, item.TrashPath, dstDir); err != nil {
+			return errors.WithMessage(err, "failed move from recycle bin")
+		}
+		// the dir of the item in the recycle bin is empty now
+		if err = RemovePermanently(ctx, storage, root); err != nil {
+			log.Warnf("failed remove %s: %+v", root, err)
+		}
+	}
+	moveRecycledProps(storage, item.TrashPath, item.Path)
+	return db.DeleteRecycleItemById(id)
+}
+
+// PurgeRecycleItem removes the object in the recycle bin permanently
+func PurgeRecycleItem(ctx context.Context, id uint) error {
+	item, err := db.GetRecycleItemById(id)
+	if err != nil {
+		return err
+	}
+	return purge(ctx, item)
+}
+
+func purge(ctx context.Context, item *model.RecycleItem) error {
+	storage, err := getStorageById(item.StorageID)
+	if err != nil {
+		return err
+	}
+	root := trashRoot(storage, item)
+	if err = RemovePermanently(ctx, storage, root); err != nil {
+		return err
+	}
+	if err = DeleteWebDAVProps(utils.GetFullPath(storage.GetStorage().MountPath, root)); err != nil {
+		log.Warnf("failed remove webdav props of %s: %+v", root, err)
+	}
+	return db.DeleteRecycleItemById(item.ID)
+}
+
+// EmptyRecycleBin purges all the objects in the recycle bin of the storage
+func EmptyRecycleBin(ctx context.Context, storageID uint) error {
+	items, err := db.GetAllRecycleItems(storageID)
+	if err != nil {
+		return err
+	}
+	var errList []error
+	for i := range items {
+		if err = purge(ctx, &items[i]); err != nil {
+			errList = append(errList, errors.WithMessagef(err, "failed purge %s", items[i].Path))
+		}
+	}
+	return utils.MergeErrors(errList...)
+}
+
+// ExpireRecycleItems purges the objects that have been in the recycle bins longer
+// than the expiration of their storages
+func ExpireRecycleItems(ctx context.Context) {
+	for _, storage := range GetAllStorages() {
+		s := storage.GetStorage()
+		if !s.EnableRecycleBin || s.RecycleBinExpiration <= 0 {
+			continue
+		}
+		items, err := db.GetExpiredRecycleItems(s.ID, time.Now().AddDate(0, 0, -s.RecycleBinExpiration))
+		if err != nil {
+			log.Errorf("failed get expired recycle items of %s: %+v", s.MountPath, err)
+			continue
+		}
+		for i := range items {
+			if err = purge(ctx, &items[i]); err != nil {
+				log.Errorf("failed purge expired recycle item %s: %+v", items[i].Path, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package op_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+func TestRecycleAndRestore(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:     "Local",
+		MountPath:  "/recycle",
+		RecycleBin: model.RecycleBin{EnableRecycleBin: true},
+		Addition:   fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/recycle")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	prop := model.WebDAVProp{Space: "ns", Local: "foo", InnerXML: "1"}
+	if err = op.PatchWebDAVProps("/recycle/a.txt", []model.WebDAVProp{prop}, nil); err != nil {
+		t.Fatalf("failed patch props: %+v", err)
+	}
+	if err = op.Remove(ctx, storage, "/a.txt"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	objs, err := op.List(ctx, storage, "/", model.ListArgs{Refresh: true})
+	if err != nil {
+		t.Fatalf("failed list: %+v", err)
+	}
+	if objs = op.HideRecycled(storage, "/", objs); len(objs) != 0 {
+		t.Errorf("the recycle bin is listed: %v", objs)
+	}
+	items, _, err := op.GetRecycleItems(storage.GetStorage().ID, 1, 10)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("GetRecycleItems = %v, %v, want 1 item", items, err)
+	}
+	if err = op.RestoreRecycleItem(ctx, items[0].ID); err != nil {
+		t.Fatalf("failed restore: %+v", err)
+	}
+	if _, err = os.Stat(filepath.Join(root, "a.txt")); err != nil {
+		t.Errorf("the restored file is missing: %v", err)
+	}
+	props, err := op.GetWebDAVProps("/recycle/a.txt")
+	if err != nil || len(props) != 1 || props[0].InnerXML != "1" {
+		t.Errorf("props of the restored file = %v, %v, want %v", props, err, prop)
+	}
+}
+
+func TestRemovePermanently(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{
+		Driver:     "Local",
+		MountPath:  "/overwrite",
+		RecycleBin: model.RecycleBin{EnableRecycleBin: true},
+		Addition:   fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/overwrite")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	// the objects being overwritten skip the recycle bin
+	if err = op.RemovePermanently(ctx, storage, "/a.txt"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	if _, err = os.Stat(filepath.Join(root, "a.txt")); !os.IsNotExist(err) {
+		t.Errorf("the removed file exists: %v", err)
+	}
+	items, _, err := op.GetRecycleItems(storage.GetStorage().ID, 1, 10)
+	if err != nil || len(items) != 0 {
+		t.Errorf("GetRecycleItems = %v, %v, want no item", items, err)
+	}
+}
@@ -0,0 +1,130 @@
+package op
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+const shareIDLength = 10
+
+// CreateShare creates a share of user, the id is generated
+func CreateShare(user *model.User, s *model.Share) error {
+	if user.IsGuest() || (!user.IsAdmin() && !user.CanShare()) {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	if err := checkShare(user, s); err != nil {
+		return err
+	}
+	s.UserID = user.ID
+	s.Views = 0
+	s.Downloads = 0
+	s.LastAccess = nil
+	s.Created = time.Now()
+	// retry in the unlikely case of a conflict
+	var err error
+	for range 3 {
+		s.ID = random.String(shareIDLength)
+		if err = db.CreateShare(s); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// UpdateShare updates a share created by user, admin can update all shares,
+// the access stats are kept, so is the password if keepPassword
+func UpdateShare(user *model.User, s *model.Share, keepPassword bool) error {
+	old, err := db.GetShareById(s.ID)
+	if err != nil {
+		return err
+	}
+	if old.UserID != user.ID && !user.IsAdmin() {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	creator, err := GetUserById(old.UserID)
+	if err != nil {
+		return err
+	}
+	if err = checkShare(creator, s); err != nil {
+		return err
+	}
+	s.UserID = old.UserID
+	s.Views = old.Views
+	s.Downloads = old.Downloads
+	s.LastAccess = old.LastAccess
+	s.Created = old.Created
+	if keepPassword {
+		s.Password = old.Password
+	}
+	return db.UpdateShare(s)
+}
+
+func checkShare(creator *model.User, s *model.Share) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if s.AllowUpload && !creator.IsAdmin() && !creator.CanWrite() {
+		return errors.New("the creator of the share has no permission to upload")
+	}
+	_, err := creator.JoinPath(s.Path)
+	return err
+}
+
+// DeleteShare deletes a share created by user, admin can delete all shares
+func DeleteShare(user *model.User, id string) error {
+	s, err := db.GetShareById(id)
+	if err != nil {
+		return err
+	}
+	if s.UserID != user.ID && !user.IsAdmin() {
+		return errors.WithStack(errs.PermissionDenied)
+	}
+	return db.DeleteShareById(id)
+}
+
+func GetSharesByUser(userID uint) ([]model.Share, error) {
+	return db.GetSharesByUserId(userID)
+}
+
+func GetShares(pageIndex, pageSize int) ([]model.Share, int64, error) {
+	return db.GetShares(pageIndex, pageSize)
+}
+
+// GetShareVisitor returns the share of id if it can be visited, and the
+// creator restricted to it, the password is not verified
+func GetShareVisitor(id string) (*model.Share, *model.User, error) {
+	s, err := db.GetShareById(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.Disabled || s.Expired() {
+		return nil, nil, errors.New("share is disabled or has expired")
+	}
+	creator, err := GetUserById(s.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if creator.Disabled {
+		return nil, nil, errors.New("the creator of share is disabled")
+	}
+	visitor, err := s.Visitor(creator)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, visitor, nil
+}
+
+func IncreaseShareViews(id string) error {
+	return db.IncreaseShareViews(id, time.Now())
+}
+
+// IncreaseShareDownloads counts a download, it returns false if the max
+// downloads of the share has been reached
+func IncreaseShareDownloads(id string) (bool, error) {
+	return db.IncreaseShareDownloads(id, time.Now())
+}
@@ -0,0 +1,60 @@
+package op_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+func TestShare(t *testing.T) {
+	user := model.User{Username: "share", BasePath: "/", Permission: 1 << 14}
+	if err := op.CreateUser(&user); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	s := model.Share{Path: "/docs", Password: "secret", MaxDownloads: 2}
+	if err := op.CreateShare(&user, &s); err != nil {
+		t.Fatalf("failed create share: %+v", err)
+	}
+
+	// the password is kept unless it's changed
+	update := s
+	update.Password = ""
+	if err := op.UpdateShare(&user, &update, true); err != nil {
+		t.Fatalf("failed update share: %+v", err)
+	}
+	if got, _ := db.GetShareById(s.ID); got.Password != "secret" {
+		t.Errorf("password = %q after updating without it, want kept", got.Password)
+	}
+	update.Password = "changed"
+	if err := op.UpdateShare(&user, &update, false); err != nil {
+		t.Fatalf("failed update share: %+v", err)
+	}
+	if got, _ := db.GetShareById(s.ID); got.Password != "changed" {
+		t.Errorf("password = %q, want changed", got.Password)
+	}
+
+	for i, want := range []bool{true, true, false} {
+		ok, err := op.IncreaseShareDownloads(s.ID)
+		if err != nil || ok != want {
+			t.Errorf("download %d = %v, %v, want %v", i+1, ok, err, want)
+		}
+	}
+	if got, _ := db.GetShareById(s.ID); got.Downloads != 2 || !got.DownloadsExceeded() {
+		t.Errorf("downloads = %d, want 2 and exceeded", got.Downloads)
+	}
+
+	if _, visitor, err := op.GetShareVisitor(s.ID); err != nil || visitor.BasePath != "/docs" {
+		t.Errorf("GetShareVisitor = %v, %v, want a visitor of /docs", visitor, err)
+	}
+	expired := time.Now().Add(-time.Minute)
+	update.Expires = &expired
+	if err := op.UpdateShare(&user, &update, true); err != nil {
+		t.Fatalf("failed update share: %+v", err)
+	}
+	if _, _, err := op.GetShareVisitor(s.ID); err == nil {
+		t.Errorf("GetShareVisitor of an expired share should fail")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/driver"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/pkg/generic_sync"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	mapset "github.com/deckarep/golang-set/v2"
@@ -103,6 +104,7 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 			driverStorage.SetStatus(errInfo)
 			MustSaveDriverStorage(storageDriver)
 			storagesMap.Store(driverStorage.MountPath, storageDriver)
+			webhook.EmitStorageStatus(*driverStorage, storage.Status)
 		}
 	}()
 	// Unmarshal Addition
@@ -141,6 +143,9 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 		driverStorage.SetStatus(WORK)
 	}
 	MustSaveDriverStorage(storageDriver)
+	if driverStorage.Status != storage.Status {
+		webhook.EmitStorageStatus(*driverStorage, storage.Status)
+	}
 	return err
 }
 
@@ -182,6 +187,7 @@ func DisableStorage(ctx context.Context, id uint) error {
 	}
 	// delete the storage in the memory
 	storage.Disabled = true
+	oldStatus := storage.Status
 	storage.SetStatus(DISABLED)
 	err = db.UpdateStorage(storage)
 	if err != nil {
@@ -189,6 +195,7 @@ func DisableStorage(ctx context.Context, id uint) error {
 	}
 	storagesMap.Delete(storage.MountPath)
 	go callStorageHooks("del", storageDriver)
+	webhook.EmitStorageStatus(*storage, oldStatus)
 	return nil
 }
 
@@ -253,6 +260,12 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 	if err := db.DeleteStorageById(id); err != nil {
 		return errors.WithMessage(err, "failed delete storage in database")
 	}
+	if err := db.DeleteRecycleItemsByStorage(id); err != nil {
+		log.Errorf("failed delete recycle items of storage %d: %+v", id, err)
+	}
+	if err := db.DeleteQuotaFilesByStorage(id); err != nil {
+		log.Errorf("failed delete quota files of storage %d: %+v", id, err)
+	}
 	return nil
 }
 
@@ -362,3 +375,16 @@ func GetBalancedStorage(path string) driver.Driver {
 		return storages[i]
 	}
 }
+
+// GetStorageDetails returns the space of the storage if its driver reports it
+func GetStorageDetails(ctx context.Context, storage driver.Driver) (*model.StorageDetails, error) {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	s, ok := storage.(driver.WithDetails)
+	if !ok {
+		return nil, errs.NotImplement
+	}
+	details, err := s.GetDetails(ctx)
+	return details, errors.WithStack(err)
+}
@@ -0,0 +1,71 @@
+package op
+
+import (
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// the groups are cached since their limits are checked on every transfer
+var (
+	groupMu     sync.RWMutex
+	groupCache  map[uint]model.Group
+	groupLoaded bool
+)
+
+func getGroupMap() (map[uint]model.Group, error) {
+	groupMu.RLock()
+	if groupLoaded {
+		defer groupMu.RUnlock()
+		return groupCache, nil
+	}
+	groupMu.RUnlock()
+	groupMu.Lock()
+	defer groupMu.Unlock()
+	if !groupLoaded {
+		groups, err := db.GetGroups()
+		if err != nil {
+			return nil, err
+		}
+		groupCache = make(map[uint]model.Group, len(groups))
+		for _, g := range groups {
+			groupCache[g.ID] = g
+		}
+		groupLoaded = true
+	}
+	return groupCache, nil
+}
+
+func clearGroupCache() {
+	groupMu.Lock()
+	defer groupMu.Unlock()
+	groupCache = nil
+	groupLoaded = false
+}
+
+// GetTrafficLimit returns the effective traffic limit of user, which is the
+// stricter one of the limits of the user and its groups
+func GetTrafficLimit(user *model.User) model.TrafficLimit {
+	limit := user.TrafficLimit
+	if len(user.GroupIDs) == 0 {
+		return limit
+	}
+	groups, err := getGroupMap()
+	if err != nil {
+		log.Errorf("failed get groups: %+v", err)
+		return limit
+	}
+	for _, id := range user.GroupIDs {
+		if g, ok := groups[id]; ok {
+			limit = limit.Merge(g.TrafficLimit)
+		}
+	}
+	return limit
+}
+
+// GetTraffics returns the traffic of the day and the month of date, of all users if userID is 0
+func GetTraffics(userID uint, day, month string) ([]model.Traffic, error) {
+	return db.GetTraffics(userID, day, month)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/db"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/session"
 	"github.com/OpenListTeam/OpenList/v4/pkg/singleflight"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/go-cache"
@@ -70,6 +71,12 @@ func GetUsers(pageIndex, pageSize int) (users []model.User, count int64, err err
 
 func CreateUser(u *model.User) error {
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
+	if err := u.ValidateBasePaths(); err != nil {
+		return err
+	}
+	if err := u.ValidateFTPAllowedIPs(); err != nil {
+		return err
+	}
 	return db.CreateUser(u)
 }
 
@@ -82,6 +89,25 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
+	if err = db.DeleteAPITokensByUserId(id); err != nil {
+		return err
+	}
+	if err = db.DeleteSharesByUserId(id); err != nil {
+		return err
+	}
+	if err = db.DeleteACLRulesByUserId(id); err != nil {
+		return err
+	}
+	clearACLCache()
+	if err = db.DeleteQuotaByUserId(id); err != nil {
+		return err
+	}
+	if err = db.DeleteTrafficsByUserId(id); err != nil {
+		return err
+	}
+	if err = session.DeleteByUser(id); err != nil {
+		return err
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -96,8 +122,14 @@ func UpdateUser(u *model.User) error {
 	if u.IsGuest() {
 		guestUser = nil
 	}
-	userCache.Del(old.Username)
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
+	if err = u.ValidateBasePaths(); err != nil {
+		return err
+	}
+	if err = u.ValidateFTPAllowedIPs(); err != nil {
+		return err
+	}
+	userCache.Del(old.Username)
 	return db.UpdateUser(u)
 }
 
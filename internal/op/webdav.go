@@ -0,0 +1,34 @@
+package op
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+// the param named path of the functions below is a mount path,
+// since the WebDAV props don't belong to any storage
+
+func GetWebDAVProps(path string) ([]model.WebDAVProp, error) {
+	return db.GetWebDAVPropsByPath(utils.FixAndCleanPath(path))
+}
+
+func GetWebDAVPropsByParent(parent string) ([]model.WebDAVProp, error) {
+	return db.GetWebDAVPropsByParent(utils.FixAndCleanPath(parent))
+}
+
+func PatchWebDAVProps(path string, set []model.WebDAVProp, remove []model.WebDAVProp) error {
+	return db.PatchWebDAVProps(utils.FixAndCleanPath(path), set, remove)
+}
+
+func DeleteWebDAVProps(path string) error {
+	return db.DeleteWebDAVProps(utils.FixAndCleanPath(path))
+}
+
+func CopyWebDAVProps(srcPath, dstPath string) error {
+	return db.CopyWebDAVProps(utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath), false)
+}
+
+func MoveWebDAVProps(srcPath, dstPath string) error {
+	return db.CopyWebDAVProps(utils.FixAndCleanPath(srcPath), utils.FixAndCleanPath(dstPath), true)
+}
@@ -0,0 +1,44 @@
+package op
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
+)
+
+func GetWebhooks() ([]model.Webhook, error) {
+	return db.GetWebhooks()
+}
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	return db.GetWebhookById(id)
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	w.ID = 0
+	defer webhook.Reload()
+	return db.CreateWebhook(w)
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.GetWebhookById(w.ID); err != nil {
+		return err
+	}
+	defer webhook.Reload()
+	return db.UpdateWebhook(w)
+}
+
+func DeleteWebhookById(id uint) error {
+	defer webhook.Reload()
+	return db.DeleteWebhookById(id)
+}
+
+func GetWebhookDeliveries(webhookID uint, pageIndex, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	return db.GetWebhookDeliveries(webhookID, pageIndex, pageSize)
+}
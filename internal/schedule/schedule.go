@@ -0,0 +1,200 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	timersMu sync.Mutex
+	// the cancel funcs of the timers of the enabled schedules
+	timers = make(map[uint]context.CancelFunc)
+)
+
+func validate(s *model.Schedule) error {
+	if _, err := cron.Parse(s.Cron); err != nil {
+		return err
+	}
+	fixPath := func(p string) string {
+		if p == "" {
+			return ""
+		}
+		return utils.FixAndCleanPath(p)
+	}
+	s.SrcPath, s.DstPath = fixPath(s.SrcPath), fixPath(s.DstPath)
+	switch s.Type {
+	case model.ScheduleCopy, model.ScheduleMove:
+		if s.SrcPath == "" || s.DstPath == "" {
+			return errors.New("src path and dst path are required")
+		}
+	case model.ScheduleOfflineDownload:
+		if s.URL == "" || s.DstPath == "" || s.Tool == "" {
+			return errors.New("url, dst path and tool are required")
+		}
+		if _, err := tool.Tools.Get(s.Tool); err != nil {
+			return err
+		}
+	case model.ScheduleIndexUpdate, model.ScheduleStorageReload:
+		if s.SrcPath == "" {
+			return errors.New("src path is required")
+		}
+	case model.ScheduleSync:
+		if _, err := db.GetSyncJobById(s.SyncJobID); err != nil {
+			return errors.WithMessage(err, "failed get sync job")
+		}
+	default:
+		return errors.Errorf("invalid schedule type: %s", s.Type)
+	}
+	return nil
+}
+
+func Create(s *model.Schedule) error {
+	s.ID = 0
+	if err := validate(s); err != nil {
+		return err
+	}
+	if err := db.CreateSchedule(s); err != nil {
+		return err
+	}
+	start(*s)
+	return nil
+}
+
+func Update(s *model.Schedule) error {
+	old, err := db.GetScheduleById(s.ID)
+	if err != nil {
+		return err
+	}
+	if err = validate(s); err != nil {
+		return err
+	}
+	s.LastRun, s.LastResult = old.LastRun, old.LastResult
+	if err = db.UpdateSchedule(s); err != nil {
+		return err
+	}
+	start(*s)
+	return nil
+}
+
+func Delete(id uint) error {
+	if err := db.DeleteScheduleById(id); err != nil {
+		return err
+	}
+	stop(id)
+	return nil
+}
+
+func Get(id uint) (*model.Schedule, error) {
+	return db.GetScheduleById(id)
+}
+
+func GetAll(pageIndex, pageSize int) ([]model.Schedule, int64, error) {
+	return db.GetSchedules(pageIndex, pageSize)
+}
+
+// Run runs the schedule now, regardless of its cron expression
+func Run(ctx context.Context, id uint) (task.TaskExtensionInfo, error) {
+	s, err := db.GetScheduleById(id)
+	if err != nil {
+		return nil, err
+	}
+	creator, _ := ctx.Value(conf.UserKey).(*model.User)
+	return run(*s, creator, common.GetApiUrl(ctx)), nil
+}
+
+func run(s model.Schedule, creator *model.User, apiUrl string) *RunTask {
+	t := &RunTask{
+		TaskExtension: task.TaskExtension{
+			Creator: creator,
+			ApiUrl:  apiUrl,
+		},
+		Schedule: s,
+	}
+	RunTaskManager.Add(t)
+	return t
+}
+
+func saveResult(id uint, result string) {
+	s, err := db.GetScheduleById(id)
+	if err != nil {
+		// the schedule has been deleted
+		return
+	}
+	now := time.Now()
+	s.LastRun, s.LastResult = &now, result
+	if err = db.UpdateSchedule(s); err != nil {
+		utils.Log.Errorf("failed save the result of schedule %d: %+v", id, err)
+	}
+}
+
+// start (re)starts the timer of s, the scheduled runs are created by the admin
+func start(s model.Schedule) {
+	stop(s.ID)
+	if s.Disabled {
+		return
+	}
+	expr, err := cron.Parse(s.Cron)
+	if err != nil {
+		utils.Log.Errorf("failed parse the cron of schedule %d: %+v", s.ID, err)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	timersMu.Lock()
+	timers[s.ID] = cancel
+	timersMu.Unlock()
+	go func() {
+		for {
+			next := expr.Next(time.Now())
+			if next.IsZero() {
+				utils.Log.Warnf("schedule %d will never run", s.ID)
+				return
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			admin, err := op.GetAdmin()
+			if err != nil {
+				utils.Log.Errorf("failed get admin for schedule %d: %+v", s.ID, err)
+				continue
+			}
+			run(s, admin, common.GetApiUrlFromRequest(nil))
+		}
+	}()
+}
+
+func stop(id uint) {
+	timersMu.Lock()
+	defer timersMu.Unlock()
+	if cancel, ok := timers[id]; ok {
+		cancel()
+		delete(timers, id)
+	}
+}
+
+// Init starts the timers of the enabled schedules, it should be called after the task managers are initialized
+func Init() {
+	schedules, err := db.GetEnabledSchedules()
+	if err != nil {
+		utils.Log.Errorf("failed load schedules: %+v", err)
+		return
+	}
+	for _, s := range schedules {
+		start(s)
+	}
+}
@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/search"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/syncjob"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/tache"
+	"github.com/pkg/errors"
+)
+
+// RunTask is a run of a schedule, the tasks of the operations it starts
+// (copy, move, offline download and sync) are listed in their own managers
+type RunTask struct {
+	task.TaskExtension
+	Schedule model.Schedule `json:"schedule"`
+	// the id of the task started by the run
+	TaskID string `json:"task_id"`
+	status string
+}
+
+func (t *RunTask) GetName() string {
+	name := t.Schedule.Name
+	if name == "" {
+		name = fmt.Sprintf("schedule %d", t.Schedule.ID)
+	}
+	return fmt.Sprintf("%s (%s)", name, t.Schedule.Type)
+}
+
+func (t *RunTask) GetStatus() string {
+	return t.status
+}
+
+func (t *RunTask) Run() error {
+	if err := t.ReinitCtx(); err != nil {
+		return err
+	}
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	err := t.run()
+	result := t.status
+	if err != nil {
+		result = err.Error()
+	}
+	saveResult(t.Schedule.ID, result)
+	return err
+}
+
+func (t *RunTask) run() error {
+	s := t.Schedule
+	var (
+		tsk task.TaskExtensionInfo
+		err error
+	)
+	switch s.Type {
+	case model.ScheduleCopy:
+		tsk, err = fs.Copy(t.Ctx(), s.SrcPath, s.DstPath)
+	case model.ScheduleMove:
+		tsk, err = fs.MoveWithTask(t.Ctx(), s.SrcPath, s.DstPath)
+	case model.ScheduleOfflineDownload:
+		tsk, err = tool.AddURL(t.Ctx(), &tool.AddURLArgs{
+			URL:          s.URL,
+			DstDirPath:   s.DstPath,
+			Tool:         s.Tool,
+			DeletePolicy: tool.DeletePolicy(s.DeletePolicy),
+		})
+	case model.ScheduleSync:
+		tsk, err = syncjob.Run(t.Ctx(), s.SyncJobID, false)
+	case model.ScheduleIndexUpdate:
+		err = t.updateIndex()
+	case model.ScheduleStorageReload:
+		err = t.reloadStorage()
+	default:
+		err = errors.Errorf("unknown schedule type: %s", s.Type)
+	}
+	if err != nil {
+		return err
+	}
+	if tsk != nil {
+		t.TaskID = tsk.GetID()
+		t.status = fmt.Sprintf("task %s added", t.TaskID)
+	} else {
+		t.status = "done"
+	}
+	t.SetProgress(100)
+	return nil
+}
+
+func (t *RunTask) updateIndex() error {
+	if search.Running() {
+		return errors.New("index is running")
+	}
+	if !search.Config(t.Ctx()).AutoUpdate {
+		return errors.New("update is not supported for current index")
+	}
+	t.status = "updating index"
+	if err := search.Del(t.Ctx(), t.Schedule.SrcPath); err != nil {
+		return err
+	}
+	return search.BuildIndex(t.Ctx(), []string{t.Schedule.SrcPath},
+		conf.SlicesMap[conf.IgnorePaths], setting.GetInt(conf.MaxIndexDepth, 20), false)
+}
+
+func (t *RunTask) reloadStorage() error {
+	storageDriver, err := op.GetStorageByMountPath(t.Schedule.SrcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage driver")
+	}
+	storage := *storageDriver.GetStorage()
+	t.status = "reloading storage"
+	if err = storageDriver.Drop(t.Ctx()); err != nil {
+		return errors.WithMessage(err, "failed drop storage")
+	}
+	return op.LoadStorage(t.Ctx(), storage)
+}
+
+var RunTaskManager *tache.Manager[*RunTask]
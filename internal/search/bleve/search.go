@@ -3,6 +3,8 @@ package bleve
 import (
 	"context"
 	"os"
+	"strings"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
@@ -25,19 +27,47 @@ func (b *Bleve) Config() searcher.Config {
 	return config
 }
 
+// document is what's indexed for a SearchNode, the lowercase
+// extension is kept for filtering by extensions
+type document struct {
+	model.SearchNode
+	Ext string `json:"ext"`
+}
+
+func (d *document) Type() string {
+	return "SearchNode"
+}
+
+func newDocument(node model.SearchNode) *document {
+	d := &document{SearchNode: node}
+	if !node.IsDir {
+		d.Ext = strings.ToLower(utils.Ext(node.Name))
+	}
+	return d
+}
+
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	var queries []query2.Query
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	queries = append(queries, query)
+	if req.Keywords == "" {
+		queries = append(queries, bleve.NewMatchAllQuery())
+	} else {
+		query := bleve.NewMatchQuery(req.Keywords)
+		query.SetField("name")
+		queries = append(queries, query)
+	}
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
 		isDirQuery := bleve.NewBoolFieldQuery(isDir)
 		queries = append(queries, isDirQuery)
 	}
+	queries = append(queries, filterQueries(req)...)
 	reqQuery := bleve.NewConjunctionQuery(queries...)
 	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	orderBy, desc := req.Order()
+	if desc {
+		orderBy = "-" + orderBy
+	}
+	search.SortBy([]string{orderBy})
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
 	search.Fields = []string{"*"}
@@ -47,24 +77,88 @@ func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.Search
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
+		node := model.SearchNode{
 			Parent: src.Fields["parent"].(string),
 			Name:   src.Fields["name"].(string),
 			IsDir:  src.Fields["is_dir"].(bool),
 			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		}
+		// the nodes indexed by the old versions don't have the fields below
+		if modified, ok := src.Fields["modified"].(string); ok {
+			node.Modified, _ = time.Parse(time.RFC3339, modified)
+		}
+		if hash, ok := src.Fields["hash"].(string); ok {
+			node.Hash = hash
+		}
+		if typ, ok := src.Fields["type"].(float64); ok {
+			node.Type = int(typ)
+		}
+		return node, nil
 	})
 	return res, int64(searchResults.Total), nil
 }
 
+func filterQueries(req model.SearchReq) []query2.Query {
+	var queries []query2.Query
+	if req.MinSize > 0 || req.MaxSize > 0 {
+		var min, max *float64
+		if req.MinSize > 0 {
+			v := float64(req.MinSize)
+			min = &v
+		}
+		if req.MaxSize > 0 {
+			v := float64(req.MaxSize)
+			max = &v
+		}
+		inclusive := true
+		q := bleve.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+		q.SetField("size")
+		queries = append(queries, q)
+	}
+	if req.ModifiedAfter != nil || req.ModifiedBefore != nil {
+		var start, end time.Time
+		if req.ModifiedAfter != nil {
+			start = *req.ModifiedAfter
+		}
+		if req.ModifiedBefore != nil {
+			end = *req.ModifiedBefore
+		}
+		inclusive := true
+		q := bleve.NewDateRangeInclusiveQuery(start, end, &inclusive, &inclusive)
+		q.SetField("modified")
+		queries = append(queries, q)
+	}
+	if len(req.Exts) > 0 {
+		extQueries := make([]query2.Query, 0, len(req.Exts))
+		for _, ext := range req.Exts {
+			q := bleve.NewTermQuery(ext)
+			q.SetField("ext")
+			extQueries = append(extQueries, q)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(extQueries...))
+	}
+	if len(req.Types) > 0 {
+		typeQueries := make([]query2.Query, 0, len(req.Types))
+		for _, typ := range req.Types {
+			v := float64(typ)
+			inclusive := true
+			q := bleve.NewNumericRangeInclusiveQuery(&v, &v, &inclusive, &inclusive)
+			q.SetField("type")
+			typeQueries = append(typeQueries, q)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(typeQueries...))
+	}
+	return queries
+}
+
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
-	return b.BIndex.Index(uuid.NewString(), node)
+	return b.BIndex.Index(uuid.NewString(), newDocument(node))
 }
 
 func (b *Bleve) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	batch := b.BIndex.NewBatch()
 	for _, node := range nodes {
-		batch.Index(uuid.NewString(), node)
+		batch.Index(uuid.NewString(), newDocument(node))
 	}
 	return b.BIndex.Batch(batch)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,6 +18,82 @@ import (
 type searchDocument struct {
 	ID string `json:"id"`
 	model.SearchNode
+	// meilisearch can only filter and sort by numbers, and can't match suffixes
+	ModifiedUnix int64  `json:"modified_unix"`
+	Ext          string `json:"ext"`
+}
+
+func newSearchDocument(node model.SearchNode) *searchDocument {
+	d := &searchDocument{
+		ID:           uuid.NewString(),
+		SearchNode:   node,
+		ModifiedUnix: node.Modified.Unix(),
+	}
+	if !node.IsDir {
+		d.Ext = strings.ToLower(utils.Ext(node.Name))
+	}
+	return d
+}
+
+// quote quotes s as a string of the filter expressions, the backslashes are
+// escaped before the quotes so that the escapes of the quotes are kept
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+func docToSearchNode(src map[string]any) model.SearchNode {
+	node := model.SearchNode{
+		Parent: src["parent"].(string),
+		Name:   src["name"].(string),
+		IsDir:  src["is_dir"].(bool),
+		Size:   int64(src["size"].(float64)),
+	}
+	// the documents indexed by the old versions don't have the fields below
+	if modified, ok := src["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	if hash, ok := src["hash"].(string); ok {
+		node.Hash = hash
+	}
+	if typ, ok := src["type"].(float64); ok {
+		node.Type = int(typ)
+	}
+	return node
+}
+
+func filters(req model.SearchReq) []string {
+	var filter []string
+	if req.Scope != 0 {
+		filter = append(filter, fmt.Sprintf("is_dir = %v", req.Scope == 1))
+	}
+	if req.MinSize > 0 {
+		filter = append(filter, fmt.Sprintf("size >= %d", req.MinSize))
+	}
+	if req.MaxSize > 0 {
+		filter = append(filter, fmt.Sprintf("size <= %d", req.MaxSize))
+	}
+	if req.ModifiedAfter != nil {
+		filter = append(filter, fmt.Sprintf("modified_unix >= %d", req.ModifiedAfter.Unix()))
+	}
+	if req.ModifiedBefore != nil {
+		filter = append(filter, fmt.Sprintf("modified_unix <= %d", req.ModifiedBefore.Unix()))
+	}
+	if len(req.Exts) > 0 {
+		exts := make([]string, 0, len(req.Exts))
+		for _, ext := range req.Exts {
+			exts = append(exts, quote(ext))
+		}
+		filter = append(filter, fmt.Sprintf("ext IN [%s]", strings.Join(exts, ",")))
+	}
+	if len(req.Types) > 0 {
+		types := make([]string, 0, len(req.Types))
+		for _, typ := range req.Types {
+			types = append(types, strconv.Itoa(typ))
+		}
+		filter = append(filter, fmt.Sprintf("type IN [%s]", strings.Join(types, ",")))
+	}
+	return filter
 }
 
 type Meilisearch struct {
@@ -24,6 +101,7 @@ type Meilisearch struct {
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 }
 
 func (m *Meilisearch) Config() searcher.Config {
@@ -36,21 +114,27 @@ func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.
 		Page:                 int64(req.Page),
 		HitsPerPage:          int64(req.PerPage),
 	}
-	if req.Scope != 0 {
-		mReq.Filter = fmt.Sprintf("is_dir = %v", req.Scope == 1)
+	if filter := filters(req); len(filter) > 0 {
+		mReq.Filter = strings.Join(filter, " AND ")
+	}
+	// sorted by relevance unless an order is specified
+	if req.OrderBy != "" {
+		orderBy, desc := req.Order()
+		if orderBy == "modified" {
+			orderBy = "modified_unix"
+		}
+		if desc {
+			mReq.Sort = []string{orderBy + ":desc"}
+		} else {
+			mReq.Sort = []string{orderBy + ":asc"}
+		}
 	}
 	search, err := m.Client.Index(m.IndexUid).Search(req.Keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
-		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		return docToSearchNode(src.(map[string]any)), nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -64,11 +148,7 @@ func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 
 func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	documents, _ := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
-
-		return &searchDocument{
-			ID:         uuid.NewString(),
-			SearchNode: src,
-		}, nil
+		return newSearchDocument(src), nil
 	})
 
 	_, err := m.Client.Index(m.IndexUid).AddDocuments(documents)
@@ -101,13 +181,8 @@ func (m *Meilisearch) getDocumentsByParent(ctx context.Context, parent string) (
 	}
 	return utils.SliceConvert(result.Results, func(src map[string]any) (*searchDocument, error) {
 		return &searchDocument{
-			ID: src["id"].(string),
-			SearchNode: model.SearchNode{
-				Parent: src["parent"].(string),
-				Name:   src["name"].(string),
-				IsDir:  src["is_dir"].(bool),
-				Size:   int64(src["size"].(float64)),
-			},
+			ID:         src["id"].(string),
+			SearchNode: docToSearchNode(src),
 		}, nil
 	})
 }
@@ -0,0 +1,68 @@
+package session
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/go-cache"
+)
+
+// dbCacheExpiration is how long the sessions got from the database are cached,
+// since they are checked by every authorized request. A session deleted by
+// another instance is still valid in this instance until its cache expires
+const dbCacheExpiration = 30 * time.Second
+
+// dbStore keeps the sessions in the database, so they survive restarts and
+// are shared by the instances using the same database
+type dbStore struct {
+	cache cache.ICache[*model.Session]
+}
+
+func newDBStore() *dbStore {
+	return &dbStore{cache: cache.NewMemCache(cache.WithShards[*model.Session](16))}
+}
+
+func (d *dbStore) Create(s *model.Session) error {
+	return db.CreateSession(s)
+}
+
+func (d *dbStore) Get(id string) (*model.Session, error) {
+	s, ok := d.cache.Get(id)
+	if !ok {
+		var err error
+		if s, err = db.GetSessionById(id); err != nil {
+			return nil, err
+		}
+		d.cache.Set(id, s, cache.WithEx[*model.Session](dbCacheExpiration))
+	}
+	if s.Expired() {
+		return nil, errNotFound
+	}
+	res := *s
+	return &res, nil
+}
+
+func (d *dbStore) ListByUser(userID uint) ([]model.Session, error) {
+	return db.GetSessionsByUserId(userID)
+}
+
+// the sessions are deleted from the cache after the database, so that they
+// aren't cached again by a Get in between
+
+func (d *dbStore) Delete(id string) error {
+	err := db.DeleteSessionById(id)
+	d.cache.Del(id)
+	return err
+}
+
+func (d *dbStore) DeleteByUser(userID uint) error {
+	err := db.DeleteSessionsByUserId(userID)
+	// the cache isn't indexed by users
+	d.cache.Clear()
+	return err
+}
+
+func (d *dbStore) DeleteExpired() error {
+	return db.DeleteExpiredSessions(time.Now())
+}
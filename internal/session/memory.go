@@ -0,0 +1,77 @@
+package session
+
+import (
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+)
+
+// memoryStore keeps the sessions until restart, it can't be shared by multiple instances
+type memoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]model.Session
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{sessions: make(map[string]model.Session)}
+}
+
+func (m *memoryStore) Create(s *model.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.ID] = *s
+	return nil
+}
+
+func (m *memoryStore) Get(id string) (*model.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[id]
+	if !ok || s.Expired() {
+		return nil, errNotFound
+	}
+	return &s, nil
+}
+
+func (m *memoryStore) ListByUser(userID uint) ([]model.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []model.Session
+	for _, s := range m.sessions {
+		if s.UserID == userID && !s.Expired() {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memoryStore) DeleteByUser(userID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, s := range m.sessions {
+		if s.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) DeleteExpired() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for id, s := range m.sessions {
+		if now.After(s.Expires) {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Store keeps the valid login tokens, a token is valid only if its session is in the store
+type Store interface {
+	Create(s *model.Session) error
+	// Get returns an error if the session doesn't exist or has expired
+	Get(id string) (*model.Session, error)
+	ListByUser(userID uint) ([]model.Session, error)
+	Delete(id string) error
+	DeleteByUser(userID uint) error
+	DeleteExpired() error
+}
+
+var (
+	store       Store = newMemoryStore()
+	expiredCron *cron.Cron
+)
+
+// Init selects the store by kind, "memory" or "database"
+func Init(kind string) {
+	switch kind {
+	case "database":
+		store = newDBStore()
+	case "", "memory":
+		store = newMemoryStore()
+	default:
+		utils.Log.Warnf("unknown session store [%s], use memory instead", kind)
+		store = newMemoryStore()
+	}
+	if expiredCron != nil {
+		expiredCron.Stop()
+	}
+	expiredCron = cron.NewCron(time.Hour)
+	expiredCron.Do(func() {
+		if err := store.DeleteExpired(); err != nil {
+			utils.Log.Errorf("failed delete expired sessions: %+v", err)
+		}
+	})
+}
+
+// ID is the id of the session of token, the token itself is not kept
+func ID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func Create(token string, s *model.Session) error {
+	s.ID = ID(token)
+	return store.Create(s)
+}
+
+// IsValid reports whether the session of token is in the store and not expired
+func IsValid(token string) bool {
+	_, err := store.Get(ID(token))
+	return err == nil
+}
+
+func Get(id string) (*model.Session, error) {
+	return store.Get(id)
+}
+
+func ListByUser(userID uint) ([]model.Session, error) {
+	return store.ListByUser(userID)
+}
+
+func Delete(id string) error {
+	return store.Delete(id)
+}
+
+// DeleteByToken deletes the session of token, e.g. on logout
+func DeleteByToken(token string) error {
+	return store.Delete(ID(token))
+}
+
+func DeleteByUser(userID uint) error {
+	return store.DeleteByUser(userID)
+}
+
+var errNotFound = errors.New("session not found")
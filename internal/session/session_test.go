@@ -0,0 +1,80 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func initTestDB(t *testing.T) {
+	dB, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	// every connection of an in-memory sqlite has its own database
+	sqlDB, _ := dB.DB()
+	sqlDB.SetMaxOpenConns(1)
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestStores(t *testing.T) {
+	initTestDB(t)
+	for name, s := range map[string]Store{"memory": newMemoryStore(), "database": newDBStore()} {
+		t.Run(name, func(t *testing.T) {
+			store = s
+			expires := time.Now().Add(time.Hour)
+			for _, token := range []string{"a", "b"} {
+				if err := Create(name+token, &model.Session{UserID: 1, Expires: expires}); err != nil {
+					t.Fatalf("failed create session: %+v", err)
+				}
+			}
+			if err := Create(name+"c", &model.Session{UserID: 2, Expires: time.Now().Add(-time.Second)}); err != nil {
+				t.Fatalf("failed create session: %+v", err)
+			}
+			if !IsValid(name+"a") || !IsValid(name+"b") {
+				t.Errorf("the sessions created are invalid")
+			}
+			if IsValid(name+"c") || IsValid(name+"d") {
+				t.Errorf("an expired or unknown session is valid")
+			}
+			if sessions, err := ListByUser(1); err != nil || len(sessions) != 2 {
+				t.Errorf("ListByUser = %v, %v, want 2 sessions", sessions, err)
+			}
+			if err := DeleteByToken(name + "a"); err != nil || IsValid(name+"a") {
+				t.Errorf("the session is valid after logout: %v", err)
+			}
+			if err := DeleteByUser(1); err != nil || IsValid(name+"b") {
+				t.Errorf("the session is valid after deleting its user: %v", err)
+			}
+		})
+	}
+}
+
+func TestDBStoreCache(t *testing.T) {
+	initTestDB(t)
+	s := newDBStore()
+	store = s
+	if err := Create("a", &model.Session{UserID: 1, Expires: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("failed create session: %+v", err)
+	}
+	if !IsValid("a") {
+		t.Fatalf("the session created is invalid")
+	}
+	// deleted by another instance, the session is valid here until the cache expires
+	if err := db.DeleteSessionById(ID("a")); err != nil {
+		t.Fatalf("failed delete session: %+v", err)
+	}
+	if !IsValid("a") {
+		t.Errorf("the cached session is invalid")
+	}
+	s.cache.Clear()
+	if IsValid("a") {
+		t.Errorf("the deleted session is valid after the cache expires")
+	}
+}
@@ -1,6 +1,8 @@
 package sign
 
 import (
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,6 +23,12 @@ func SignArchive(data string) string {
 	}
 }
 
+// SignArchiveBy signs data for the user of userID like SignBy
+func SignArchiveBy(data string, userID uint) string {
+	signer := strconv.FormatUint(uint64(userID), 10)
+	return signer + signerSep + SignArchive(signerData(data, signer))
+}
+
 func WithDurationArchive(data string, d time.Duration) string {
 	onceArchive.Do(InstanceArchive)
 	return instanceArchive.Sign(data, time.Now().Add(d).Unix())
@@ -33,6 +41,9 @@ func NotExpiredArchive(data string) string {
 
 func VerifyArchive(data string, sign string) error {
 	onceArchive.Do(InstanceArchive)
+	if signer, s, ok := strings.Cut(sign, signerSep); ok {
+		return instanceArchive.Verify(signerData(data, signer), s)
+	}
 	return instanceArchive.Verify(data, sign)
 }
 
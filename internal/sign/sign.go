@@ -1,6 +1,8 @@
 package sign
 
 import (
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,6 +14,10 @@ import (
 var once sync.Once
 var instance sign.Sign
 
+// signerSep separates the id of the user who made a sign from the sign, it's
+// not in the alphabet of the signs
+const signerSep = "."
+
 func Sign(data string) string {
 	expire := setting.GetInt(conf.LinkExpiration, 0)
 	if expire == 0 {
@@ -21,6 +27,14 @@ func Sign(data string) string {
 	}
 }
 
+// SignBy signs data for the user of userID, the requests by the sign are
+// counted to the user. The links sent to other programs, e.g. the down proxy,
+// should be signed by Sign, which they can verify
+func SignBy(data string, userID uint) string {
+	signer := strconv.FormatUint(uint64(userID), 10)
+	return signer + signerSep + Sign(signerData(data, signer))
+}
+
 func WithDuration(data string, d time.Duration) string {
 	once.Do(Instance)
 	return instance.Sign(data, time.Now().Add(d).Unix())
@@ -33,9 +47,32 @@ func NotExpired(data string) string {
 
 func Verify(data string, sign string) error {
 	once.Do(Instance)
+	if signer, s, ok := strings.Cut(sign, signerSep); ok {
+		return instance.Verify(signerData(data, signer), s)
+	}
 	return instance.Verify(data, sign)
 }
 
 func Instance() {
 	instance = sign.NewHMACSign([]byte(setting.GetStr(conf.Token)))
 }
+
+// Signer returns the id of the user who made the verified sign, it returns 0
+// if the sign is made by Sign
+func Signer(sign string) uint {
+	signer, _, ok := strings.Cut(sign, signerSep)
+	if !ok {
+		return 0
+	}
+	id, err := strconv.ParseUint(signer, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
+// signerData binds data to the signer, the paths never contain "\x00" so the
+// sign of a path can't be taken as the sign of another path by a user
+func signerData(data, signer string) string {
+	return data + "\x00" + signer
+}
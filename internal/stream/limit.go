@@ -35,6 +35,31 @@ var (
 	ServerUploadLimit   Limiter
 )
 
+// BlockBurstLimiter waits for more than the burst in multiple rounds
+// instead of failing
+type BlockBurstLimiter struct {
+	*rate.Limiter
+}
+
+func NewLimiter(limit rate.Limit, burst int) Limiter {
+	return BlockBurstLimiter{Limiter: rate.NewLimiter(limit, burst)}
+}
+
+func (l BlockBurstLimiter) WaitN(ctx context.Context, total int) error {
+	for total > 0 {
+		n := l.Burst()
+		if l.Limiter.Limit() == rate.Inf || n > total {
+			n = total
+		}
+		err := l.Limiter.WaitN(ctx, n)
+		if err != nil {
+			return err
+		}
+		total -= n
+	}
+	return nil
+}
+
 type RateLimitReader struct {
 	io.Reader
 	Limiter Limiter
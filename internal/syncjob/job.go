@@ -0,0 +1,143 @@
+package syncjob
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/OpenListTeam/tache"
+	"github.com/pkg/errors"
+)
+
+// runMu makes checking the running tasks of a job and adding one atomic
+var runMu sync.Mutex
+
+func validate(job *model.SyncJob) error {
+	job.SrcPath = utils.FixAndCleanPath(job.SrcPath)
+	job.DstPath = utils.FixAndCleanPath(job.DstPath)
+	if utils.IsSubPath(job.SrcPath, job.DstPath) || utils.IsSubPath(job.DstPath, job.SrcPath) {
+		return errors.New("src path and dst path can't contain each other")
+	}
+	switch job.Mode {
+	case "":
+		job.Mode = model.SyncOneWay
+	case model.SyncOneWay:
+	case model.SyncTwoWay:
+		job.DeleteExtraneous = false
+	default:
+		return errors.Errorf("invalid mode: %s", job.Mode)
+	}
+	return nil
+}
+
+func CreateJob(job *model.SyncJob) error {
+	job.ID = 0
+	if err := validate(job); err != nil {
+		return err
+	}
+	if err := db.CreateSyncJob(job); err != nil {
+		return err
+	}
+	return nil
+}
+
+func UpdateJob(job *model.SyncJob) error {
+	old, err := db.GetSyncJobById(job.ID)
+	if err != nil {
+		return err
+	}
+	if err = validate(job); err != nil {
+		return err
+	}
+	// the result of the last run is not editable
+	job.LastRun, job.LastResult = old.LastRun, old.LastResult
+	if err = db.UpdateSyncJob(job); err != nil {
+		return err
+	}
+	// the entries of the last sync are of the old paths
+	if job.SrcPath != old.SrcPath || job.DstPath != old.DstPath || job.Mode != old.Mode {
+		if err = db.DeleteSyncEntries(job.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DeleteJob(id uint) error {
+	if err := db.DeleteSyncJobById(id); err != nil {
+		return err
+	}
+	if err := db.DeleteSyncEntries(id); err != nil {
+		utils.Log.Errorf("failed delete the entries of sync job %d: %+v", id, err)
+	}
+	return nil
+}
+
+func GetJob(id uint) (*model.SyncJob, error) {
+	return db.GetSyncJobById(id)
+}
+
+func GetJobs(pageIndex, pageSize int) ([]model.SyncJob, int64, error) {
+	return db.GetSyncJobs(pageIndex, pageSize)
+}
+
+// Run adds a task to sync the job, a dry run only reports the changes
+func Run(ctx context.Context, id uint, dryRun bool) (task.TaskExtensionInfo, error) {
+	job, err := db.GetSyncJobById(id)
+	if err != nil {
+		return nil, err
+	}
+	creator, _ := ctx.Value(conf.UserKey).(*model.User)
+	return run(*job, dryRun, creator, common.GetApiUrl(ctx))
+}
+
+func run(job model.SyncJob, dryRun bool, creator *model.User, apiUrl string) (*SyncTask, error) {
+	if !dryRun {
+		runMu.Lock()
+		defer runMu.Unlock()
+		if running(job.ID) {
+			return nil, errors.Errorf("sync job %d is already running", job.ID)
+		}
+	}
+	t := &SyncTask{
+		TaskExtension: task.TaskExtension{
+			Creator: creator,
+			ApiUrl:  apiUrl,
+		},
+		Job:    job,
+		DryRun: dryRun,
+	}
+	TaskManager.Add(t)
+	return t, nil
+}
+
+func running(id uint) bool {
+	for _, t := range TaskManager.GetByCondition(func(t *SyncTask) bool {
+		return t.Job.ID == id && !t.DryRun
+	}) {
+		switch t.GetState() {
+		case tache.StatePending, tache.StateRunning, tache.StateBeforeRetry, tache.StateWaitingRetry:
+			return true
+		}
+	}
+	return false
+}
+
+func saveResult(id uint, result string) {
+	job, err := db.GetSyncJobById(id)
+	if err != nil {
+		// the job has been deleted
+		return
+	}
+	now := time.Now()
+	job.LastRun, job.LastResult = &now, result
+	if err = db.UpdateSyncJob(job); err != nil {
+		utils.Log.Errorf("failed save the result of sync job %d: %+v", id, err)
+	}
+}
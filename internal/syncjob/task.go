@@ -0,0 +1,337 @@
+package syncjob
+
+import (
+	"fmt"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/tache"
+	"github.com/pkg/errors"
+)
+
+const (
+	ActionCopy       = "copy"
+	ActionUpdate     = "update"
+	ActionDelete     = "delete"
+	ActionCopyBack   = "copy_back"
+	ActionUpdateBack = "update_back"
+)
+
+// Action is a change needed to sync a job, the paths are mount paths
+type Action struct {
+	Action string `json:"action"`
+	// the object to be copied or deleted
+	Path string `json:"path"`
+	// the dir the object is copied to, empty for delete
+	DstDir string `json:"dst_dir,omitempty"`
+	IsDir  bool   `json:"is_dir"`
+	Size   int64  `json:"size"`
+	Error  string `json:"error,omitempty"`
+}
+
+type SyncTask struct {
+	task.TaskExtension
+	Job     model.SyncJob `json:"job"`
+	DryRun  bool          `json:"dry_run"`
+	Actions []Action      `json:"actions"`
+	status  string
+	// synced is the objects in sync at the last run of a two way job by the
+	// path relative to the job, entries is the ones of this run
+	synced  map[string]model.SyncEntry
+	entries []model.SyncEntry
+}
+
+func (t *SyncTask) GetName() string {
+	name := fmt.Sprintf("sync [%s] to [%s]", t.Job.SrcPath, t.Job.DstPath)
+	if t.DryRun {
+		name += " (dry run)"
+	}
+	return name
+}
+
+func (t *SyncTask) GetStatus() string {
+	return t.status
+}
+
+func (t *SyncTask) Run() error {
+	if err := t.ReinitCtx(); err != nil {
+		return err
+	}
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	err := t.run()
+	if !t.DryRun {
+		saveResult(t.Job.ID, t.result(err))
+	}
+	return err
+}
+
+func (t *SyncTask) run() error {
+	t.Actions, t.entries = nil, nil
+	t.status = "comparing"
+	if err := t.loadSynced(); err != nil {
+		return err
+	}
+	if _, err := fs.Get(t.Ctx(), t.Job.SrcPath, &fs.GetArgs{}); err != nil {
+		return errors.WithMessage(err, "failed get src dir")
+	}
+	if _, err := fs.Get(t.Ctx(), t.Job.DstPath, &fs.GetArgs{NoLog: true}); err != nil {
+		if !errs.IsObjectNotFound(err) {
+			return errors.WithMessage(err, "failed get dst dir")
+		}
+		if !t.DryRun {
+			if err = fs.MakeDir(t.Ctx(), t.Job.DstPath); err != nil {
+				return err
+			}
+		}
+	}
+	if err := t.compare(t.Job.SrcPath, t.Job.DstPath, ""); err != nil {
+		return err
+	}
+	if t.DryRun {
+		t.status = fmt.Sprintf("%d changes to sync", len(t.Actions))
+		t.SetProgress(100)
+		return nil
+	}
+	var tasks int
+	for i := range t.Actions {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		a := &t.Actions[i]
+		if a.Error != "" {
+			// skipped when comparing
+			continue
+		}
+		t.status = fmt.Sprintf("%s %s", a.Action, a.Path)
+		var err error
+		if a.Action == ActionDelete {
+			err = fs.Remove(t.Ctx(), a.Path)
+		} else {
+			var tsk task.TaskExtensionInfo
+			tsk, err = fs.Copy(t.Ctx(), a.Path, a.DstDir)
+			if tsk != nil {
+				tasks++
+			}
+		}
+		if err != nil {
+			a.Error = err.Error()
+		}
+		t.SetProgress(float64(i+1) / float64(len(t.Actions)) * 100)
+	}
+	t.status = fmt.Sprintf("%d changes synced, %d copy tasks added", len(t.Actions), tasks)
+	if t.Job.Mode == model.SyncTwoWay {
+		// the copied objects are recorded by the next run, after the copy tasks
+		if err := db.SaveSyncEntries(t.Job.ID, t.entries); err != nil {
+			return errors.WithMessage(err, "failed save the synced objects")
+		}
+	}
+	return nil
+}
+
+func (t *SyncTask) loadSynced() error {
+	t.synced = nil
+	if t.Job.Mode != model.SyncTwoWay {
+		return nil
+	}
+	entries, err := db.GetSyncEntries(t.Job.ID)
+	if err != nil {
+		return err
+	}
+	t.synced = make(map[string]model.SyncEntry, len(entries))
+	for _, e := range entries {
+		t.synced[e.Path] = e
+	}
+	return nil
+}
+
+func (t *SyncTask) result(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	failed := 0
+	for _, a := range t.Actions {
+		if a.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Sprintf("%d of %d changes failed", failed, len(t.Actions))
+	}
+	return t.status
+}
+
+func (t *SyncTask) list(path string) (map[string]model.Obj, error) {
+	objs, err := fs.List(t.Ctx(), path, &fs.ListArgs{Refresh: true, NoLog: true})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	m := make(map[string]model.Obj, len(objs))
+	for _, obj := range objs {
+		m[obj.GetName()] = obj
+	}
+	return m, nil
+}
+
+func (t *SyncTask) add(action string, path, dstDir string, obj model.Obj) {
+	t.Actions = append(t.Actions, Action{
+		Action: action,
+		Path:   path,
+		DstDir: dstDir,
+		IsDir:  obj.IsDir(),
+		Size:   obj.GetSize(),
+	})
+}
+
+// keep records the objects in sync for the next run of a two way job
+func (t *SyncTask) keep(rel string, src, dst model.Obj) {
+	if t.Job.Mode != model.SyncTwoWay {
+		return
+	}
+	t.entries = append(t.entries, model.SyncEntry{
+		JobID:       t.Job.ID,
+		Path:        rel,
+		IsDir:       src.IsDir(),
+		Size:        src.GetSize(),
+		SrcModified: src.ModTime(),
+		DstModified: dst.ModTime(),
+	})
+}
+
+// deleted reports whether obj at path, which is missing on the other side, has
+// been deleted there since the last run. It's true only if obj was in sync at
+// the last run and is unchanged since, so the changes are never lost
+func (t *SyncTask) deleted(path, rel string, obj model.Obj, isSrc bool) (bool, error) {
+	e, ok := t.synced[rel]
+	if !ok || e.IsDir != obj.IsDir() {
+		return false, nil
+	}
+	if !obj.IsDir() {
+		modified := e.DstModified
+		if isSrc {
+			modified = e.SrcModified
+		}
+		diff := obj.ModTime().Sub(modified)
+		return obj.GetSize() == e.Size && diff <= modTimeTolerance && diff >= -modTimeTolerance, nil
+	}
+	objs, err := t.list(path)
+	if err != nil {
+		return false, errors.WithMessagef(err, "failed list %s", path)
+	}
+	for name, o := range objs {
+		if ok, err = t.deleted(stdpath.Join(path, name), stdpath.Join(rel, name), o, isSrc); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// compare adds the actions needed to sync srcDir to dstDir, rel is their path
+// relative to the job
+func (t *SyncTask) compare(srcDir, dstDir, rel string) error {
+	if utils.IsCanceled(t.Ctx()) {
+		return t.Ctx().Err()
+	}
+	srcObjs, err := t.list(srcDir)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list %s", srcDir)
+	}
+	dstObjs, err := t.list(dstDir)
+	if err != nil {
+		return errors.WithMessagef(err, "failed list %s", dstDir)
+	}
+	twoWay := t.Job.Mode == model.SyncTwoWay
+	for name, src := range srcObjs {
+		srcPath, dstPath := stdpath.Join(srcDir, name), stdpath.Join(dstDir, name)
+		objRel := stdpath.Join(rel, name)
+		dst, ok := dstObjs[name]
+		switch {
+		case !ok:
+			var gone bool
+			if twoWay {
+				if gone, err = t.deleted(srcPath, objRel, src, true); err != nil {
+					return err
+				}
+			}
+			if gone {
+				t.add(ActionDelete, srcPath, "", src)
+			} else {
+				t.add(ActionCopy, srcPath, dstDir, src)
+			}
+		case src.IsDir() && dst.IsDir():
+			t.keep(objRel, src, dst)
+			if err = t.compare(srcPath, dstPath, objRel); err != nil {
+				return err
+			}
+		case src.IsDir() != dst.IsDir():
+			// a file and a dir of the same name can't be synced without losing one
+			t.Actions = append(t.Actions, Action{
+				Action: ActionUpdate,
+				Path:   srcPath,
+				DstDir: dstDir,
+				IsDir:  src.IsDir(),
+				Error:  "skipped, the type of src and dst differ",
+			})
+		case changed(src, dst):
+			if twoWay && dst.ModTime().After(src.ModTime()) {
+				t.add(ActionUpdateBack, dstPath, srcDir, dst)
+			} else {
+				t.add(ActionUpdate, srcPath, dstDir, src)
+			}
+		default:
+			t.keep(objRel, src, dst)
+		}
+	}
+	for name, dst := range dstObjs {
+		if _, ok := srcObjs[name]; ok {
+			continue
+		}
+		dstPath := stdpath.Join(dstDir, name)
+		if twoWay {
+			gone, err := t.deleted(dstPath, stdpath.Join(rel, name), dst, false)
+			if err != nil {
+				return err
+			}
+			if gone {
+				t.add(ActionDelete, dstPath, "", dst)
+			} else {
+				t.add(ActionCopyBack, dstPath, srcDir, dst)
+			}
+		} else if t.Job.DeleteExtraneous {
+			t.add(ActionDelete, dstPath, "", dst)
+		}
+	}
+	return nil
+}
+
+// changed compares the files by size and hash, by the modified time if they
+// don't have a hash in common
+func changed(src, dst model.Obj) bool {
+	if src.GetSize() != dst.GetSize() {
+		return true
+	}
+	dstHash := dst.GetHash()
+	for ht, v := range src.GetHash().All() {
+		if dv := dstHash.GetHash(ht); v != "" && dv != "" {
+			return !strings.EqualFold(v, dv)
+		}
+	}
+	// most storages set the modified time to the upload time, so dst is usually newer
+	return src.ModTime().Sub(dst.ModTime()) > modTimeTolerance
+}
+
+// modTimeTolerance is the precision of the modified time of most storages
+const modTimeTolerance = 2 * time.Second
+
+var TaskManager *tache.Manager[*SyncTask]
@@ -0,0 +1,239 @@
+package syncjob
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	_ "github.com/OpenListTeam/OpenList/v4/drivers/local"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func initTestDB(t *testing.T) {
+	dB, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	// every connection of an in-memory sqlite has its own database
+	sqlDB, _ := dB.DB()
+	sqlDB.SetMaxOpenConns(1)
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+var modified = time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+
+// mount creates a local storage at mountPath with the files of content,
+// which are modified at the time of modified
+func mount(t *testing.T, mountPath string, content map[string]string) {
+	t.Helper()
+	root := t.TempDir()
+	for name, data := range content {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+}
+
+func newTestTask(job model.SyncJob, synced ...model.SyncEntry) *SyncTask {
+	t := &SyncTask{Job: job}
+	t.SetCtx(context.Background())
+	if job.Mode == model.SyncTwoWay {
+		t.synced = make(map[string]model.SyncEntry, len(synced))
+		for _, e := range synced {
+			t.synced[e.Path] = e
+		}
+	}
+	return t
+}
+
+func actions(t *SyncTask) []string {
+	var res []string
+	for _, a := range t.Actions {
+		res = append(res, fmt.Sprintf("%s %s %s", a.Action, a.Path, a.DstDir))
+	}
+	slices.Sort(res)
+	return res
+}
+
+func TestChanged(t *testing.T) {
+	md5 := func(s string) utils.HashInfo { return utils.NewHashInfo(utils.MD5, s) }
+	tests := []struct {
+		name     string
+		src, dst model.Object
+		want     bool
+	}{
+		{"size", model.Object{Size: 1, Modified: modified}, model.Object{Size: 2, Modified: modified}, true},
+		{"same", model.Object{Size: 1, Modified: modified}, model.Object{Size: 1, Modified: modified}, false},
+		{"dst newer", model.Object{Size: 1, Modified: modified}, model.Object{Size: 1, Modified: modified.Add(time.Hour)}, false},
+		{"src newer", model.Object{Size: 1, Modified: modified.Add(time.Hour)}, model.Object{Size: 1, Modified: modified}, true},
+		{"src newer in tolerance", model.Object{Size: 1, Modified: modified.Add(time.Second)}, model.Object{Size: 1, Modified: modified}, false},
+		{"same hash", model.Object{Size: 1, Modified: modified.Add(time.Hour), HashInfo: md5("ABC")},
+			model.Object{Size: 1, Modified: modified, HashInfo: md5("abc")}, false},
+		{"different hash", model.Object{Size: 1, Modified: modified, HashInfo: md5("abc")},
+			model.Object{Size: 1, Modified: modified, HashInfo: md5("def")}, true},
+		{"hash on one side", model.Object{Size: 1, Modified: modified.Add(time.Hour), HashInfo: md5("abc")},
+			model.Object{Size: 1, Modified: modified}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changed(&tt.src, &tt.dst); got != tt.want {
+				t.Errorf("changed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareOneWay(t *testing.T) {
+	initTestDB(t)
+	mount(t, "/one/src", map[string]string{"same.txt": "a", "changed.txt": "ab", "new/a.txt": "a", "type": "a"})
+	mount(t, "/one/dst", map[string]string{"same.txt": "a", "changed.txt": "a", "extra/a.txt": "a", "type/a.txt": "a"})
+
+	for _, deleteExtraneous := range []bool{false, true} {
+		t.Run(fmt.Sprintf("delete extraneous %v", deleteExtraneous), func(t *testing.T) {
+			task := newTestTask(model.SyncJob{SrcPath: "/one/src", DstPath: "/one/dst",
+				Mode: model.SyncOneWay, DeleteExtraneous: deleteExtraneous})
+			if err := task.compare(task.Job.SrcPath, task.Job.DstPath, ""); err != nil {
+				t.Fatalf("failed compare: %+v", err)
+			}
+			want := []string{
+				"copy /one/src/new /one/dst",
+				"update /one/src/changed.txt /one/dst",
+				"update /one/src/type /one/dst",
+			}
+			if deleteExtraneous {
+				want = append(want, "delete /one/dst/extra ")
+				slices.Sort(want)
+			}
+			if got := actions(task); !slices.Equal(got, want) {
+				t.Errorf("actions = %q, want %q", got, want)
+			}
+			for _, a := range task.Actions {
+				if (a.Path == "/one/src/type") == (a.Error == "") {
+					t.Errorf("only the object of different types should be skipped, got %+v", a)
+				}
+			}
+			if len(task.entries) != 0 {
+				t.Errorf("one way jobs should not keep entries, got %+v", task.entries)
+			}
+		})
+	}
+}
+
+func TestCompareTwoWay(t *testing.T) {
+	initTestDB(t)
+	mount(t, "/two/src", map[string]string{
+		"same.txt":          "a",
+		"deleted.txt":       "a",
+		"edited.txt":        "abc",
+		"new.txt":           "a",
+		"dir/a.txt":         "a",
+		"dir/b.txt":         "a",
+		"changed_dir/a.txt": "a",
+		"changed_dir/b.txt": "a",
+	})
+	mount(t, "/two/dst", map[string]string{
+		"same.txt":    "a",
+		"dst_new.txt": "a",
+	})
+	entry := func(path string, isDir bool) model.SyncEntry {
+		e := model.SyncEntry{Path: path, IsDir: isDir, SrcModified: modified, DstModified: modified}
+		if !isDir {
+			e.Size = 1
+		}
+		return e
+	}
+	task := newTestTask(model.SyncJob{SrcPath: "/two/src", DstPath: "/two/dst", Mode: model.SyncTwoWay},
+		entry("same.txt", false),
+		entry("deleted.txt", false),
+		// edited since the last sync, so it's copied instead of deleted
+		entry("edited.txt", false),
+		entry("dir", true),
+		entry("dir/a.txt", false),
+		entry("dir/b.txt", false),
+		// b.txt is new in the dir, so it's copied instead of deleted
+		entry("changed_dir", true),
+		entry("changed_dir/a.txt", false),
+	)
+	if err := task.compare(task.Job.SrcPath, task.Job.DstPath, ""); err != nil {
+		t.Fatalf("failed compare: %+v", err)
+	}
+	want := []string{
+		"copy /two/src/changed_dir /two/dst",
+		"copy /two/src/edited.txt /two/dst",
+		"copy /two/src/new.txt /two/dst",
+		"copy_back /two/dst/dst_new.txt /two/src",
+		"delete /two/src/deleted.txt ",
+		"delete /two/src/dir ",
+	}
+	if got := actions(task); !slices.Equal(got, want) {
+		t.Errorf("actions = %q, want %q", got, want)
+	}
+	if len(task.entries) != 1 || task.entries[0].Path != "same.txt" {
+		t.Errorf("entries = %+v, want only same.txt", task.entries)
+	}
+}
+
+func TestDeleted(t *testing.T) {
+	initTestDB(t)
+	mount(t, "/deleted", map[string]string{"a.txt": "a", "dir/a.txt": "a"})
+	task := newTestTask(model.SyncJob{Mode: model.SyncTwoWay},
+		model.SyncEntry{Path: "a.txt", Size: 1, SrcModified: modified, DstModified: modified.Add(time.Hour)},
+		model.SyncEntry{Path: "dir", IsDir: true},
+		model.SyncEntry{Path: "dir/a.txt", Size: 1, SrcModified: modified.Add(time.Hour), DstModified: modified},
+	)
+	objs, err := task.list("/deleted")
+	if err != nil {
+		t.Fatalf("failed list: %+v", err)
+	}
+	tests := []struct {
+		name  string
+		isSrc bool
+		want  bool
+	}{
+		{"a.txt", true, true},
+		// the modified time of the other side doesn't match
+		{"a.txt", false, false},
+		{"dir", true, false},
+		{"dir", false, true},
+	}
+	for _, tt := range tests {
+		got, err := task.deleted("/deleted/"+tt.name, tt.name, objs[tt.name], tt.isSrc)
+		if err != nil {
+			t.Fatalf("failed check %s: %+v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("deleted(%s, isSrc=%v) = %v, want %v", tt.name, tt.isSrc, got, tt.want)
+		}
+	}
+	// not in sync at the last run
+	task.synced = nil
+	if got, _ := task.deleted("/deleted/a.txt", "a.txt", objs["a.txt"], true); got {
+		t.Error("an object never synced should not be deleted")
+	}
+}
@@ -0,0 +1,159 @@
+package traffic
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"golang.org/x/time/rate"
+)
+
+type Direction int
+
+const (
+	Download Direction = iota
+	Upload
+)
+
+type userLimiter struct {
+	speed   int
+	limiter stream.Limiter
+}
+
+// the limiters are shared by all transfers of a user in a direction
+var (
+	limitersMu sync.Mutex
+	limiters   = [2]map[uint]*userLimiter{make(map[uint]*userLimiter), make(map[uint]*userLimiter)}
+)
+
+// getLimiter returns the limiter of the user, the limit is updated if the speed has been changed
+func getLimiter(userID uint, direction Direction, speed int) stream.Limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	l, ok := limiters[direction][userID]
+	if speed <= 0 {
+		if ok {
+			delete(limiters[direction], userID)
+		}
+		return nil
+	}
+	limit, burst := rate.Limit(speed)*1024.0, speed*1024
+	if !ok {
+		l = &userLimiter{speed: speed, limiter: stream.NewLimiter(limit, burst)}
+		limiters[direction][userID] = l
+	} else if l.speed != speed {
+		l.speed = speed
+		l.limiter.SetLimit(limit)
+		l.limiter.SetBurst(burst)
+	}
+	return l.limiter
+}
+
+// Exceeded reports whether the monthly traffic limit of user is exceeded
+func Exceeded(user *model.User) bool {
+	return exceeded(user.ID, op.GetTrafficLimit(user))
+}
+
+func exceeded(userID uint, limit model.TrafficLimit) bool {
+	if limit.MonthlyTraffic <= 0 {
+		return false
+	}
+	used, err := MonthlyUsed(userID)
+	if err != nil {
+		utils.Log.Errorf("failed get monthly traffic of user %d: %+v", userID, err)
+		return false
+	}
+	return used >= limit.MonthlyTraffic
+}
+
+// Meter counts the bytes transferred by a user in a direction and limits the speed
+type Meter struct {
+	ctx       context.Context
+	userID    uint
+	direction Direction
+	limit     model.TrafficLimit
+	limiter   stream.Limiter
+}
+
+func NewMeter(ctx context.Context, user *model.User, direction Direction) *Meter {
+	limit := op.GetTrafficLimit(user)
+	speed := limit.DownloadSpeed
+	if direction == Upload {
+		speed = limit.UploadSpeed
+	}
+	return &Meter{
+		ctx:       ctx,
+		userID:    user.ID,
+		direction: direction,
+		limit:     limit,
+		limiter:   getLimiter(user.ID, direction, speed),
+	}
+}
+
+// Check returns an error if the monthly traffic limit is exceeded
+func (m *Meter) Check() error {
+	if exceeded(m.userID, m.limit) {
+		return errs.TrafficExceeded
+	}
+	return nil
+}
+
+// Wait counts n bytes and waits for the limiter of the user
+func (m *Meter) Wait(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	if m.direction == Upload {
+		Add(m.userID, 0, int64(n))
+	} else {
+		Add(m.userID, int64(n), 0)
+	}
+	if m.limiter == nil {
+		return nil
+	}
+	return m.limiter.WaitN(m.ctx, n)
+}
+
+type Reader struct {
+	io.Reader
+	Meter *Meter
+}
+
+func (r *Reader) Read(p []byte) (n int, err error) {
+	if err = r.Meter.Check(); err != nil {
+		return 0, err
+	}
+	n, err = r.Reader.Read(p)
+	if werr := r.Meter.Wait(n); werr != nil && err == nil {
+		err = werr
+	}
+	return
+}
+
+func (r *Reader) Close() error {
+	if c, ok := r.Reader.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+type Writer struct {
+	io.Writer
+	Meter *Meter
+}
+
+func (w *Writer) Write(p []byte) (n int, err error) {
+	if err = w.Meter.Check(); err != nil {
+		return 0, err
+	}
+	n, err = w.Writer.Write(p)
+	if werr := w.Meter.Wait(n); werr != nil && err == nil {
+		err = werr
+	}
+	return
+}
@@ -0,0 +1,122 @@
+package traffic
+
+import (
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type usage struct {
+	download, upload int64
+}
+
+type monthUsage struct {
+	month string
+	total int64
+}
+
+// the traffic is counted in memory and flushed to the database periodically,
+// the traffic of the current month is cached to check the monthly limits
+var (
+	mu        sync.Mutex
+	pending   = make(map[uint]*usage)
+	monthly   = make(map[uint]*monthUsage)
+	flushCron *cron.Cron
+)
+
+// Init flushes the traffic to the database every minute
+func Init() {
+	if flushCron != nil {
+		flushCron.Stop()
+	}
+	flushCron = cron.NewCron(time.Minute)
+	flushCron.Do(func() {
+		if err := Flush(); err != nil {
+			utils.Log.Errorf("failed flush traffic: %+v", err)
+		}
+	})
+}
+
+// Add counts the bytes transferred by the user
+func Add(userID uint, download, upload int64) {
+	if download <= 0 && upload <= 0 {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	u, ok := pending[userID]
+	if !ok {
+		u = &usage{}
+		pending[userID] = u
+	}
+	u.download += download
+	u.upload += upload
+	if m, ok := monthly[userID]; ok {
+		m.total += download + upload
+	}
+}
+
+// Flush writes the pending traffic to the day and the month of now,
+// the traffic failed to write is kept for the next flush
+func Flush() error {
+	mu.Lock()
+	flushing := pending
+	pending = make(map[uint]*usage)
+	mu.Unlock()
+	now := time.Now()
+	var (
+		failed  int
+		lastErr error
+	)
+	for userID, u := range flushing {
+		err := db.AddTraffic(userID, u.download, u.upload, model.DayPeriod(now), model.MonthPeriod(now))
+		if err != nil {
+			failed++
+			lastErr = err
+			mu.Lock()
+			p, ok := pending[userID]
+			if !ok {
+				p = &usage{}
+				pending[userID] = p
+			}
+			p.download += u.download
+			p.upload += u.upload
+			mu.Unlock()
+		}
+	}
+	if lastErr != nil {
+		return errors.WithMessagef(lastErr, "failed write traffic of %d users", failed)
+	}
+	return nil
+}
+
+// MonthlyUsed returns the traffic of the user in the current month
+func MonthlyUsed(userID uint) (int64, error) {
+	month := model.MonthPeriod(time.Now())
+	mu.Lock()
+	if m, ok := monthly[userID]; ok && m.month == month {
+		defer mu.Unlock()
+		return m.total, nil
+	}
+	mu.Unlock()
+	var total int64
+	t, err := db.GetTraffic(userID, month)
+	if err == nil {
+		total = t.Total()
+	} else if !errors.Is(errors.Cause(err), gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if u, ok := pending[userID]; ok {
+		total += u.download + u.upload
+	}
+	monthly[userID] = &monthUsage{month: month, total: total}
+	return total, nil
+}
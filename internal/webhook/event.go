@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+)
+
+type FileEvent struct {
+	Path     string `json:"path"`
+	DstPath  string `json:"dst_path,omitempty"`
+	Username string `json:"username,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+	IP       string `json:"ip,omitempty"`
+}
+
+type TaskEvent struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Creator    string `json:"creator,omitempty"`
+	TotalBytes int64  `json:"total_bytes"`
+	Error      string `json:"error,omitempty"`
+}
+
+type LoginFailedEvent struct {
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+	Protocol string `json:"protocol"`
+}
+
+type StorageStatusEvent struct {
+	MountPath string `json:"mount_path"`
+	Driver    string `json:"driver"`
+	Status    string `json:"status"`
+	OldStatus string `json:"old_status"`
+}
+
+// EmitFile emits a file event of the user in ctx, dstPath is optional
+func EmitFile(ctx context.Context, event, path string, dstPath ...string) {
+	e := FileEvent{Path: path}
+	if len(dstPath) > 0 {
+		e.DstPath = dstPath[0]
+	}
+	if user, ok := ctx.Value(conf.UserKey).(*model.User); ok {
+		e.Username = user.Username
+	}
+	e.Protocol, _ = ctx.Value(conf.ProtocolKey).(string)
+	e.IP, _ = ctx.Value(conf.ClientIPKey).(string)
+	Emit(event, e)
+}
+
+// EmitTask emits task.succeeded or task.failed, it's called in the
+// OnSucceeded and OnFailed hooks of the tasks. the error of a retried
+// task is still set in OnSucceeded, so the result is passed explicitly
+func EmitTask(t task.TaskExtensionInfo, succeeded bool) {
+	e := TaskEvent{
+		ID:         t.GetID(),
+		Name:       t.GetName(),
+		TotalBytes: t.GetTotalBytes(),
+	}
+	if creator := t.GetCreator(); creator != nil {
+		e.Creator = creator.Username
+	}
+	event := model.EventTaskSucceeded
+	if !succeeded {
+		event = model.EventTaskFailed
+		if err := t.GetErr(); err != nil {
+			e.Error = err.Error()
+		}
+	}
+	Emit(event, e)
+}
+
+func EmitLoginFailed(username, ip, protocol string) {
+	Emit(model.EventLoginFailed, LoginFailedEvent{Username: username, IP: ip, Protocol: protocol})
+}
+
+func EmitStorageStatus(storage model.Storage, oldStatus string) {
+	Emit(model.EventStorageStatus, StorageStatusEvent{
+		MountPath: storage.MountPath,
+		Driver:    storage.Driver,
+		Status:    storage.Status,
+		OldStatus: oldStatus,
+	})
+}
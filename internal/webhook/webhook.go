@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/net"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/OpenListTeam/OpenList/v4/pkg/sign"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+)
+
+const (
+	// the signature expires after signatureTTL, so a captured request can't be replayed later
+	signatureTTL     = 5 * time.Minute
+	requestTimeout   = 30 * time.Second
+	maxConcurrency   = 8
+	deliveryLifetime = 30 * 24 * time.Hour
+)
+
+// the delays before the attempts of a delivery, the first attempt is made at once
+var retryDelays = []time.Duration{0, 10 * time.Second, time.Minute}
+
+// Payload is the body of the requests sent to the webhooks
+type Payload struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data"`
+}
+
+// the webhooks are cached since they are checked on every event
+var (
+	mu          sync.RWMutex
+	webhooks    []model.Webhook
+	loaded      bool
+	sem         = make(chan struct{}, maxConcurrency)
+	client      *http.Client
+	clientOnce  sync.Once
+	cleanupCron *cron.Cron
+)
+
+// Init deletes the old deliveries daily
+func Init() {
+	if cleanupCron != nil {
+		cleanupCron.Stop()
+	}
+	cleanupCron = cron.NewCron(24 * time.Hour)
+	cleanupCron.Do(func() {
+		if err := db.DeleteWebhookDeliveriesBefore(time.Now().Add(-deliveryLifetime)); err != nil {
+			utils.Log.Errorf("failed delete old webhook deliveries: %+v", err)
+		}
+	})
+}
+
+func getWebhooks() ([]model.Webhook, error) {
+	mu.RLock()
+	if loaded {
+		defer mu.RUnlock()
+		return webhooks, nil
+	}
+	mu.RUnlock()
+	mu.Lock()
+	defer mu.Unlock()
+	if !loaded {
+		hooks, err := db.GetWebhooks()
+		if err != nil {
+			return nil, err
+		}
+		webhooks = hooks
+		loaded = true
+	}
+	return webhooks, nil
+}
+
+// Reload drops the cached webhooks, it must be called after they are changed
+func Reload() {
+	mu.Lock()
+	defer mu.Unlock()
+	webhooks = nil
+	loaded = false
+}
+
+// Emit sends the event to the subscribed webhooks in the background
+func Emit(event string, data any) {
+	hooks, err := getWebhooks()
+	if err != nil {
+		utils.Log.Errorf("failed get webhooks: %+v", err)
+		return
+	}
+	var body []byte
+	for _, w := range hooks {
+		if w.Disabled || !w.Subscribed(event) {
+			continue
+		}
+		if body == nil {
+			if body, err = utils.Json.Marshal(Payload{Event: event, Time: time.Now(), Data: data}); err != nil {
+				utils.Log.Errorf("failed marshal webhook payload of %s: %+v", event, err)
+				return
+			}
+		}
+		go deliver(w, event, body, len(retryDelays))
+	}
+}
+
+// Ping sends a ping event to w once and returns the delivery
+func Ping(w model.Webhook) *model.WebhookDelivery {
+	body, _ := utils.Json.Marshal(Payload{Event: model.EventPing, Time: time.Now(), Data: map[string]any{"webhook_id": w.ID}})
+	return deliver(w, model.EventPing, body, 1)
+}
+
+// deliver records the delivery of the event and makes its first attempt, the
+// others are scheduled with timers, so that nothing is held while waiting
+func deliver(w model.Webhook, event string, body []byte, attempts int) *model.WebhookDelivery {
+	d := &model.WebhookDelivery{
+		WebhookID: w.ID,
+		Event:     event,
+		Payload:   string(body),
+		Created:   time.Now(),
+	}
+	if err := db.CreateWebhookDelivery(d); err != nil {
+		utils.Log.Errorf("failed create webhook delivery: %+v", err)
+	}
+	attempt(w, d, body, attempts)
+	return d
+}
+
+// attempt posts the delivery once and schedules the next attempt if it failed,
+// the semaphore limits the concurrent posts only
+func attempt(w model.Webhook, d *model.WebhookDelivery, body []byte, attempts int) {
+	sem <- struct{}{}
+	d.Attempts++
+	d.StatusCode, d.Error = 0, ""
+	statusCode, err := post(w, d, body)
+	<-sem
+	d.StatusCode = statusCode
+	if err != nil {
+		d.Error = err.Error()
+	} else {
+		d.Success = true
+	}
+	if err := db.UpdateWebhookDelivery(d); err != nil {
+		utils.Log.Errorf("failed update webhook delivery: %+v", err)
+	}
+	if d.Success {
+		return
+	}
+	if d.Attempts >= attempts {
+		utils.Log.Warnf("failed deliver %s to webhook [%s]: %s", d.Event, w.Name, d.Error)
+		return
+	}
+	time.AfterFunc(retryDelays[d.Attempts], func() {
+		attempt(w, d, body, attempts)
+	})
+}
+
+// post sends body to the webhook, the receiver can verify the X-OpenList-Signature
+// header with the secret by sign.NewHMACSign(secret).Verify(body, signature)
+func post(w model.Webhook, d *model.WebhookDelivery, body []byte) (int, error) {
+	clientOnce.Do(func() { client = net.NewHttpClient() })
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OpenList-Webhook")
+	req.Header.Set("X-OpenList-Event", d.Event)
+	req.Header.Set("X-OpenList-Delivery", strconv.FormatUint(uint64(d.ID), 10))
+	if w.Secret != "" {
+		signature := sign.NewHMACSign([]byte(w.Secret)).Sign(string(body), time.Now().Add(signatureTTL).Unix())
+		req.Header.Set("X-OpenList-Signature", signature)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
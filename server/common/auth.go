@@ -5,7 +5,8 @@ import (
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
-	"github.com/OpenListTeam/go-cache"
+	"github.com/OpenListTeam/OpenList/v4/internal/session"
+	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 )
@@ -18,24 +19,35 @@ type UserClaims struct {
 	jwt.RegisteredClaims
 }
 
-var validTokenCache = cache.NewMemCache[bool]()
-
-func GenerateToken(user *model.User) (tokenString string, err error) {
+// GenerateToken creates a login token of user and saves its session, the client
+// of the request is recorded in the session
+func GenerateToken(c *gin.Context, user *model.User) (tokenString string, err error) {
+	now := time.Now()
+	expires := now.Add(time.Duration(conf.Conf.TokenExpiresIn) * time.Hour)
 	claim := UserClaims{
 		Username: user.Username,
 		PwdTS:    user.PwdTS,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(conf.Conf.TokenExpiresIn) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expires),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		}}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
 	tokenString, err = token.SignedString(SecretKey)
 	if err != nil {
 		return "", err
 	}
-	validTokenCache.Set(tokenString, true)
-	return tokenString, err
+	err = session.Create(tokenString, &model.Session{
+		UserID:    user.ID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Created:   now,
+		Expires:   expires,
+	})
+	if err != nil {
+		return "", err
+	}
+	return tokenString, nil
 }
 
 func ParseToken(tokenString string) (*UserClaims, error) {
@@ -68,11 +80,9 @@ func InvalidateToken(tokenString string) error {
 	if tokenString == "" {
 		return nil // don't invalidate empty guest token
 	}
-	validTokenCache.Del(tokenString)
-	return nil
+	return session.DeleteByToken(tokenString)
 }
 
 func IsTokenInvalidated(tokenString string) bool {
-	_, ok := validTokenCache.Get(tokenString)
-	return !ok
+	return !session.IsValid(tokenString)
 }
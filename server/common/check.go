@@ -17,13 +17,35 @@ func IsStorageSignEnabled(rawPath string) bool {
 	return storage != nil && storage.GetStorage().EnableSign
 }
 
-func CanWrite(meta *model.Meta, path string) bool {
+// CanWrite reports whether user can mkdir and upload in path, the matched acl
+// rule takes precedence over the permission of user and the write flag of meta,
+// but none of them can go beyond the scope of user
+func CanWrite(user *model.User, meta *model.Meta, path string) bool {
+	if !user.ScopeAllows(model.ACLWrite) {
+		return false
+	}
+	if allowed, matched := op.CheckACL(user, path, model.ACLWrite); matched {
+		return allowed
+	}
+	return user.CanWrite() || MetaCanWrite(meta, path)
+}
+
+func MetaCanWrite(meta *model.Meta, path string) bool {
 	if meta == nil || !meta.Write {
 		return false
 	}
 	return meta.WSub || meta.Path == path
 }
 
+// CanOperate reports whether user can do operation in path, the matched acl rule
+// takes precedence over permitted, which is the permission of user for it
+func CanOperate(user *model.User, path string, operation int32, permitted bool) bool {
+	if allowed, matched := op.CheckACL(user, path, operation); matched {
+		return allowed
+	}
+	return permitted
+}
+
 func IsApply(metaPath, reqPath string, applySub bool) bool {
 	if utils.PathEqual(metaPath, reqPath) {
 		return true
@@ -32,15 +54,13 @@ func IsApply(metaPath, reqPath string, applySub bool) bool {
 }
 
 func CanAccess(user *model.User, meta *model.Meta, reqPath string, password string) bool {
+	// if an acl rule denies reading, can't access
+	if allowed, matched := op.CheckACL(user, reqPath, model.ACLRead); matched && !allowed {
+		return false
+	}
 	// if the reqPath is in hide (only can check the nearest meta) and user can't see hides, can't access
-	if meta != nil && !user.CanSeeHides() && meta.Hide != "" &&
-		IsApply(meta.Path, path.Dir(reqPath), meta.HSub) { // the meta should apply to the parent of current path
-		for _, hide := range strings.Split(meta.Hide, "\n") {
-			re := regexp2.MustCompile(hide, regexp2.None)
-			if isMatch, _ := re.MatchString(path.Base(reqPath)); isMatch {
-				return false
-			}
-		}
+	if IsHidden(user, meta, reqPath) {
+		return false
 	}
 	// if is not guest and can access without password
 	if user.CanAccessWithoutPassword() {
@@ -58,6 +78,22 @@ func CanAccess(user *model.User, meta *model.Meta, reqPath string, password stri
 	return meta.Password == password
 }
 
+// IsHidden reports whether reqPath is hidden from user by the hide of meta,
+// which is the nearest meta of reqPath
+func IsHidden(user *model.User, meta *model.Meta, reqPath string) bool {
+	if meta == nil || user.CanSeeHides() || meta.Hide == "" ||
+		!IsApply(meta.Path, path.Dir(reqPath), meta.HSub) { // the meta should apply to the parent of current path
+		return false
+	}
+	for _, hide := range strings.Split(meta.Hide, "\n") {
+		re := regexp2.MustCompile(hide, regexp2.None)
+		if isMatch, _ := re.MatchString(path.Base(reqPath)); isMatch {
+			return true
+		}
+	}
+	return false
+}
+
 // ShouldProxy TODO need optimize
 // when should be proxy?
 // 1. config.MustProxy()
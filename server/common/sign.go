@@ -9,9 +9,10 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 )
 
-func Sign(obj model.Obj, parent string, encrypt bool) string {
+// Sign signs the link of obj for user, the downloads by it are counted to the user
+func Sign(user *model.User, obj model.Obj, parent string, encrypt bool) string {
 	if obj.IsDir() || (!encrypt && !setting.GetBool(conf.SignAll)) {
 		return ""
 	}
-	return sign.Sign(stdpath.Join(parent, obj.GetName()))
+	return sign.SignBy(stdpath.Join(parent, obj.GetName()), user.ID)
 }
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
+	"github.com/OpenListTeam/go-cache"
+)
+
+// the stream tickets authorize the requests which can't set the Authorization
+// header, like EventSource and WebSocket of the browsers, so that the long-lived
+// tokens are not put in the urls. A ticket can be used once in a short time
+
+const StreamTicketExpiration = 30 * time.Second
+
+type streamTicket struct {
+	user *model.User
+	// the api token the ticket is issued with, the user is restricted to it
+	apiToken string
+}
+
+var streamTickets = cache.NewMemCache(cache.WithShards[*streamTicket](2))
+
+// IssueStreamTicket returns a ticket authorizing a request as user
+func IssueStreamTicket(user *model.User, apiToken string) string {
+	ticket := random.String(32)
+	streamTickets.Set(ticket, &streamTicket{user: user, apiToken: apiToken},
+		cache.WithEx[*streamTicket](StreamTicketExpiration))
+	return ticket
+}
+
+// UseStreamTicket returns the user and the api token the ticket is issued with,
+// and invalidates the ticket
+func UseStreamTicket(ticket string) (*model.User, string, bool) {
+	t, ok := streamTickets.Get(ticket)
+	// only one of the requests using the ticket at the same time deletes it
+	if !ok || streamTickets.Del(ticket) == 0 {
+		return nil, "", false
+	}
+	return t.user, t.apiToken, true
+}
@@ -12,11 +12,13 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/ftp"
 	ftpserver "github.com/fclairamb/ftpserverlib"
@@ -112,35 +114,108 @@ func (d *FtpMainDriver) ClientDisconnected(cc ftpserver.ClientContext) {
 func (d *FtpMainDriver) AuthUser(cc ftpserver.ClientContext, user, pass string) (ftpserver.ClientDriver, error) {
 	var userObj *model.User
 	var err error
+	metaPass := ""
 	if user == "anonymous" || user == "guest" {
 		userObj, err = op.GetGuest()
 		if err != nil {
 			return nil, err
 		}
+		metaPass = pass
 	} else {
 		userObj, err = op.GetUserByName(user)
 		if err != nil {
+			webhook.EmitLoginFailed(user, cc.RemoteAddr().String(), "ftp")
 			return nil, err
 		}
+		// the ftp passwords of the user are accepted as well as its password
 		passHash := model.StaticHash(pass)
-		if err = userObj.ValidatePwdStaticHash(passHash); err != nil {
+		if err = userObj.ValidatePwdStaticHash(passHash); err != nil && !op.ValidateFTPPassword(userObj.ID, passHash) {
+			webhook.EmitLoginFailed(user, cc.RemoteAddr().String(), "ftp")
 			return nil, err
 		}
 	}
-	if userObj.Disabled || !userObj.CanFTPAccess() {
-		return nil, errors.New("user is not allowed to access via FTP")
+	if err = checkFTPUser(cc, userObj); err != nil {
+		return nil, err
 	}
+	return d.newClientDriver(cc, userObj, metaPass), nil
+}
 
-	ctx := context.Background()
-	ctx = context.WithValue(ctx, conf.UserKey, userObj)
+// PreAuthUser rejects the user before asking for the password if it can't
+// log in from the address of cc, the unknown users are rejected after that
+func (d *FtpMainDriver) PreAuthUser(cc ftpserver.ClientContext, user string) error {
+	var userObj *model.User
+	var err error
 	if user == "anonymous" || user == "guest" {
-		ctx = context.WithValue(ctx, conf.MetaPassKey, pass)
+		userObj, err = op.GetGuest()
 	} else {
-		ctx = context.WithValue(ctx, conf.MetaPassKey, "")
+		userObj, err = op.GetUserByName(user)
+	}
+	if err != nil {
+		return nil
+	}
+	if !userObj.FTPAllowsIP(remoteIP(cc)) {
+		return errors.New("user is not allowed to access via FTP from this address")
+	}
+	return nil
+}
+
+// VerifyConnection logs the user in by the client certificate of FTPS, the
+// password is asked if the client presents no certificate added by the user
+func (d *FtpMainDriver) VerifyConnection(cc ftpserver.ClientContext, user string, tlsConn *tls.Conn) (ftpserver.ClientDriver, error) {
+	if tlsConn == nil {
+		return nil, nil
+	}
+	peerCerts := tlsConn.ConnectionState().PeerCertificates
+	if len(peerCerts) == 0 {
+		return nil, nil
+	}
+	userObj, err := op.GetUserByName(user)
+	if err != nil || userObj.IsGuest() {
+		return nil, nil
 	}
+	cert, err := op.GetFTPCertByFingerprint(userObj.ID, model.FTPCertFingerprint(peerCerts[0]))
+	if err != nil {
+		return nil, nil
+	}
+	if now := time.Now(); now.Before(peerCerts[0].NotBefore) || now.After(peerCerts[0].NotAfter) {
+		return nil, errors.New("the certificate has expired or is not yet valid")
+	}
+	if err = checkFTPUser(cc, userObj); err != nil {
+		return nil, err
+	}
+	cert.UpdateLastUsedTime()
+	if err = op.UpdateFTPCert(cert); err != nil {
+		utils.Log.Errorf("failed to update ftp cert: %+v", err)
+	}
+	return d.newClientDriver(cc, userObj, ""), nil
+}
+
+func (d *FtpMainDriver) newClientDriver(cc ftpserver.ClientContext, user *model.User, metaPass string) ftpserver.ClientDriver {
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, conf.UserKey, user)
+	ctx = context.WithValue(ctx, conf.MetaPassKey, metaPass)
 	ctx = context.WithValue(ctx, conf.ClientIPKey, cc.RemoteAddr().String())
+	ctx = context.WithValue(ctx, conf.ProtocolKey, "ftp")
 	ctx = context.WithValue(ctx, conf.ProxyHeaderKey, d.proxyHeader)
-	return ftp.NewAferoAdapter(ctx), nil
+	return ftp.NewAferoAdapter(ctx)
+}
+
+// checkFTPUser checks whether the user can log in to FTP from the address of cc
+func checkFTPUser(cc ftpserver.ClientContext, user *model.User) error {
+	if user.Disabled || !user.CanFTPAccess() {
+		return errors.New("user is not allowed to access via FTP")
+	}
+	if !user.FTPAllowsIP(remoteIP(cc)) {
+		return errors.New("user is not allowed to access via FTP from this address")
+	}
+	return nil
+}
+
+func remoteIP(cc ftpserver.ClientContext) net.IP {
+	if addr, ok := cc.RemoteAddr().(*net.TCPAddr); ok {
+		return addr.IP
+	}
+	return nil
 }
 
 func (d *FtpMainDriver) GetTLSConfig() (*tls.Config, error) {
@@ -293,5 +368,6 @@ func getTlsConf(keyPath, certPath string) (*tls.Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &tls.Config{Certificates: []tls.Certificate{tlsCert}}, nil
+	// the client certificates are pinned by the users instead of being verified by a CA
+	return &tls.Config{Certificates: []tls.Certificate{tlsCert}, ClientAuth: tls.RequestClientCert}, nil
 }
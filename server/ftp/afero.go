@@ -75,8 +75,20 @@ func (a *AferoAdapter) Chown(_ string, _, _ int) error {
 	return errs.NotSupport
 }
 
-func (a *AferoAdapter) Chtimes(_ string, _ time.Time, _ time.Time) error {
-	return errs.NotSupport
+func (a *AferoAdapter) Chtimes(name string, _ time.Time, mtime time.Time) error {
+	return Chtimes(a.ctx, name, mtime)
+}
+
+func (a *AferoAdapter) SymlinkIfPossible(oldname, newname string) error {
+	return Symlink(a.ctx, oldname, newname)
+}
+
+func (a *AferoAdapter) ReadlinkIfPossible(name string) (string, error) {
+	return ReadLink(a.ctx, name)
+}
+
+func (a *AferoAdapter) StorageDetails(name string) (*model.StorageDetails, error) {
+	return StorageDetails(a.ctx, name)
 }
 
 func (a *AferoAdapter) ReadDir(name string) ([]os.FileInfo, error) {
@@ -89,9 +101,6 @@ func (a *AferoAdapter) GetHandle(name string, flags int, offset int64) (ftpserve
 	if (flags & os.O_SYNC) != 0 {
 		return nil, errs.NotSupport
 	}
-	if (flags & os.O_APPEND) != 0 {
-		return nil, errs.NotSupport
-	}
 	user := a.ctx.Value(conf.UserKey).(*model.User)
 	path, err := user.JoinPath(name)
 	if err != nil {
@@ -106,8 +115,12 @@ func (a *AferoAdapter) GetHandle(name string, flags int, offset int64) (ftpserve
 		return nil, errors.New("file already exists")
 	}
 	if (flags & os.O_WRONLY) != 0 {
+		appending := (flags & os.O_APPEND) != 0
+		if (offset != 0 || appending) && exists {
+			return OpenResumeUpload(a.ctx, path, offset, appending)
+		}
 		if offset != 0 {
-			return nil, errs.NotSupport
+			return nil, errs.ObjectNotFound
 		}
 		trunc := (flags & os.O_TRUNC) != 0
 		if fileSize > 0 {
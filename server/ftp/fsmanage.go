@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	stdpath "path"
+	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -20,17 +21,15 @@ func Mkdir(ctx context.Context, path string) error {
 	if err != nil {
 		return err
 	}
-	if !user.CanWrite() || !user.CanFTPManage() {
-		meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
-		if err != nil {
-			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
-				return err
-			}
-		}
-		if !common.CanWrite(meta, reqPath) {
-			return errs.PermissionDenied
+	meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return err
 		}
 	}
+	if !(user.CanFTPManage() || common.MetaCanWrite(meta, reqPath)) || !common.CanWrite(user, meta, reqPath) {
+		return errs.PermissionDenied
+	}
 	return fs.MakeDir(ctx, reqPath)
 }
 
@@ -82,3 +81,98 @@ func Rename(ctx context.Context, oldPath, newPath string) error {
 		return nil
 	}
 }
+
+func Chtimes(ctx context.Context, path string, mtime time.Time) error {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(path)
+	if err != nil {
+		return err
+	}
+	meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return err
+		}
+	}
+	if !(user.CanFTPManage() || common.MetaCanWrite(meta, reqPath)) || !common.CanWrite(user, meta, stdpath.Dir(reqPath)) {
+		return errs.PermissionDenied
+	}
+	return fs.SetModTime(ctx, reqPath, mtime)
+}
+
+// Symlink creates a symbolic link at path which points to target, target is
+// relative to the dir of path if it isn't absolute
+func Symlink(ctx context.Context, target, path string) error {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(path)
+	if err != nil {
+		return err
+	}
+	if !stdpath.IsAbs(target) {
+		target = stdpath.Join(stdpath.Dir(path), target)
+	}
+	targetPath, err := user.JoinPath(target)
+	if err != nil {
+		return err
+	}
+	meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return err
+		}
+	}
+	if !(user.CanFTPManage() || common.MetaCanWrite(meta, reqPath)) || !common.CanWrite(user, meta, stdpath.Dir(reqPath)) {
+		return errs.PermissionDenied
+	}
+	targetMeta, err := op.GetNearestMeta(targetPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return err
+		}
+	}
+	if !common.CanAccess(user, targetMeta, targetPath, ctx.Value(conf.MetaPassKey).(string)) {
+		return errs.PermissionDenied
+	}
+	return fs.Symlink(ctx, reqPath, targetPath)
+}
+
+// ReadLink returns the path seen by the user which the symbolic link at path points to
+func ReadLink(ctx context.Context, path string) (string, error) {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(path)
+	if err != nil {
+		return "", err
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return "", err
+		}
+	}
+	if !common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
+		return "", errs.PermissionDenied
+	}
+	target, err := fs.ReadLink(ctx, reqPath)
+	if err != nil {
+		return "", err
+	}
+	relPath, ok := user.RelPath(target)
+	if !ok {
+		return "", errs.PermissionDenied
+	}
+	return relPath, nil
+}
+
+// StorageDetails returns the space of the storage which path is in
+func StorageDetails(ctx context.Context, path string) (*model.StorageDetails, error) {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(path)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		return nil, err
+	}
+	return op.GetStorageDetails(ctx, storage)
+}
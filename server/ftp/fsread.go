@@ -14,6 +14,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/pkg/errors"
 )
@@ -21,7 +22,8 @@ import (
 type FileDownloadProxy struct {
 	model.File
 	io.Closer
-	ctx context.Context
+	ctx   context.Context
+	meter *traffic.Meter
 }
 
 func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownloadProxy, error) {
@@ -36,11 +38,16 @@ func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownl
 	if !common.CanAccess(user, meta, reqPath, ctx.Value(conf.MetaPassKey).(string)) {
 		return nil, errs.PermissionDenied
 	}
+	meter := traffic.NewMeter(ctx, user, traffic.Download)
+	if err = meter.Check(); err != nil {
+		return nil, err
+	}
 
 	// directly use proxy
 	header, _ := ctx.Value(conf.ProxyHeaderKey).(http.Header)
 	ip, _ := ctx.Value(conf.ClientIPKey).(string)
 	link, obj, err := fs.Link(ctx, reqPath, model.LinkArgs{IP: ip, Header: header})
+	op.Audit(ctx, model.AuditDownload, err, reqPath)
 	if err != nil {
 		return nil, err
 	}
@@ -57,24 +64,36 @@ func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownl
 		_ = ss.Close()
 		return nil, err
 	}
-	return &FileDownloadProxy{File: reader, Closer: ss, ctx: ctx}, nil
+	return &FileDownloadProxy{File: reader, Closer: ss, ctx: ctx, meter: meter}, nil
 }
 
 func (f *FileDownloadProxy) Read(p []byte) (n int, err error) {
+	if err = f.meter.Check(); err != nil {
+		return 0, err
+	}
 	n, err = f.File.Read(p)
 	if err != nil {
 		return
 	}
 	err = stream.ClientDownloadLimit.WaitN(f.ctx, n)
+	if err == nil {
+		err = f.meter.Wait(n)
+	}
 	return
 }
 
 func (f *FileDownloadProxy) ReadAt(p []byte, off int64) (n int, err error) {
+	if err = f.meter.Check(); err != nil {
+		return 0, err
+	}
 	n, err = f.File.ReadAt(p, off)
 	if err != nil {
 		return
 	}
 	err = stream.ClientDownloadLimit.WaitN(f.ctx, n)
+	if err == nil {
+		err = f.meter.Wait(n)
+	}
 	return
 }
 
@@ -116,6 +135,9 @@ func (o *OsFileInfoAdapter) Sys() any {
 
 func Stat(ctx context.Context, path string) (os.FileInfo, error) {
 	user := ctx.Value(conf.UserKey).(*model.User)
+	if user.IsVirtualRoot(path) {
+		return &OsFileInfoAdapter{obj: &model.Object{Name: "/", IsFolder: true}}, nil
+	}
 	reqPath, err := user.JoinPath(path)
 	if err != nil {
 		return nil, err
@@ -139,6 +161,9 @@ func Stat(ctx context.Context, path string) (os.FileInfo, error) {
 
 func List(ctx context.Context, path string) ([]os.FileInfo, error) {
 	user := ctx.Value(conf.UserKey).(*model.User)
+	if user.IsVirtualRoot(path) {
+		return toFileInfos(user.VirtualRootObjs()), nil
+	}
 	reqPath, err := user.JoinPath(path)
 	if err != nil {
 		return nil, err
@@ -157,9 +182,13 @@ func List(ctx context.Context, path string) ([]os.FileInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	return toFileInfos(objs), nil
+}
+
+func toFileInfos(objs []model.Obj) []os.FileInfo {
 	ret := make([]os.FileInfo, len(objs))
 	for i, obj := range objs {
 		ret[i] = &OsFileInfoAdapter{obj: obj}
 	}
-	return ret, nil
+	return ret
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	ftpserver "github.com/fclairamb/ftpserverlib"
 	"github.com/pkg/errors"
@@ -26,6 +27,9 @@ type FileUploadProxy struct {
 	path   string
 	ctx    context.Context
 	trunc  bool
+	meter  *traffic.Meter
+	// modTime is the modification time of the uploaded file, now if it's zero
+	modTime time.Time
 }
 
 func uploadAuth(ctx context.Context, path string) error {
@@ -37,22 +41,108 @@ func uploadAuth(ctx context.Context, path string) error {
 		}
 	}
 	if !(common.CanAccess(user, meta, path, ctx.Value(conf.MetaPassKey).(string)) &&
-		((user.CanFTPManage() && user.CanWrite()) || common.CanWrite(meta, stdpath.Dir(path)))) {
+		(user.CanFTPManage() || common.MetaCanWrite(meta, stdpath.Dir(path))) &&
+		common.CanWrite(user, meta, stdpath.Dir(path))) {
 		return errs.PermissionDenied
 	}
 	return nil
 }
 
+// uploadMeter counts the upload traffic of the user, it fails if the monthly traffic is exceeded
+func uploadMeter(ctx context.Context) (*traffic.Meter, error) {
+	meter := traffic.NewMeter(ctx, ctx.Value(conf.UserKey).(*model.User), traffic.Upload)
+	if err := meter.Check(); err != nil {
+		return nil, err
+	}
+	return meter, nil
+}
+
 func OpenUpload(ctx context.Context, path string, trunc bool) (*FileUploadProxy, error) {
 	err := uploadAuth(ctx, path)
 	if err != nil {
 		return nil, err
 	}
+	meter, err := uploadMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
 	if err != nil {
 		return nil, err
 	}
-	return &FileUploadProxy{buffer: tmpFile, path: path, ctx: ctx, trunc: trunc}, nil
+	return &FileUploadProxy{buffer: tmpFile, path: path, ctx: ctx, trunc: trunc, meter: meter}, nil
+}
+
+// OpenResumeUpload resumes the upload of path from offset, or from the end of
+// the file if appending. The drivers can only put a whole file, so the
+// uploaded part is copied to the temp file before the rest is written
+func OpenResumeUpload(ctx context.Context, path string, offset int64, appending bool) (*FileUploadProxy, error) {
+	err := uploadAuth(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	meter, err := uploadMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := fs.Get(ctx, path, &fs.GetArgs{})
+	if err != nil {
+		return nil, err
+	}
+	if obj.IsDir() {
+		return nil, errs.NotFile
+	}
+	if appending && offset == 0 {
+		offset = obj.GetSize()
+	}
+	if offset > obj.GetSize() {
+		return nil, errors.Errorf("the offset %d is beyond the size %d of the file", offset, obj.GetSize())
+	}
+	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "file-*")
+	if err != nil {
+		return nil, err
+	}
+	if err = stageUploaded(ctx, path, obj, tmpFile, offset); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return nil, err
+	}
+	return &FileUploadProxy{buffer: tmpFile, path: path, ctx: ctx, trunc: true, meter: meter}, nil
+}
+
+// stageUploaded copies the first n bytes of the uploaded file to w, it's not
+// counted in the traffic of the user
+func stageUploaded(ctx context.Context, path string, obj model.Obj, w io.Writer, n int64) error {
+	if n == 0 {
+		return nil
+	}
+	header, _ := ctx.Value(conf.ProxyHeaderKey).(http.Header)
+	ip, _ := ctx.Value(conf.ClientIPKey).(string)
+	link, _, err := fs.Link(ctx, path, model.LinkArgs{IP: ip, Header: header})
+	if err != nil {
+		return err
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{
+		Obj: obj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		_ = link.Close()
+		return err
+	}
+	defer ss.Close()
+	reader, err := stream.NewReadAtSeeker(ss, 0)
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(w, reader, n)
+	return err
+}
+
+// SetModTime sets the modification time of the file, which is passed to the
+// driver when the upload is done
+func (f *FileUploadProxy) SetModTime(modTime time.Time) {
+	f.modTime = modTime
 }
 
 func (f *FileUploadProxy) Read(p []byte) (n int, err error) {
@@ -60,11 +150,17 @@ func (f *FileUploadProxy) Read(p []byte) (n int, err error) {
 }
 
 func (f *FileUploadProxy) Write(p []byte) (n int, err error) {
+	if err = f.meter.Check(); err != nil {
+		return 0, err
+	}
 	n, err = f.buffer.Write(p)
 	if err != nil {
 		return
 	}
 	err = stream.ClientUploadLimit.WaitN(f.ctx, n)
+	if err == nil {
+		err = f.meter.Wait(n)
+	}
 	return
 }
 
@@ -74,10 +170,12 @@ func (f *FileUploadProxy) Seek(offset int64, whence int) (int64, error) {
 
 func (f *FileUploadProxy) Close() error {
 	dir, name := stdpath.Split(f.path)
-	size, err := f.buffer.Seek(0, io.SeekCurrent)
+	// the file may be written at any offset, so the size isn't the current offset
+	stat, err := f.buffer.Stat()
 	if err != nil {
 		return err
 	}
+	size := stat.Size()
 	if _, err := f.buffer.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
@@ -90,13 +188,17 @@ func (f *FileUploadProxy) Close() error {
 		return err
 	}
 	if f.trunc {
-		_ = fs.Remove(f.ctx, f.path)
+		_ = fs.RemovePermanently(f.ctx, f.path)
+	}
+	modTime := f.modTime
+	if modTime.IsZero() {
+		modTime = time.Now()
 	}
 	s := &stream.FileStream{
 		Obj: &model.Object{
 			Name:     name,
 			Size:     size,
-			Modified: time.Now(),
+			Modified: modTime,
 		},
 		Mimetype:     contentType,
 		WebPutAsTask: true,
@@ -115,6 +217,7 @@ type FileUploadWithLengthProxy struct {
 	pFirst        int
 	pipeWriter    io.WriteCloser
 	errChan       chan error
+	meter         *traffic.Meter
 }
 
 func OpenUploadWithLength(ctx context.Context, path string, trunc bool, length int64) (*FileUploadWithLengthProxy, error) {
@@ -122,10 +225,14 @@ func OpenUploadWithLength(ctx context.Context, path string, trunc bool, length i
 	if err != nil {
 		return nil, err
 	}
+	meter, err := uploadMeter(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if trunc {
-		_ = fs.Remove(ctx, path)
+		_ = fs.RemovePermanently(ctx, path)
 	}
-	return &FileUploadWithLengthProxy{ctx: ctx, path: path, length: length}, nil
+	return &FileUploadWithLengthProxy{ctx: ctx, path: path, length: length, meter: meter}, nil
 }
 
 func (f *FileUploadWithLengthProxy) Read(p []byte) (n int, err error) {
@@ -180,11 +287,17 @@ func (f *FileUploadWithLengthProxy) write(p []byte) (n int, err error) {
 }
 
 func (f *FileUploadWithLengthProxy) Write(p []byte) (n int, err error) {
+	if err = f.meter.Check(); err != nil {
+		return 0, err
+	}
 	n, err = f.write(p)
 	if err != nil {
 		return
 	}
 	err = stream.ClientUploadLimit.WaitN(f.ctx, n)
+	if err == nil {
+		err = f.meter.Wait(n)
+	}
 	return
 }
 
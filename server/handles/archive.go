@@ -120,7 +120,7 @@ func FsArchiveMeta(c *gin.Context) {
 	}
 	s := ""
 	if isEncrypt(meta, reqPath) || setting.GetBool(conf.SignAll) {
-		s = sign.SignArchive(reqPath)
+		s = sign.SignArchiveBy(reqPath, user.ID)
 	}
 	api := "/ae"
 	if ret.DriverProviding {
@@ -291,6 +291,84 @@ func FsArchiveDecompress(c *gin.Context) {
 	})
 }
 
+type ArchiveCompressReq struct {
+	SrcDir      string        `json:"src_dir" form:"src_dir"`
+	DstDir      string        `json:"dst_dir" form:"dst_dir"`
+	Name        StringOrArray `json:"name" form:"name"`
+	ArchiveName string        `json:"archive_name" form:"archive_name"`
+	Format      string        `json:"format" form:"format"`
+	ArchivePass string        `json:"archive_pass" form:"archive_pass"`
+}
+
+func FsArchiveCompress(c *gin.Context) {
+	var req ArchiveCompressReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if len(req.Name) == 0 {
+		common.ErrorStrResp(c, "Empty file names", 400)
+		return
+	}
+	if req.ArchiveName == "" {
+		common.ErrorStrResp(c, "Empty archive name", 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	// compressing reads the srcs and writes the archive to dst
+	srcPaths := make([]string, 0, len(req.Name))
+	for _, name := range req.Name {
+		srcPath, err := user.JoinPath(stdpath.Join(req.SrcDir, name))
+		if err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+		srcMeta, err := op.GetNearestMeta(srcPath)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !common.CanAccess(user, srcMeta, srcPath, "") {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		srcPaths = append(srcPaths, srcPath)
+	}
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMeta(dstDir)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanWrite(user, meta, dstDir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	t, err := fs.ArchiveCompress(c.Request.Context(), srcPaths, dstDir, stdpath.Base(req.ArchiveName), model.ArchiveCompressArgs{
+		Format:   req.Format,
+		Password: req.ArchivePass,
+	})
+	if err != nil {
+		if errors.Is(err, errs.UnknownArchiveFormat) {
+			common.ErrorResp(c, err, 400)
+		} else {
+			common.ErrorResp(c, err, 500)
+		}
+		return
+	}
+	var tasks []task.TaskExtensionInfo
+	if t != nil {
+		tasks = append(tasks, t)
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfos(tasks),
+	})
+}
+
 func ArchiveDown(c *gin.Context) {
 	archiveRawPath := c.Request.Context().Value(conf.PathKey).(string)
 	innerPath := utils.FixAndCleanPath(c.Query("inner"))
@@ -401,3 +479,11 @@ func ArchiveExtensions(c *gin.Context) {
 	}
 	common.SuccessResp(c, ext)
 }
+
+func ArchiveCompressExtensions(c *gin.Context) {
+	var ext []string
+	for key := range tool.CompressTools {
+		ext = append(ext, key)
+	}
+	common.SuccessResp(c, ext)
+}
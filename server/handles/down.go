@@ -13,6 +13,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/net"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
@@ -41,6 +42,7 @@ func Down(c *gin.Context) {
 			Type:     c.Query("type"),
 			Redirect: true,
 		})
+		op.Audit(c.Request.Context(), model.AuditDownload, err, rawPath)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -74,6 +76,7 @@ func Proxy(c *gin.Context) {
 			Header: c.Request.Header,
 			Type:   c.Query("type"),
 		})
+		op.Audit(c.Request.Context(), model.AuditDownload, err, rawPath)
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -0,0 +1,90 @@
+package handles
+
+import (
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/duplicate"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+func FindDuplicates(c *gin.Context) {
+	var req duplicate.FindArgs
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	var err error
+	req.Parent, err = user.JoinPath(req.Parent)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	t, err := duplicate.Find(c.Request.Context(), req)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
+
+func GetDuplicateGroups(c *gin.Context) {
+	tid := c.Query("tid")
+	t, ok := duplicate.FindTaskManager.GetByID(tid)
+	if !ok {
+		common.ErrorStrResp(c, "task not found", 404)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task":   getTaskInfo(t),
+		"groups": t.Groups,
+	})
+}
+
+func ResolveDuplicates(c *gin.Context) {
+	var req duplicate.ResolveArgs
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	joinPath := func(path *string) error {
+		p, err := user.JoinPath(*path)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid path %s", *path)
+		}
+		*path = p
+		return nil
+	}
+	if req.DstDir != "" {
+		if err := joinPath(&req.DstDir); err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+	}
+	for i := range req.Groups {
+		if err := joinPath(&req.Groups[i].Keep); err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+		for j := range req.Groups[i].Others {
+			if err := joinPath(&req.Groups[i].Others[j]); err != nil {
+				common.ErrorResp(c, err, 403)
+				return
+			}
+		}
+	}
+	tasks, failures, err := duplicate.Resolve(c.Request.Context(), req)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks":    getTaskInfos(tasks),
+		"failures": failures,
+	})
+}
@@ -0,0 +1,243 @@
+package handles
+
+import (
+	"io"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/duplicate"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/message"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/schedule"
+	"github.com/OpenListTeam/OpenList/v4/internal/syncjob"
+	"github.com/OpenListTeam/OpenList/v4/internal/task"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	EventTask = "task"
+	EventDir  = "dir"
+)
+
+const (
+	taskWatchInterval = time.Second
+	eventPingInterval = 30 * time.Second
+)
+
+// TaskEvent is sent when the state or the progress of a task changes,
+// or it's removed from the manager
+type TaskEvent struct {
+	// Type is the type of the task, the same as in the path of its api, e.g. copy
+	Type string `json:"type"`
+	TaskInfo
+	Removed   bool `json:"removed,omitempty"`
+	creatorID uint
+}
+
+// DirEvent is sent when the cache of a dir is updated or invalidated,
+// the clients should list it again if they are showing it
+type DirEvent struct {
+	Path string `json:"path"`
+	// the nearest meta of Path, it's got once for all the subscribers
+	meta    *model.Meta
+	metaErr error
+}
+
+// InitEvents publishes the dir and task events for Events
+func InitEvents() {
+	op.RegisterDirChangeHook(func(dirPath string) {
+		if message.HasSubscribers() {
+			go func() {
+				e := DirEvent{Path: dirPath}
+				e.meta, e.metaErr = op.GetNearestMeta(dirPath)
+				if errors.Is(errors.Cause(e.metaErr), errs.MetaNotFound) {
+					e.metaErr = nil
+				}
+				message.Publish(message.Message{Type: EventDir, Content: e})
+			}()
+		}
+	})
+	go watchTasks()
+}
+
+func taskSource[T task.TaskExtensionInfo](typ string, manager task.Manager[T]) func() []TaskEvent {
+	return func() []TaskEvent {
+		tasks := manager.GetAll()
+		events := make([]TaskEvent, 0, len(tasks))
+		for _, t := range tasks {
+			e := TaskEvent{Type: typ, TaskInfo: getTaskInfo(t)}
+			if creator := t.GetCreator(); creator != nil {
+				e.creatorID = creator.ID
+			}
+			events = append(events, e)
+		}
+		return events
+	}
+}
+
+// watchTasks compares the tasks of all managers with the last ones periodically
+// and publishes the changes, the tache managers have no hooks for them
+func watchTasks() {
+	sources := []func() []TaskEvent{
+		taskSource("upload", fs.UploadTaskManager),
+		taskSource("copy", fs.CopyTaskManager),
+		taskSource("move", fs.MoveTaskManager),
+		taskSource("offline_download", tool.DownloadTaskManager),
+		taskSource("offline_download_transfer", tool.TransferTaskManager),
+		taskSource("decompress", fs.ArchiveDownloadTaskManager),
+		taskSource("decompress_upload", fs.ArchiveContentUploadTaskManager),
+		taskSource("compress", fs.ArchiveCompressTaskManager),
+		taskSource("duplicate", duplicate.FindTaskManager),
+		taskSource("sync", syncjob.TaskManager),
+		taskSource("schedule", schedule.RunTaskManager),
+	}
+	var last map[string]TaskEvent
+	ticker := time.NewTicker(taskWatchInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !message.HasSubscribers() {
+			last = nil
+			continue
+		}
+		current := make(map[string]TaskEvent)
+		for _, source := range sources {
+			for _, e := range source() {
+				key := e.Type + "/" + e.ID
+				current[key] = e
+				// the tasks existing before anyone subscribing are compared from the next time
+				if last == nil {
+					continue
+				}
+				if old, ok := last[key]; ok && old.State == e.State && old.Status == e.Status &&
+					old.Progress == e.Progress && old.Error == e.Error {
+					continue
+				}
+				message.Publish(message.Message{Type: EventTask, Content: e})
+			}
+		}
+		for key, e := range last {
+			if _, ok := current[key]; !ok {
+				e.Removed = true
+				message.Publish(message.Message{Type: EventTask, Content: e})
+			}
+		}
+		last = current
+	}
+}
+
+// eventFilter only passes the tasks created by user and the dirs it can
+// access, the paths are converted to the ones seen by the user
+func eventFilter(user *model.User) message.Filter {
+	return func(event message.Message) (message.Message, bool) {
+		switch e := event.Content.(type) {
+		case TaskEvent:
+			return event, user.IsAdmin() || user.ID == e.creatorID
+		case DirEvent:
+			relPath, ok := user.RelPath(e.Path)
+			if !ok {
+				return event, false
+			}
+			if e.metaErr != nil || !common.CanAccess(user, e.meta, e.Path, "") {
+				return event, false
+			}
+			event.Content = DirEvent{Path: relPath}
+			return event, true
+		}
+		return event, false
+	}
+}
+
+var eventUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// EventsTicket issues a ticket to connect Events without the Authorization
+// header, it's used once in common.StreamTicketExpiration
+func EventsTicket(c *gin.Context) {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	apiToken, _ := c.Request.Context().Value(conf.APITokenKey).(string)
+	common.SuccessResp(c, gin.H{
+		"ticket":     common.IssueStreamTicket(user, apiToken),
+		"expires_in": int(common.StreamTicketExpiration.Seconds()),
+	})
+}
+
+// Events streams the task and dir events to the user, as WebSocket messages
+// if it's requested to upgrade, otherwise as Server-Sent Events. the events
+// only contain the changes, the current tasks should be got from the task api
+func Events(c *gin.Context) {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		eventsWebsocket(c, user)
+		return
+	}
+	events, cancel := message.Subscribe(eventFilter(user))
+	defer cancel()
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+	c.Writer.Flush()
+	ping := time.NewTicker(eventPingInterval)
+	defer ping.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-ping.C:
+			_, err := w.Write([]byte(": ping\n\n"))
+			return err == nil
+		case e := <-events:
+			c.SSEvent(e.Type, e.Content)
+			return true
+		}
+	})
+}
+
+func eventsWebsocket(c *gin.Context, user *model.User) {
+	conn, err := eventUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the error response has been written by the upgrader
+		log.Debugf("failed upgrade events websocket: %+v", err)
+		return
+	}
+	defer conn.Close()
+	events, cancel := message.Subscribe(eventFilter(user))
+	defer cancel()
+	// the messages from the client are discarded, reading is needed to handle
+	// the control messages and find out the closing of the connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	ping := time.NewTicker(eventPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ping.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+		case e := <-events:
+			err = conn.WriteJSON(e)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
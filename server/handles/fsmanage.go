@@ -35,19 +35,17 @@ func FsMkdir(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
-	if !user.CanWrite() {
-		meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
-		if err != nil {
-			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
-				common.ErrorResp(c, err, 500, true)
-				return
-			}
-		}
-		if !common.CanWrite(meta, reqPath) {
-			common.ErrorResp(c, errs.PermissionDenied, 403)
+	meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
 			return
 		}
 	}
+	if !common.CanWrite(user, meta, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	if err := fs.MakeDir(c.Request.Context(), reqPath); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -55,6 +53,17 @@ func FsMkdir(c *gin.Context) {
 	common.SuccessResp(c)
 }
 
+// canOperateAll checks operation on every entry of names in dir, since the acl
+// rules of the entries may differ from the rules of dir
+func canOperateAll(user *model.User, dir string, names []string, operation int32, permitted bool) bool {
+	for _, name := range names {
+		if !common.CanOperate(user, stdpath.Join(dir, name), operation, permitted) {
+			return false
+		}
+	}
+	return true
+}
+
 type MoveCopyReq struct {
 	SrcDir    string   `json:"src_dir"`
 	DstDir    string   `json:"dst_dir"`
@@ -73,10 +82,6 @@ func FsMove(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	if !user.CanMove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -87,6 +92,11 @@ func FsMove(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !canOperateAll(user, srcDir, req.Names, model.ACLMove, user.CanMove()) ||
+		!common.CanOperate(user, dstDir, model.ACLWrite, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
 	if !req.Overwrite {
 		for _, name := range req.Names {
@@ -135,10 +145,6 @@ func FsCopy(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	if !user.CanCopy() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -149,6 +155,11 @@ func FsCopy(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !canOperateAll(user, srcDir, req.Names, model.ACLCopy, user.CanCopy()) ||
+		!common.CanOperate(user, dstDir, model.ACLWrite, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
 	if !req.Overwrite {
 		for _, name := range req.Names {
@@ -199,15 +210,15 @@ func FsRename(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqPath, err := user.JoinPath(req.Path)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !common.CanOperate(user, reqPath, model.ACLRename, user.CanRename()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	if !req.Overwrite {
 		dstPath := stdpath.Join(stdpath.Dir(reqPath), req.Name)
 		if dstPath != reqPath {
@@ -240,15 +251,15 @@ func FsRemove(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqDir, err := user.JoinPath(req.Dir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !canOperateAll(user, reqDir, req.Names, model.ACLRemove, user.CanRemove()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	for _, name := range req.Names {
 		err := fs.Remove(c.Request.Context(), stdpath.Join(reqDir, name))
 		if err != nil {
@@ -272,15 +283,15 @@ func FsRemoveEmptyDirectory(c *gin.Context) {
 	}
 
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !common.CanOperate(user, srcDir, model.ACLRemove, user.CanRemove()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
 	meta, err := op.GetNearestMeta(srcDir)
 	if err != nil {
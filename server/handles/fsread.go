@@ -64,6 +64,15 @@ func FsList(c *gin.Context) {
 	}
 	req.Validate()
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if user.IsVirtualRoot(req.Path) {
+		total, objs := pagination(user.VirtualRootObjs(), &req.PageReq)
+		common.SuccessResp(c, FsListResp{
+			Content:  toObjsResp(user, objs, "/", false),
+			Total:    int64(total),
+			Provider: "virtual",
+		})
+		return
+	}
 	reqPath, err := user.JoinPath(req.Path)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -81,7 +90,7 @@ func FsList(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
-	if !user.CanWrite() && !common.CanWrite(meta, reqPath) && req.Refresh {
+	if !common.CanWrite(user, meta, reqPath) && req.Refresh {
 		common.ErrorStrResp(c, "Refresh without permission", 403)
 		return
 	}
@@ -97,11 +106,11 @@ func FsList(c *gin.Context) {
 		provider = storage.GetStorage().Driver
 	}
 	common.SuccessResp(c, FsListResp{
-		Content:  toObjsResp(objs, reqPath, isEncrypt(meta, reqPath)),
+		Content:  toObjsResp(user, objs, reqPath, isEncrypt(meta, reqPath)),
 		Total:    int64(total),
 		Readme:   getReadme(meta, reqPath),
 		Header:   getHeader(meta, reqPath),
-		Write:    user.CanWrite() || common.CanWrite(meta, reqPath),
+		Write:    common.CanWrite(user, meta, reqPath),
 		Provider: provider,
 	})
 }
@@ -113,6 +122,10 @@ func FsDirs(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !req.ForceRoot && user.IsVirtualRoot(req.Path) {
+		common.SuccessResp(c, filterDirs(user.VirtualRootObjs()))
+		return
+	}
 	reqPath := req.Path
 	if req.ForceRoot {
 		if !user.IsAdmin() {
@@ -207,7 +220,7 @@ func pagination(objs []model.Obj, req *model.PageReq) (int, []model.Obj) {
 	return total, objs[start:end]
 }
 
-func toObjsResp(objs []model.Obj, parent string, encrypt bool) []ObjResp {
+func toObjsResp(user *model.User, objs []model.Obj, parent string, encrypt bool) []ObjResp {
 	var resp []ObjResp
 	for _, obj := range objs {
 		thumb, _ := model.GetThumb(obj)
@@ -221,7 +234,7 @@ func toObjsResp(objs []model.Obj, parent string, encrypt bool) []ObjResp {
 			Created:     obj.CreateTime(),
 			HashInfoStr: obj.GetHash().String(),
 			HashInfo:    obj.GetHash().Export(),
-			Sign:        common.Sign(obj, parent, encrypt),
+			Sign:        common.Sign(user, obj, parent, encrypt),
 			Thumb:       thumb,
 			Type:        utils.GetObjType(obj.GetName(), obj.IsDir()),
 		})
@@ -250,6 +263,17 @@ func FsGet(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if user.IsVirtualRoot(req.Path) {
+		common.SuccessResp(c, FsGetResp{
+			ObjResp: ObjResp{
+				Name:  "root",
+				IsDir: true,
+				Type:  utils.GetObjType("root", true),
+			},
+			Provider: "virtual",
+		})
+		return
+	}
 	reqPath, err := user.JoinPath(req.Path)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -287,7 +311,7 @@ func FsGet(c *gin.Context) {
 		if storage.Config().MustProxy() || storage.GetStorage().WebProxy {
 			query := ""
 			if isEncrypt(meta, reqPath) || setting.GetBool(conf.SignAll) {
-				query = "?sign=" + sign.Sign(reqPath)
+				query = "?sign=" + sign.SignBy(reqPath, user.ID)
 			}
 			if storage.GetStorage().DownProxyUrl != "" {
 				rawURL = fmt.Sprintf("%s%s?sign=%s",
@@ -339,7 +363,7 @@ func FsGet(c *gin.Context) {
 			Created:     obj.CreateTime(),
 			HashInfoStr: obj.GetHash().String(),
 			HashInfo:    obj.GetHash().Export(),
-			Sign:        common.Sign(obj, parentPath, isEncrypt(meta, reqPath)),
+			Sign:        common.Sign(user, obj, parentPath, isEncrypt(meta, reqPath)),
 			Type:        utils.GetFileType(obj.GetName()),
 			Thumb:       thumb,
 		},
@@ -347,7 +371,7 @@ func FsGet(c *gin.Context) {
 		Readme:   getReadme(meta, reqPath),
 		Header:   getHeader(meta, reqPath),
 		Provider: provider,
-		Related:  toObjsResp(related, parentPath, isEncrypt(parentMeta, parentPath)),
+		Related:  toObjsResp(user, related, parentPath, isEncrypt(parentMeta, parentPath)),
 	})
 }
 
@@ -0,0 +1,222 @@
+package handles
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type FTPCertAddReq struct {
+	Title string `json:"title" binding:"required"`
+	Cert  string `json:"cert" binding:"required"`
+}
+
+func AddMyFTPCert(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req FTPCertAddReq
+	if err := c.ShouldBind(&req); err != nil || req.Title == "" {
+		common.ErrorStrResp(c, "request invalid", 400)
+		return
+	}
+	cert := &model.FTPCert{
+		Title:   req.Title,
+		CertStr: strings.TrimSpace(req.Cert),
+		UserId:  userObj.ID,
+	}
+	err, parsed := op.CreateFTPCert(cert)
+	if !parsed {
+		common.ErrorStrResp(c, "provided cert invalid", 400)
+		return
+	} else if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, cert)
+}
+
+func ListMyFTPCerts(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	listFTPCerts(c, userObj.ID)
+}
+
+func DeleteMyFTPCert(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "id format invalid", 400)
+		return
+	}
+	cert, err := op.GetFTPCertByIdAndUserId(uint(id), userObj.ID)
+	if err != nil {
+		common.ErrorStrResp(c, "failed to get ftp cert", 404)
+		return
+	}
+	if err = op.DeleteFTPCertById(cert.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListFTPCerts(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Query("uid"))
+	if err != nil {
+		common.ErrorStrResp(c, "user id format invalid", 400)
+		return
+	}
+	listFTPCerts(c, uint(userId))
+}
+
+func DeleteFTPCert(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "id format invalid", 400)
+		return
+	}
+	err = op.DeleteFTPCertById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func listFTPCerts(c *gin.Context, userId uint) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	certs, total, err := op.GetFTPCertsByUserId(userId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: certs,
+		Total:   total,
+	})
+}
+
+type FTPPasswordAddReq struct {
+	Title string `json:"title" binding:"required"`
+}
+
+// AddMyFTPPassword generates an ftp password of the current user, the
+// password is only returned here and can't be read again
+func AddMyFTPPassword(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	var req FTPPasswordAddReq
+	if err := c.ShouldBind(&req); err != nil || req.Title == "" {
+		common.ErrorStrResp(c, "request invalid", 400)
+		return
+	}
+	pwd := &model.FTPPassword{
+		Title:  req.Title,
+		UserId: userObj.ID,
+	}
+	password, err := op.CreateFTPPassword(pwd)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"info":     pwd,
+		"password": password,
+	})
+}
+
+func ListMyFTPPasswords(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	listFTPPasswords(c, userObj.ID)
+}
+
+func DeleteMyFTPPassword(c *gin.Context) {
+	userObj, ok := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !ok || userObj.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "id format invalid", 400)
+		return
+	}
+	pwd, err := op.GetFTPPasswordByIdAndUserId(uint(id), userObj.ID)
+	if err != nil {
+		common.ErrorStrResp(c, "failed to get ftp password", 404)
+		return
+	}
+	if err = op.DeleteFTPPasswordById(pwd.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListFTPPasswords(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Query("uid"))
+	if err != nil {
+		common.ErrorStrResp(c, "user id format invalid", 400)
+		return
+	}
+	listFTPPasswords(c, uint(userId))
+}
+
+func DeleteFTPPassword(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "id format invalid", 400)
+		return
+	}
+	err = op.DeleteFTPPasswordById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func listFTPPasswords(c *gin.Context, userId uint) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	pwds, total, err := op.GetFTPPasswordsByUserId(userId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: pwds,
+		Total:   total,
+	})
+}
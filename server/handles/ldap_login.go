@@ -11,6 +11,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils/random"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
@@ -95,6 +96,7 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 		utils.Log.Errorf("Failed to auth. %v", err)
 		common.ErrorResp(c, err, 400)
 		model.LoginCache.Set(ip, count+1)
+		webhook.EmitLoginFailed(req.Username, ip, "ldap")
 		return
 	} else {
 		utils.Log.Infof("Auth successful username:%s", req.Username)
@@ -112,7 +114,7 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 	}
 
 	// generate token
-	token, err := common.GenerateToken(user)
+	token, err := common.GenerateToken(c, user)
 	if err != nil {
 		common.ErrorResp(c, err, 400, true)
 		return
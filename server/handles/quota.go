@@ -0,0 +1,72 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListQuotas(c *gin.Context) {
+	quotas, err := op.GetQuotas()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, quotas)
+}
+
+// GetQuota returns the quota of a user, the data is null if the user has no quota
+func GetQuota(c *gin.Context) {
+	uid, err := strconv.Atoi(c.Query("uid"))
+	if err != nil {
+		common.ErrorStrResp(c, "user id format invalid", 400)
+		return
+	}
+	q, err := op.GetUserQuota(uint(uid))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, q)
+}
+
+// SetQuota sets the limits of the quota of a user, the usage is kept
+func SetQuota(c *gin.Context) {
+	var req model.Quota
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.SetUserQuota(&req); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, req)
+}
+
+func DeleteQuota(c *gin.Context) {
+	uid, err := strconv.Atoi(c.Query("uid"))
+	if err != nil {
+		common.ErrorStrResp(c, "user id format invalid", 400)
+		return
+	}
+	if err = op.DeleteUserQuota(uint(uid)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func GetMyQuota(c *gin.Context) {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	q, err := op.GetUserQuota(user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, q)
+}
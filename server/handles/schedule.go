@@ -0,0 +1,126 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/schedule"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListSchedules(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	schedules, total, err := schedule.GetAll(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: schedules,
+		Total:   total,
+	})
+}
+
+func GetSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	s, err := schedule.Get(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, s)
+}
+
+// joinSchedulePaths converts the paths of req relative to the base path of user,
+// the mount path of the storage to reload is kept as it is
+func joinSchedulePaths(c *gin.Context, req *model.Schedule) bool {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	var err error
+	if req.SrcPath != "" && req.Type != model.ScheduleStorageReload {
+		if req.SrcPath, err = user.JoinPath(req.SrcPath); err != nil {
+			common.ErrorResp(c, err, 403)
+			return false
+		}
+	}
+	if req.DstPath != "" {
+		if req.DstPath, err = user.JoinPath(req.DstPath); err != nil {
+			common.ErrorResp(c, err, 403)
+			return false
+		}
+	}
+	return true
+}
+
+func CreateSchedule(c *gin.Context) {
+	var req model.Schedule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !joinSchedulePaths(c, &req) {
+		return
+	}
+	if err := schedule.Create(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"id": req.ID,
+	})
+}
+
+func UpdateSchedule(c *gin.Context) {
+	var req model.Schedule
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !joinSchedulePaths(c, &req) {
+		return
+	}
+	if err := schedule.Update(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = schedule.Delete(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func RunSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	t, err := schedule.Run(c.Request.Context(), uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
@@ -2,7 +2,6 @@ package handles
 
 import (
 	"path"
-	"strings"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
@@ -22,7 +21,6 @@ type SearchReq struct {
 
 type SearchResp struct {
 	model.SearchNode
-	Type int `json:"type"`
 }
 
 func Search(c *gin.Context) {
@@ -35,10 +33,15 @@ func Search(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	req.Parent, err = user.JoinPath(req.Parent)
-	if err != nil {
-		common.ErrorResp(c, err, 400)
-		return
+	if user.IsVirtualRoot(req.Parent) {
+		// search everywhere, the nodes out of the base paths are filtered out
+		req.Parent = "/"
+	} else {
+		req.Parent, err = user.JoinPath(req.Parent)
+		if err != nil {
+			common.ErrorResp(c, err, 400)
+			return
+		}
 	}
 	if err := req.Validate(); err != nil {
 		common.ErrorResp(c, err, 400)
@@ -51,7 +54,8 @@ func Search(c *gin.Context) {
 	}
 	var filteredNodes []model.SearchNode
 	for _, node := range nodes {
-		if !strings.HasPrefix(node.Parent, user.BasePath) {
+		relParent, ok := user.RelPath(node.Parent)
+		if !ok {
 			continue
 		}
 		meta, err := op.GetNearestMeta(node.Parent)
@@ -61,6 +65,10 @@ func Search(c *gin.Context) {
 		if !common.CanAccess(user, meta, path.Join(node.Parent, node.Name), req.Password) {
 			continue
 		}
+		// the actual paths can't be mapped back by the clients if the root is virtual
+		if user.HasVirtualRoot() {
+			node.Parent = relParent
+		}
 		filteredNodes = append(filteredNodes, node)
 	}
 	common.SuccessResp(c, common.PageResp{
@@ -70,8 +78,7 @@ func Search(c *gin.Context) {
 }
 
 func nodeToSearchResp(node model.SearchNode) SearchResp {
-	return SearchResp{
-		SearchNode: node,
-		Type:       utils.GetObjType(node.Name, node.IsDir),
-	}
+	// the nodes indexed by the old versions don't have a type
+	node.Type = utils.GetObjType(node.Name, node.IsDir)
+	return SearchResp{SearchNode: node}
 }
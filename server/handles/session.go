@@ -0,0 +1,51 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/session"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListSessions(c *gin.Context) {
+	uid, err := strconv.Atoi(c.Query("uid"))
+	if err != nil {
+		common.ErrorStrResp(c, "user id format invalid", 400)
+		return
+	}
+	sessions, err := session.ListByUser(uint(uid))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, sessions)
+}
+
+// DeleteSession revokes a session, the token of it is rejected from now on
+func DeleteSession(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		common.ErrorStrResp(c, "id is required", 400)
+		return
+	}
+	if err := session.Delete(id); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// DeleteUserSessions revokes all sessions of a user
+func DeleteUserSessions(c *gin.Context) {
+	uid, err := strconv.Atoi(c.Query("uid"))
+	if err != nil {
+		common.ErrorStrResp(c, "user id format invalid", 400)
+		return
+	}
+	if err = session.DeleteByUser(uint(uid)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -19,7 +19,9 @@ import (
 func ResetToken(c *gin.Context) {
 	token := random.Token()
 	item := model.SettingItem{Key: "token", Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE}
-	if err := op.SaveSettingItem(&item); err != nil {
+	err := op.SaveSettingItem(&item)
+	op.Audit(c.Request.Context(), model.AuditSettingResetToken, err, item.Key)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -53,7 +55,13 @@ func SaveSettings(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.SaveSettingItems(req); err != nil {
+	keys := make([]string, len(req))
+	for i := range req {
+		keys[i] = req[i].Key
+	}
+	err := op.SaveSettingItems(req)
+	op.Audit(c.Request.Context(), model.AuditSettingSave, err, strings.Join(keys, ","))
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -135,7 +143,9 @@ func DefaultSettings(c *gin.Context) {
 
 func DeleteSetting(c *gin.Context) {
 	key := c.Query("key")
-	if err := op.DeleteSettingItemByKey(key); err != nil {
+	err := op.DeleteSettingItemByKey(key)
+	op.Audit(c.Request.Context(), model.AuditSettingDelete, err, key)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
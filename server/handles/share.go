@@ -0,0 +1,283 @@
+package handles
+
+import (
+	"io"
+	"net/http"
+	stdpath "path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ShareGet lists a folder of the share, or downloads a file of it through Down
+func ShareGet(c *gin.Context) {
+	reqPath := c.Request.Context().Value(conf.PathKey).(string)
+	s := c.Request.Context().Value(conf.ShareKey).(*model.Share)
+	obj, err := fs.Get(c.Request.Context(), reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if obj.IsDir() {
+		shareList(c, s, reqPath)
+		return
+	}
+	counted := isNewDownload(c)
+	if s.MaxDownloads > 0 {
+		shareProxy(c, s, reqPath, counted)
+		return
+	}
+	Down(c)
+	// the errors of Down abort the request
+	if counted && !c.IsAborted() {
+		if _, err := op.IncreaseShareDownloads(s.ID); err != nil {
+			log.Errorf("failed count the download of share [%s]: %+v", s.ID, err)
+		}
+	}
+}
+
+// isNewDownload reports whether the request starts a download, so that the
+// partial requests of the media players and download managers are counted once
+// by the one from the start
+func isNewDownload(c *gin.Context) bool {
+	if c.Request.Method != http.MethodGet {
+		return false
+	}
+	r := strings.TrimSpace(c.GetHeader("Range"))
+	return r == "" || strings.HasPrefix(r, "bytes=0-")
+}
+
+// shareProxy serves the file of a share with limited downloads through the
+// server, since a redirected link could be downloaded again without counting.
+// The download is counted after the link is resolved
+func shareProxy(c *gin.Context, s *model.Share, reqPath string, counted bool) {
+	storage, err := fs.GetStorage(reqPath, &fs.GetStoragesArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	link, file, err := fs.Link(c.Request.Context(), reqPath, model.LinkArgs{
+		Header: c.Request.Header,
+		Type:   c.Query("type"),
+	})
+	op.Audit(c.Request.Context(), model.AuditDownload, err, reqPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if counted {
+		ok, err := op.IncreaseShareDownloads(s.ID)
+		if err != nil {
+			_ = link.Close()
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+		if !ok {
+			_ = link.Close()
+			common.ErrorStrResp(c, "the download limit of the share has been reached", 403)
+			return
+		}
+	}
+	proxy(c, link, file, storage.GetStorage().ProxyRange)
+}
+
+func shareList(c *gin.Context, s *model.Share, reqPath string) {
+	var req model.PageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	meta, _ := c.Request.Context().Value(conf.MetaKey).(*model.Meta)
+	objs, err := fs.List(c.Request.Context(), reqPath, &fs.ListArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if c.Request.Method == "GET" {
+		if err = op.IncreaseShareViews(s.ID); err != nil {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	total, objs := pagination(objs, &req)
+	common.SuccessResp(c, FsListResp{
+		Content: toObjsResp(user, objs, reqPath, false),
+		Total:   int64(total),
+		Readme:  getReadme(meta, reqPath),
+		Header:  getHeader(meta, reqPath),
+		Write:   user.CanWrite() && common.CanOperate(user, reqPath, model.ACLWrite, true),
+	})
+}
+
+// ShareUpload uploads the body to the path of a share that allows uploading,
+// the existing files are never overwritten
+func ShareUpload(c *gin.Context) {
+	defer func() {
+		if n, _ := io.ReadFull(c.Request.Body, []byte{0}); n == 1 {
+			_, _ = utils.CopyWithBuffer(io.Discard, c.Request.Body)
+		}
+		_ = c.Request.Body.Close()
+	}()
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	reqPath := c.Request.Context().Value(conf.PathKey).(string)
+	// the write flags of metas don't apply to the visitors
+	if !user.CanWrite() || !common.CanOperate(user, stdpath.Dir(reqPath), model.ACLWrite, true) {
+		common.ErrorStrResp(c, "the share doesn't allow uploading", 403)
+		return
+	}
+	if utils.PathEqual(reqPath, user.BasePath) {
+		common.ErrorStrResp(c, "file name is required", 400)
+		return
+	}
+	if res, _ := fs.Get(c.Request.Context(), reqPath, &fs.GetArgs{NoLog: true}); res != nil {
+		common.ErrorStrResp(c, "file exists", 403)
+		return
+	}
+	if c.Request.ContentLength < 0 {
+		common.ErrorStrResp(c, "Content-Length is required", 411)
+		return
+	}
+	dir, name := stdpath.Split(reqPath)
+	mimetype := c.GetHeader("Content-Type")
+	if len(mimetype) == 0 {
+		mimetype = utils.GetMimeType(name)
+	}
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     c.Request.ContentLength,
+			Modified: getLastModified(c),
+		},
+		Reader:   c.Request.Body,
+		Mimetype: mimetype,
+	}
+	if err := fs.PutDirectly(c.Request.Context(), dir, s, true); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// ShareReq is a share to create or update, the password is kept if it's absent
+type ShareReq struct {
+	model.Share
+	Password *string `json:"password"`
+}
+
+// ShareResp is a share with its password hidden
+type ShareResp struct {
+	model.Share
+	HasPassword bool `json:"has_password"`
+}
+
+func toShareResp(s model.Share) ShareResp {
+	return ShareResp{Share: s, HasPassword: s.Password != ""}
+}
+
+func toSharesResp(shares []model.Share) []ShareResp {
+	resp := make([]ShareResp, 0, len(shares))
+	for _, s := range shares {
+		resp = append(resp, toShareResp(s))
+	}
+	return resp
+}
+
+func ListMyShares(c *gin.Context) {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "user invalid", 401)
+		return
+	}
+	shares, err := op.GetSharesByUser(user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, toSharesResp(shares))
+}
+
+func CreateShare(c *gin.Context) {
+	var req ShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if _, err = fs.Get(c.Request.Context(), reqPath, &fs.GetArgs{NoLog: true}); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Password != nil {
+		req.Share.Password = *req.Password
+	}
+	if err = op.CreateShare(user, &req.Share); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, toShareResp(req.Share))
+}
+
+func UpdateShare(c *gin.Context) {
+	var req ShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if req.Password != nil {
+		req.Share.Password = *req.Password
+	}
+	if err := op.UpdateShare(user, &req.Share, req.Password == nil); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, toShareResp(req.Share))
+}
+
+func DeleteShare(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		common.ErrorStrResp(c, "id is required", 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if err := op.DeleteShare(user, id); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// ListShares lists the shares of all users with their access stats
+func ListShares(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	shares, total, err := op.GetShares(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: toSharesResp(shares),
+		Total:   total,
+	})
+}
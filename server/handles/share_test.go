@@ -0,0 +1,32 @@
+package handles
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIsNewDownload(t *testing.T) {
+	tests := []struct {
+		method, rng string
+		want        bool
+	}{
+		{"GET", "", true},
+		{"GET", "bytes=0-", true},
+		{"GET", "bytes=0-1023", true},
+		{"GET", "bytes=1024-", false},
+		{"GET", "bytes=1024-2047", false},
+		{"HEAD", "", false},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(tt.method, "/s/id/a.mp4", nil)
+		if tt.rng != "" {
+			c.Request.Header.Set("Range", tt.rng)
+		}
+		if got := isNewDownload(c); got != tt.want {
+			t.Errorf("isNewDownload(%s %q) = %v, want %v", tt.method, tt.rng, got, tt.want)
+		}
+	}
+}
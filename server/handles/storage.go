@@ -38,7 +38,9 @@ func CreateStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if id, err := op.CreateStorage(c.Request.Context(), req); err != nil {
+	id, err := op.CreateStorage(c.Request.Context(), req)
+	op.Audit(c.Request.Context(), model.AuditStorageCreate, err, req.MountPath)
+	if err != nil {
 		common.ErrorWithDataResp(c, err, 500, gin.H{
 			"id": id,
 		}, true)
@@ -55,7 +57,9 @@ func UpdateStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.UpdateStorage(c.Request.Context(), req); err != nil {
+	err := op.UpdateStorage(c.Request.Context(), req)
+	op.Audit(c.Request.Context(), model.AuditStorageUpdate, err, req.MountPath)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -69,7 +73,14 @@ func DeleteStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DeleteStorageById(c.Request.Context(), uint(id)); err != nil {
+	storage, err := db.GetStorageById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	err = op.DeleteStorageById(c.Request.Context(), uint(id))
+	op.Audit(c.Request.Context(), model.AuditStorageDelete, err, storage.MountPath)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -83,7 +94,14 @@ func DisableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DisableStorage(c.Request.Context(), uint(id)); err != nil {
+	storage, err := db.GetStorageById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	err = op.DisableStorage(c.Request.Context(), uint(id))
+	op.Audit(c.Request.Context(), model.AuditStorageDisable, err, storage.MountPath)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -97,7 +115,14 @@ func EnableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.EnableStorage(c.Request.Context(), uint(id)); err != nil {
+	storage, err := db.GetStorageById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	err = op.EnableStorage(c.Request.Context(), uint(id))
+	op.Audit(c.Request.Context(), model.AuditStorageEnable, err, storage.MountPath)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -0,0 +1,141 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/syncjob"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListSyncJobs(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	jobs, total, err := syncjob.GetJobs(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: jobs,
+		Total:   total,
+	})
+}
+
+func GetSyncJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	job, err := syncjob.GetJob(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, job)
+}
+
+// joinSyncPaths converts the paths of req relative to the base path of user
+func joinSyncPaths(c *gin.Context, req *model.SyncJob) bool {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	var err error
+	if req.SrcPath, err = user.JoinPath(req.SrcPath); err != nil {
+		common.ErrorResp(c, err, 403)
+		return false
+	}
+	if req.DstPath, err = user.JoinPath(req.DstPath); err != nil {
+		common.ErrorResp(c, err, 403)
+		return false
+	}
+	return true
+}
+
+func CreateSyncJob(c *gin.Context) {
+	var req model.SyncJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !joinSyncPaths(c, &req) {
+		return
+	}
+	if err := syncjob.CreateJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"id": req.ID,
+	})
+}
+
+func UpdateSyncJob(c *gin.Context) {
+	var req model.SyncJob
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !joinSyncPaths(c, &req) {
+		return
+	}
+	if err := syncjob.UpdateJob(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteSyncJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = syncjob.DeleteJob(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type RunSyncJobReq struct {
+	ID     uint `json:"id" form:"id" binding:"required"`
+	DryRun bool `json:"dry_run" form:"dry_run"`
+}
+
+func RunSyncJob(c *gin.Context) {
+	var req RunSyncJobReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	t, err := syncjob.Run(c.Request.Context(), req.ID, req.DryRun)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
+
+// GetSyncReport returns the changes found by a sync task, the result
+// of each of them is included if it's not a dry run
+func GetSyncReport(c *gin.Context) {
+	tid := c.Query("tid")
+	t, ok := syncjob.TaskManager.GetByID(tid)
+	if !ok {
+		common.ErrorStrResp(c, "task not found", 404)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task":    getTaskInfo(t),
+		"actions": t.Actions,
+	})
+}
@@ -8,8 +8,11 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/task"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/duplicate"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/offline_download/tool"
+	"github.com/OpenListTeam/OpenList/v4/internal/schedule"
+	"github.com/OpenListTeam/OpenList/v4/internal/syncjob"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/OpenListTeam/tache"
@@ -225,4 +228,8 @@ func SetupTaskRoute(g *gin.RouterGroup) {
 	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
 	taskRoute(g.Group("/decompress"), fs.ArchiveDownloadTaskManager)
 	taskRoute(g.Group("/decompress_upload"), fs.ArchiveContentUploadTaskManager)
+	taskRoute(g.Group("/compress"), fs.ArchiveCompressTaskManager)
+	taskRoute(g.Group("/duplicate"), duplicate.FindTaskManager)
+	taskRoute(g.Group("/sync"), syncjob.TaskManager)
+	taskRoute(g.Group("/schedule"), schedule.RunTaskManager)
 }
@@ -0,0 +1,81 @@
+package handles
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type TrafficResp struct {
+	Day   []model.Traffic     `json:"day"`
+	Month []model.Traffic     `json:"month"`
+	Limit *model.TrafficLimit `json:"limit,omitempty"`
+}
+
+// getTraffics returns the traffic of the day and the month of the date query,
+// which defaults to today
+func getTraffics(c *gin.Context, userID uint) (*TrafficResp, bool) {
+	date := time.Now()
+	if d := c.Query("date"); d != "" {
+		var err error
+		date, err = time.ParseInLocation(model.TrafficDayFormat, d, time.Local)
+		if err != nil {
+			common.ErrorStrResp(c, "date format invalid", 400)
+			return nil, false
+		}
+	}
+	// the pending traffic is flushed so that the result is up to date
+	if err := traffic.Flush(); err != nil {
+		log.Errorf("failed flush traffic: %+v", err)
+	}
+	day, month := model.DayPeriod(date), model.MonthPeriod(date)
+	traffics, err := op.GetTraffics(userID, day, month)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return nil, false
+	}
+	resp := &TrafficResp{Day: []model.Traffic{}, Month: []model.Traffic{}}
+	for _, t := range traffics {
+		if t.Period == day {
+			resp.Day = append(resp.Day, t)
+		} else {
+			resp.Month = append(resp.Month, t)
+		}
+	}
+	return resp, true
+}
+
+// ListTraffics returns the traffic of all users, or of a user if uid is given
+func ListTraffics(c *gin.Context) {
+	var uid int
+	if s := c.Query("uid"); s != "" {
+		var err error
+		if uid, err = strconv.Atoi(s); err != nil {
+			common.ErrorStrResp(c, "user id format invalid", 400)
+			return
+		}
+	}
+	resp, ok := getTraffics(c, uint(uid))
+	if !ok {
+		return
+	}
+	common.SuccessResp(c, resp)
+}
+
+func GetMyTraffic(c *gin.Context) {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	resp, ok := getTraffics(c, user.ID)
+	if !ok {
+		return
+	}
+	limit := op.GetTrafficLimit(user)
+	resp.Limit = &limit
+	common.SuccessResp(c, resp)
+}
@@ -0,0 +1,152 @@
+package handles
+
+import (
+	"archive/zip"
+	"context"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ZipDown streams a zip of the directory at path, or of the entries
+// selected by the name query of it, without a temp file
+func ZipDown(c *gin.Context) {
+	rawPath := c.Request.Context().Value(conf.PathKey).(string)
+	rootMeta, _ := c.Request.Context().Value(conf.MetaKey).(*model.Meta)
+	// the entries are checked for the user who made the sign, or the guest if
+	// rawPath needs no sign
+	user, ok := c.Request.Context().Value(conf.SignerKey).(*model.User)
+	if !ok {
+		guest, err := op.GetGuest()
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+		user = guest
+	}
+	// the sign of rawPath has been verified, so the password of its meta is known,
+	// but the entries protected by other metas are skipped
+	canAccess := func(reqPath string) bool {
+		meta, err := op.GetNearestMeta(reqPath)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return false
+		}
+		password := ""
+		if meta != nil && rootMeta != nil && meta.Path == rootMeta.Path {
+			password = meta.Password
+		}
+		return common.CanAccess(user, meta, reqPath, password)
+	}
+	ctx := c.Request.Context()
+	dirObj, err := fs.Get(ctx, rawPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if !dirObj.IsDir() {
+		common.ErrorResp(c, errs.NotFolder, 400)
+		return
+	}
+	var srcPaths []string
+	if names := c.QueryArray("name"); len(names) > 0 {
+		for _, name := range names {
+			srcPath := stdpath.Join(rawPath, name)
+			if !utils.IsSubPath(rawPath, srcPath) || utils.PathEqual(srcPath, rawPath) {
+				common.ErrorStrResp(c, "invalid name: "+name, 400)
+				return
+			}
+			srcPaths = append(srcPaths, srcPath)
+		}
+	} else {
+		srcPaths = append(srcPaths, rawPath)
+	}
+
+	zipName := dirObj.GetName()
+	if zipName == "" || rawPath == "/" {
+		zipName = "root"
+	}
+	c.Header("Content-Disposition", utils.GenerateContentDisposition(zipName+".zip"))
+	c.Header("Content-Type", "application/zip")
+	c.Status(200)
+	if c.Request.Method == "HEAD" {
+		return
+	}
+	zw := zip.NewWriter(c.Writer)
+	for _, srcPath := range srcPaths {
+		srcObj := dirObj
+		if srcPath != rawPath {
+			srcObj, err = fs.Get(ctx, srcPath, &fs.GetArgs{NoLog: true})
+			if err != nil {
+				log.Warnf("zip down: failed get [%s]: %+v", srcPath, err)
+				continue
+			}
+		}
+		err = fs.WalkFS(ctx, -1, srcPath, srcObj, func(reqPath string, info model.Obj) error {
+			if utils.IsCanceled(ctx) {
+				return ctx.Err()
+			}
+			if !canAccess(reqPath) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// the entries are placed relative to rawPath
+			name := strings.TrimPrefix(strings.TrimPrefix(reqPath, rawPath), "/")
+			if name == "" {
+				return nil
+			}
+			return writeZipEntry(ctx, zw, reqPath, name, info)
+		})
+		if err != nil {
+			// the response has been started, so the error can only be logged
+			log.Errorf("zip down: failed write [%s]: %+v", srcPath, err)
+			return
+		}
+	}
+	if err = zw.Close(); err != nil {
+		log.Errorf("zip down: failed close zip of [%s]: %+v", rawPath, err)
+	}
+}
+
+func writeZipEntry(ctx context.Context, zw *zip.Writer, reqPath, name string, info model.Obj) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: info.ModTime(),
+	}
+	if info.IsDir() {
+		header.Name += "/"
+		_, err := zw.CreateHeader(header)
+		return err
+	}
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	link, obj, err := fs.Link(ctx, reqPath, model.LinkArgs{})
+	op.Audit(ctx, model.AuditDownload, err, reqPath)
+	if err != nil {
+		return err
+	}
+	ss, err := stream.NewSeekableStream(&stream.FileStream{Ctx: ctx, Obj: obj}, link)
+	if err != nil {
+		_ = link.Close()
+		return err
+	}
+	defer ss.Close()
+	_, err = utils.CopyWithBuffer(w, ss)
+	return err
+}
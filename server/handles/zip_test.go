@@ -0,0 +1,93 @@
+package handles
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	_ "github.com/OpenListTeam/OpenList/v4/drivers/local"
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestZipDownEntries(t *testing.T) {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "denied.txt", "secret/b.txt"} {
+		if err = os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(root, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	_, err = op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/zip",
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	guest := model.User{Username: "guest", Role: model.GUEST, BasePath: "/"}
+	// the user can access the folders with passwords
+	user := model.User{Username: "zip", BasePath: "/", Permission: 1 << 1}
+	for _, u := range []*model.User{&guest, &user} {
+		if err = op.CreateUser(u); err != nil {
+			t.Fatalf("failed create user: %+v", err)
+		}
+	}
+	if err = op.CreateMeta(&model.Meta{Path: "/zip/secret", Password: "pass", PSub: true}); err != nil {
+		t.Fatalf("failed create meta: %+v", err)
+	}
+	if err = op.CreateACLRule(&model.ACLRule{Path: "/zip/denied.txt", UserID: user.ID, Deny: model.ACLRead}); err != nil {
+		t.Fatalf("failed create acl rule: %+v", err)
+	}
+
+	entries := func(signer *model.User) []string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/z/zip", nil)
+		common.GinWithValue(c, conf.PathKey, "/zip")
+		if signer != nil {
+			common.GinWithValue(c, conf.SignerKey, signer)
+		}
+		ZipDown(c)
+		zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		if err != nil {
+			t.Fatalf("failed read zip: %v", err)
+		}
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+	if got, want := entries(nil), []string{"a.txt", "denied.txt"}; !slices.Equal(got, want) {
+		t.Errorf("entries for the guest = %v, want %v", got, want)
+	}
+	if got, want := entries(&user), []string{"a.txt", "secret/", "secret/b.txt"}; !slices.Equal(got, want) {
+		t.Errorf("entries for the signer = %v, want %v", got, want)
+	}
+}
@@ -45,6 +45,19 @@ func Auth(c *gin.Context) {
 		c.Next()
 		return
 	}
+	if model.IsAPIToken(token) {
+		user, err := op.ValidateAPIToken(token)
+		if err != nil {
+			common.ErrorResp(c, err, 401)
+			c.Abort()
+			return
+		}
+		common.GinWithValue(c, conf.UserKey, user)
+		common.GinWithValue(c, conf.APITokenKey, token)
+		log.Debugf("use api token: %+v", user)
+		c.Next()
+		return
+	}
 	userClaims, err := common.ParseToken(token)
 	if err != nil {
 		common.ErrorResp(c, err, 401)
@@ -146,3 +159,37 @@ func AuthAdmin(c *gin.Context) {
 		c.Next()
 	}
 }
+
+// AuthNotAPIToken rejects the requests authorized by api tokens, they can't
+// manage the account since the user is restricted to the token
+func AuthNotAPIToken(c *gin.Context) {
+	if _, ok := c.Request.Context().Value(conf.APITokenKey).(string); ok {
+		common.ErrorStrResp(c, "Not allowed with api token", 403)
+		c.Abort()
+	} else {
+		c.Next()
+	}
+}
+
+// StreamTicket authorizes the request by the ticket in the query if it has no
+// Authorization header, EventSource and WebSocket of the browsers can't set the
+// header. The others are authorized by Auth
+func StreamTicket(c *gin.Context) {
+	ticket := c.Query("ticket")
+	if ticket == "" || c.GetHeader("Authorization") != "" {
+		Auth(c)
+		return
+	}
+	user, apiToken, ok := common.UseStreamTicket(ticket)
+	if !ok {
+		common.ErrorStrResp(c, "Invalid or expired ticket", 401)
+		c.Abort()
+		return
+	}
+	common.GinWithValue(c, conf.UserKey, user)
+	if apiToken != "" {
+		common.GinWithValue(c, conf.APITokenKey, apiToken)
+	}
+	log.Debugf("use stream ticket: %+v", user)
+	c.Next()
+}
@@ -28,6 +28,8 @@ func StoragesLoaded(c *gin.Context) {
 	}
 	common.GinWithValue(c,
 		conf.ApiUrlKey, common.GetApiUrlFromRequest(c.Request),
+		conf.ClientIPKey, c.ClientIP(),
+		conf.ProtocolKey, "web",
 	)
 	c.Next()
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
@@ -36,6 +37,16 @@ func Down(verifyFunc func(string, string) error) func(c *gin.Context) {
 				c.Abort()
 				return
 			}
+			// the requests by the sign of a user are counted to the user
+			if id := sign.Signer(s); id != 0 {
+				signer, err := op.GetUserById(id)
+				if err != nil || signer.Disabled {
+					common.ErrorStrResp(c, "the signer is invalid", 401)
+					c.Abort()
+					return
+				}
+				common.GinWithValue(c, conf.SignerKey, signer)
+			}
 		}
 		c.Next()
 	}
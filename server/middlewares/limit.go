@@ -1,9 +1,17 @@
 package middlewares
 
 import (
+	"crypto/subtle"
 	"io"
+	"net/http"
 
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,13 +26,27 @@ func MaxAllowed(n int) gin.HandlerFunc {
 	}
 }
 
+// UploadRateLimiter limits the speed of the request body by limiter and the
+// limit of the user, the body is counted as the upload traffic of the user
 func UploadRateLimiter(limiter stream.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Request.Body = &stream.RateLimitReader{
+		var body io.ReadCloser = &stream.RateLimitReader{
 			Reader:  c.Request.Body,
 			Limiter: limiter,
 			Ctx:     c,
 		}
+		if user := trafficUser(c); user != nil {
+			meter := traffic.NewMeter(c, user, traffic.Upload)
+			if isUpload(c.Request.Method) {
+				if err := meter.Check(); err != nil {
+					common.ErrorResp(c, err, 429)
+					c.Abort()
+					return
+				}
+			}
+			body = &traffic.Reader{Reader: body, Meter: meter}
+		}
+		c.Request.Body = body
 		c.Next()
 	}
 }
@@ -38,16 +60,69 @@ func (w *ResponseWriterWrapper) Write(p []byte) (n int, err error) {
 	return w.WrapWriter.Write(p)
 }
 
+// DownloadRateLimiter limits the speed of the response by limiter and the
+// limit of the user, the response is counted as the download traffic of the user
 func DownloadRateLimiter(limiter stream.Limiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var w io.Writer = &stream.RateLimitWriter{
+			Writer:  c.Writer,
+			Limiter: limiter,
+			Ctx:     c,
+		}
+		if user := trafficUser(c); user != nil {
+			meter := traffic.NewMeter(c, user, traffic.Download)
+			if c.Request.Method == http.MethodGet {
+				if err := meter.Check(); err != nil {
+					common.ErrorResp(c, err, 429)
+					c.Abort()
+					return
+				}
+			}
+			w = &traffic.Writer{Writer: w, Meter: meter}
+		}
 		c.Writer = &ResponseWriterWrapper{
 			ResponseWriter: c.Writer,
-			WrapWriter: &stream.RateLimitWriter{
-				Writer:  c.Writer,
-				Limiter: limiter,
-				Ctx:     c,
-			},
+			WrapWriter:     w,
 		}
 		c.Next()
 	}
 }
+
+func isUpload(method string) bool {
+	return method == http.MethodPut || method == http.MethodPost
+}
+
+// trafficUser returns the user to count the traffic of the request, the routes
+// authorized by signs have no user, so the user who made the sign is used, or
+// the token of the request if valid. Otherwise the traffic is counted to the guest
+func trafficUser(c *gin.Context) *model.User {
+	if user, ok := c.Request.Context().Value(conf.UserKey).(*model.User); ok {
+		return user
+	}
+	if signer, ok := c.Request.Context().Value(conf.SignerKey).(*model.User); ok {
+		return signer
+	}
+	token := c.GetHeader("Authorization")
+	switch {
+	case token == "":
+	case subtle.ConstantTimeCompare([]byte(token), []byte(setting.GetStr(conf.Token))) == 1:
+		if admin, err := op.GetAdmin(); err == nil {
+			return admin
+		}
+	case model.IsAPIToken(token):
+		if user, err := op.ValidateAPIToken(token); err == nil {
+			return user
+		}
+	default:
+		if claims, err := common.ParseToken(token); err == nil {
+			if user, err := op.GetUserByName(claims.Username); err == nil && claims.PwdTS == user.PwdTS {
+				return user
+			}
+		}
+	}
+	guest, err := op.GetGuest()
+	if err != nil {
+		return nil
+	}
+	return guest
+}
@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Share resolves the share of the id param, the creator restricted to the
+// share is used as the user and the path param is joined to the shared path
+func Share(c *gin.Context) {
+	s, visitor, err := op.GetShareVisitor(c.Param("id"))
+	if err != nil {
+		log.Debugf("failed get share: %+v", err)
+		common.ErrorStrResp(c, "share not found or has expired", 404)
+		c.Abort()
+		return
+	}
+	if s.Password != "" {
+		password := c.GetHeader("Share-Password")
+		if password == "" {
+			password = c.Query("pwd")
+		}
+		if subtle.ConstantTimeCompare([]byte(password), []byte(s.Password)) != 1 {
+			common.ErrorStrResp(c, "share password is incorrect", 401)
+			c.Abort()
+			return
+		}
+		// don't forward the password to the storages with the query
+		query := c.Request.URL.Query()
+		query.Del("pwd")
+		c.Request.URL.RawQuery = query.Encode()
+	}
+	reqPath, err := visitor.JoinPath(parsePath(c.Param("path")))
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		c.Abort()
+		return
+	}
+	meta, err := op.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		c.Abort()
+		return
+	}
+	// the password of the meta of the shared path is known by the creator,
+	// but the passwords of the metas inside it are still required
+	password := ""
+	if meta != nil && utils.IsSubPath(meta.Path, visitor.BasePath) {
+		password = meta.Password
+	}
+	if !common.CanAccess(visitor, meta, reqPath, password) {
+		common.ErrorStrResp(c, "you have no permission", 403)
+		c.Abort()
+		return
+	}
+	common.GinWithValue(c, conf.UserKey, visitor)
+	common.GinWithValue(c, conf.ShareKey, s)
+	common.GinWithValue(c, conf.PathKey, reqPath)
+	common.GinWithValue(c, conf.MetaKey, meta)
+	c.Next()
+}
@@ -4,8 +4,11 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/cmd/flags"
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/message"
+	"github.com/OpenListTeam/OpenList/v4/internal/session"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/internal/stream"
+	"github.com/OpenListTeam/OpenList/v4/internal/traffic"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/OpenListTeam/OpenList/v4/server/handles"
@@ -34,6 +37,10 @@ func Init(e *gin.Engine) {
 	g.GET("/robots.txt", handles.Robots)
 	g.GET("/i/:link_name", handles.Plist)
 	common.SecretKey = []byte(conf.Conf.JwtSecret)
+	session.Init(conf.Conf.SessionStore)
+	traffic.Init()
+	webhook.Init()
+	handles.InitEvents()
 	g.Use(middlewares.StoragesLoaded)
 	if conf.Conf.MaxConnections > 0 {
 		g.Use(middlewares.MaxAllowed(conf.Conf.MaxConnections))
@@ -47,6 +54,8 @@ func Init(e *gin.Engine) {
 	g.GET("/p/*path", signCheck, downloadLimiter, handles.Proxy)
 	g.HEAD("/d/*path", signCheck, handles.Down)
 	g.HEAD("/p/*path", signCheck, handles.Proxy)
+	g.GET("/z/*path", signCheck, downloadLimiter, handles.ZipDown)
+	g.HEAD("/z/*path", signCheck, handles.ZipDown)
 	archiveSignCheck := middlewares.Down(sign.VerifyArchive)
 	g.GET("/ad/*path", archiveSignCheck, downloadLimiter, handles.ArchiveDown)
 	g.GET("/ap/*path", archiveSignCheck, downloadLimiter, handles.ArchiveProxy)
@@ -54,6 +63,9 @@ func Init(e *gin.Engine) {
 	g.HEAD("/ad/*path", archiveSignCheck, handles.ArchiveDown)
 	g.HEAD("/ap/*path", archiveSignCheck, handles.ArchiveProxy)
 	g.HEAD("/ae/*path", archiveSignCheck, handles.ArchiveInternalExtract)
+	g.GET("/s/:id/*path", middlewares.Share, downloadLimiter, handles.ShareGet)
+	g.HEAD("/s/:id/*path", middlewares.Share, handles.ShareGet)
+	g.PUT("/s/:id/*path", middlewares.Share, middlewares.UploadRateLimiter(stream.ClientUploadLimit), handles.ShareUpload)
 
 	api := g.Group("/api")
 	auth := api.Group("", middlewares.Auth)
@@ -62,13 +74,30 @@ func Init(e *gin.Engine) {
 	api.POST("/auth/login", handles.Login)
 	api.POST("/auth/login/hash", handles.LoginHash)
 	api.POST("/auth/login/ldap", handles.LoginLdap)
+	api.GET("/events", middlewares.StreamTicket, handles.Events)
+	auth.POST("/events/ticket", handles.EventsTicket)
 	auth.GET("/me", handles.CurrentUser)
-	auth.POST("/me/update", handles.UpdateCurrent)
+	auth.POST("/me/update", middlewares.AuthNotAPIToken, handles.UpdateCurrent)
 	auth.GET("/me/sshkey/list", handles.ListMyPublicKey)
-	auth.POST("/me/sshkey/add", handles.AddMyPublicKey)
-	auth.POST("/me/sshkey/delete", handles.DeleteMyPublicKey)
-	auth.POST("/auth/2fa/generate", handles.Generate2FA)
-	auth.POST("/auth/2fa/verify", handles.Verify2FA)
+	auth.POST("/me/sshkey/add", middlewares.AuthNotAPIToken, handles.AddMyPublicKey)
+	auth.POST("/me/sshkey/delete", middlewares.AuthNotAPIToken, handles.DeleteMyPublicKey)
+	auth.GET("/me/ftp/cert/list", handles.ListMyFTPCerts)
+	auth.POST("/me/ftp/cert/add", middlewares.AuthNotAPIToken, handles.AddMyFTPCert)
+	auth.POST("/me/ftp/cert/delete", middlewares.AuthNotAPIToken, handles.DeleteMyFTPCert)
+	auth.GET("/me/ftp/password/list", handles.ListMyFTPPasswords)
+	auth.POST("/me/ftp/password/add", middlewares.AuthNotAPIToken, handles.AddMyFTPPassword)
+	auth.POST("/me/ftp/password/delete", middlewares.AuthNotAPIToken, handles.DeleteMyFTPPassword)
+	auth.GET("/me/token/list", handles.ListMyAPITokens)
+	auth.POST("/me/token/create", middlewares.AuthNotAPIToken, handles.CreateMyAPIToken)
+	auth.POST("/me/token/delete", middlewares.AuthNotAPIToken, handles.DeleteMyAPIToken)
+	auth.GET("/me/share/list", handles.ListMyShares)
+	auth.POST("/me/share/create", handles.CreateShare)
+	auth.POST("/me/share/update", handles.UpdateShare)
+	auth.POST("/me/share/delete", handles.DeleteShare)
+	auth.GET("/me/quota", handles.GetMyQuota)
+	auth.GET("/me/traffic", handles.GetMyTraffic)
+	auth.POST("/auth/2fa/generate", middlewares.AuthNotAPIToken, handles.Generate2FA)
+	auth.POST("/auth/2fa/verify", middlewares.AuthNotAPIToken, handles.Verify2FA)
 	auth.GET("/auth/logout", handles.LogOut)
 
 	// auth
@@ -90,6 +119,7 @@ func Init(e *gin.Engine) {
 	public.Any("/settings", handles.PublicSettings)
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
 	public.Any("/archive_extensions", handles.ArchiveExtensions)
+	public.Any("/archive_compress_extensions", handles.ArchiveCompressExtensions)
 
 	_fs(auth.Group("/fs"))
 	_task(auth.Group("/task", middlewares.AuthNotGuest))
@@ -103,6 +133,8 @@ func Init(e *gin.Engine) {
 }
 
 func admin(g *gin.RouterGroup) {
+	g.GET("/audit", handles.ListAuditLogs)
+
 	meta := g.Group("/meta")
 	meta.GET("/list", handles.ListMetas)
 	meta.GET("/get", handles.GetMeta)
@@ -120,6 +152,42 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/del_cache", handles.DelUserCache)
 	user.GET("/sshkey/list", handles.ListPublicKeys)
 	user.POST("/sshkey/delete", handles.DeletePublicKey)
+	user.GET("/ftp/cert/list", handles.ListFTPCerts)
+	user.POST("/ftp/cert/delete", handles.DeleteFTPCert)
+	user.GET("/ftp/password/list", handles.ListFTPPasswords)
+	user.POST("/ftp/password/delete", handles.DeleteFTPPassword)
+	user.GET("/token/list", handles.ListAPITokens)
+	user.POST("/token/delete", handles.DeleteAPIToken)
+	user.GET("/session/list", handles.ListSessions)
+	user.POST("/session/delete", handles.DeleteSession)
+	user.POST("/session/delete_all", handles.DeleteUserSessions)
+	user.GET("/quota/list", handles.ListQuotas)
+	user.GET("/quota/get", handles.GetQuota)
+	user.POST("/quota/set", handles.SetQuota)
+	user.POST("/quota/delete", handles.DeleteQuota)
+	user.GET("/traffic/list", handles.ListTraffics)
+
+	group := g.Group("/group")
+	group.GET("/list", handles.ListGroups)
+	group.GET("/get", handles.GetGroup)
+	group.POST("/create", handles.CreateGroup)
+	group.POST("/update", handles.UpdateGroup)
+	group.POST("/delete", handles.DeleteGroup)
+
+	acl := g.Group("/acl")
+	acl.GET("/list", handles.ListACLRules)
+	acl.POST("/create", handles.CreateACLRule)
+	acl.POST("/update", handles.UpdateACLRule)
+	acl.POST("/delete", handles.DeleteACLRule)
+
+	hook := g.Group("/webhook")
+	hook.GET("/list", handles.ListWebhooks)
+	hook.GET("/get", handles.GetWebhook)
+	hook.POST("/create", handles.CreateWebhook)
+	hook.POST("/update", handles.UpdateWebhook)
+	hook.POST("/delete", handles.DeleteWebhook)
+	hook.POST("/test", handles.TestWebhook)
+	hook.GET("/delivery/list", handles.ListWebhookDeliveries)
 
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
@@ -165,6 +233,39 @@ func admin(g *gin.RouterGroup) {
 	index.POST("/stop", middlewares.SearchIndex, handles.StopIndex)
 	index.POST("/clear", middlewares.SearchIndex, handles.ClearIndex)
 	index.GET("/progress", middlewares.SearchIndex, handles.GetProgress)
+
+	dup := g.Group("/duplicate")
+	dup.POST("/find", middlewares.SearchIndex, handles.FindDuplicates)
+	dup.GET("/groups", handles.GetDuplicateGroups)
+	dup.POST("/resolve", handles.ResolveDuplicates)
+
+	syncJob := g.Group("/sync")
+	syncJob.GET("/list", handles.ListSyncJobs)
+	syncJob.GET("/get", handles.GetSyncJob)
+	syncJob.POST("/create", handles.CreateSyncJob)
+	syncJob.POST("/update", handles.UpdateSyncJob)
+	syncJob.POST("/delete", handles.DeleteSyncJob)
+	syncJob.POST("/run", handles.RunSyncJob)
+	syncJob.GET("/report", handles.GetSyncReport)
+
+	sched := g.Group("/schedule")
+	sched.GET("/list", handles.ListSchedules)
+	sched.GET("/get", handles.GetSchedule)
+	sched.POST("/create", handles.CreateSchedule)
+	sched.POST("/update", handles.UpdateSchedule)
+	sched.POST("/delete", handles.DeleteSchedule)
+	sched.POST("/run", handles.RunSchedule)
+
+	recycle := g.Group("/recycle")
+	recycle.GET("/list", handles.ListRecycleItems)
+	recycle.POST("/restore", handles.RestoreRecycleItem)
+	recycle.POST("/purge", handles.PurgeRecycleItem)
+	recycle.POST("/empty", handles.EmptyRecycleBin)
+
+	share := g.Group("/share")
+	share.GET("/list", handles.ListShares)
+	share.POST("/update", handles.UpdateShare)
+	share.POST("/delete", handles.DeleteShare)
 }
 
 func _fs(g *gin.RouterGroup) {
@@ -194,6 +295,7 @@ func _fs(g *gin.RouterGroup) {
 	a.Any("/meta", handles.FsArchiveMeta)
 	a.Any("/list", handles.FsArchiveList)
 	a.POST("/decompress", handles.FsArchiveDecompress)
+	a.POST("/compress", handles.FsArchiveCompress)
 }
 
 func _task(g *gin.RouterGroup) {
@@ -0,0 +1,232 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/itsHenry35/gofakes3/signature"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxPresignedExpires is the longest seconds a presigned url is valid, as S3
+	maxPresignedExpires = 7 * 24 * 60 * 60
+	maxClockSkew        = 15 * time.Minute
+)
+
+var (
+	errAccessDenied = signature.APIError{
+		Code:           "AccessDenied",
+		Description:    "Access Denied.",
+		HTTPStatusCode: http.StatusForbidden,
+	}
+	errRequestExpired = signature.APIError{
+		Code:           "AccessDenied",
+		Description:    "Request has expired.",
+		HTTPStatusCode: http.StatusForbidden,
+	}
+)
+
+func writeAPIError(w http.ResponseWriter, e signature.APIError) {
+	w.Header().Add("content-type", "application/xml")
+	w.WriteHeader(e.HTTPStatusCode)
+	_, _ = w.Write(signature.EncodeAPIErrorToResponse(e))
+}
+
+// getAccessKey returns the access key id of the signed request
+func getAccessKey(r *http.Request) string {
+	if cred := r.URL.Query().Get("X-Amz-Credential"); cred != "" {
+		return strings.SplitN(cred, "/", 2)[0]
+	}
+	if key := r.URL.Query().Get("AWSAccessKeyId"); key != "" {
+		return key
+	}
+	auth := r.Header.Get("Authorization")
+	if _, cred, ok := strings.Cut(auth, "Credential="); ok {
+		return strings.SplitN(cred, "/", 2)[0]
+	}
+	if key, ok := strings.CutPrefix(auth, "AWS "); ok {
+		return strings.SplitN(key, ":", 2)[0]
+	}
+	return ""
+}
+
+// tokenAuth verifies the request and puts its user in the context: the user
+// of the api token for the requests signed with api tokens, the admin for the
+// key pair in the settings and the guest for the anonymous ones, which are
+// only allowed if the key pair is not set
+func tokenAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), conf.ProtocolKey, "s3")
+		r = r.WithContext(context.WithValue(ctx, conf.ClientIPKey, r.RemoteAddr))
+		if !presignedValid(r, time.Now()) {
+			writeAPIError(w, errRequestExpired)
+			return
+		}
+		accessKey := getAccessKey(r)
+		var (
+			user *model.User
+			err  error
+		)
+		switch {
+		case accessKey == "":
+			if len(authlistResolver()) > 0 {
+				writeAPIError(w, errAccessDenied)
+				return
+			}
+			user, err = op.GetGuest()
+			if err == nil && user.Disabled {
+				err = errors.New("guest is disabled")
+			}
+		case accessKey == setting.GetStr(conf.S3AccessKeyId):
+			if e := verifySignature(r, setting.GetStr(conf.S3SecretAccessKey), time.Now()); e != nil {
+				writeAPIError(w, *e)
+				return
+			}
+			user, err = op.GetAdmin()
+		default:
+			var e *signature.APIError
+			if user, e = apiTokenUser(r, accessKey); e != nil {
+				writeAPIError(w, *e)
+				return
+			}
+		}
+		if err != nil {
+			log.Warnf("s3: access key %q: %v", accessKey, err)
+			writeAPIError(w, errAccessDenied)
+			return
+		}
+		ctx = context.WithValue(r.Context(), conf.UserKey, user)
+		if !canDo(ctx, r) {
+			writeAPIError(w, errAccessDenied)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// apiTokenUser verifies the signature of the request signed with the api token
+// of accessKey and returns the user restricted by the token
+func apiTokenUser(r *http.Request, accessKey string) (*model.User, *signature.APIError) {
+	t, user, err := op.GetAPITokenUser(accessKey)
+	if err != nil || t == nil {
+		log.Warnf("s3: access key %q: %v", accessKey, err)
+		return nil, &errAccessDenied
+	}
+	if e := verifySignature(r, t.Secret, time.Now()); e != nil {
+		return nil, e
+	}
+	op.TouchAPIToken(t)
+	return user, nil
+}
+
+// presignedValid reports whether the presigned url of r is valid at now,
+// gofakes3 doesn't check the expiry of the V2 ones, nor the limit of the V4 ones
+func presignedValid(r *http.Request, now time.Time) bool {
+	query := r.URL.Query()
+	switch {
+	case query.Has("X-Amz-Signature"):
+		if v := query.Get("X-Amz-Expires"); v != "" {
+			expires, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || expires <= 0 || expires > maxPresignedExpires {
+				return false
+			}
+		}
+		// a url signed for the future would be valid longer than the limit
+		date, err := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+		return err == nil && !date.After(now.Add(maxClockSkew))
+	case query.Has("Signature") && r.Header.Get("Authorization") == "":
+		expires, err := strconv.ParseInt(query.Get("Expires"), 10, 64)
+		return err == nil && now.Unix() < expires &&
+			time.Unix(expires, 0).Before(now.Add(maxPresignedExpires*time.Second+maxClockSkew))
+	}
+	return true
+}
+
+// canDo reports whether the user in ctx can do the request, the permissions
+// of the objects are checked by the meta and acl rules of their paths like the
+// other protocols. the requests to the unknown buckets are left to gofakes3,
+// and the keys deleted in batch are checked by the backend
+func canDo(ctx context.Context, r *http.Request) bool {
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucketName == "" {
+		return r.Method == http.MethodGet
+	}
+	bucket, err := getBucketByName(ctx, bucketName)
+	if err != nil {
+		return true
+	}
+	fp := path.Join(bucket.Path, key)
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return canRead(ctx, fp)
+	case http.MethodPut:
+		if src := r.Header.Get("X-Amz-Copy-Source"); src != "" && !canReadCopySource(ctx, src) {
+			return false
+		}
+		return key != "" && canWrite(ctx, fp)
+	case http.MethodPost:
+		// the multipart uploads of an object, or deleting objects in batch
+		return key == "" || canWrite(ctx, fp)
+	case http.MethodDelete:
+		if r.URL.Query().Has("uploadId") {
+			// aborting a multipart upload
+			return canWrite(ctx, fp)
+		}
+		return key != "" && canRemove(ctx, fp)
+	default:
+		return false
+	}
+}
+
+func canReadCopySource(ctx context.Context, src string) bool {
+	if unescaped, err := url.PathUnescape(src); err == nil {
+		src = unescaped
+	}
+	src, _, _ = strings.Cut(src, "?")
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(src, "/"), "/")
+	bucket, err := getBucketByName(ctx, bucketName)
+	if err != nil {
+		return true
+	}
+	return canRead(ctx, path.Join(bucket.Path, key))
+}
+
+func canRead(ctx context.Context, fp string) bool {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	meta, _ := op.GetNearestMeta(fp)
+	return common.CanAccess(user, meta, fp, "")
+}
+
+func canWrite(ctx context.Context, fp string) bool {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	meta, _ := op.GetNearestMeta(fp)
+	return common.CanAccess(user, meta, fp, "") && common.CanWrite(user, meta, path.Dir(fp))
+}
+
+func canRemove(ctx context.Context, fp string) bool {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	return canRead(ctx, fp) && common.CanOperate(user, fp, model.ACLRemove, user.CanRemove())
+}
+
+// canAccessBucket reports whether the user in ctx can access the bucket,
+// the whole bucket must be under one of the base paths of the user
+func canAccessBucket(ctx context.Context, b Bucket) bool {
+	user, ok := ctx.Value(conf.UserKey).(*model.User)
+	if !ok {
+		return false
+	}
+	_, ok = user.RelPath(b.Path)
+	return ok
+}
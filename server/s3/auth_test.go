@@ -0,0 +1,92 @@
+package s3
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/itsHenry35/gofakes3/signature"
+)
+
+func TestPresignedValid(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	v4 := func(date time.Time, expires string) string {
+		return "/b/k?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Date=" + date.Format("20060102T150405Z") +
+			"&X-Amz-Expires=" + expires + "&X-Amz-Signature=abc"
+	}
+	v2 := func(expires time.Time) string {
+		return "/b/k?AWSAccessKeyId=key&Signature=abc&Expires=" + strconv.FormatInt(expires.Unix(), 10)
+	}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"/b/k", true},
+		{v4(now, "3600"), true},
+		{v4(now, "604800"), true},
+		{v4(now, "604801"), false},
+		{v4(now, "0"), false},
+		{v4(now.Add(time.Hour), "3600"), false},
+		{"/b/k?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Expires=3600&X-Amz-Signature=abc", false},
+		{"/b/k?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Date=yesterday&X-Amz-Expires=3600&X-Amz-Signature=abc", false},
+		{v2(now.Add(time.Hour)), true},
+		{v2(now.Add(-time.Second)), false},
+		{v2(now.Add(30 * 24 * time.Hour)), false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.url, nil)
+		if got := presignedValid(r, now); got != tt.want {
+			t.Errorf("presignedValid(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "secret"
+	now := time.Now()
+	signer := v4.NewSigner(credentials.NewStaticCredentials("key", secret, ""), func(s *v4.Signer) {
+		s.DisableURIPathEscaping = true
+	})
+	newRequest := func() *http.Request {
+		return httptest.NewRequest("GET", "http://example.com/b/dir/a%20b.txt?list-type=2", nil)
+	}
+	signed := newRequest()
+	if _, err := signer.Sign(signed, nil, "s3", "us-east-1", now); err != nil {
+		t.Fatal(err)
+	}
+	presigned := newRequest()
+	if _, err := signer.Presign(presigned, nil, "s3", "us-east-1", time.Hour, now); err != nil {
+		t.Fatal(err)
+	}
+	expired := newRequest()
+	if _, err := signer.Sign(expired, nil, "s3", "us-east-1", now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	v2 := newRequest()
+	v2.Header.Set("Date", now.UTC().Format(http.TimeFormat))
+	v2.Header.Set("Authorization", "AWS key:"+signature.CredentialsV2{SecretKey: secret}.
+		SignV2("GET", v2.URL.Path, v2.URL.RawQuery, v2.Header, ""))
+	tests := []struct {
+		name   string
+		r      *http.Request
+		secret string
+		valid  bool
+	}{
+		{"v4", signed, secret, true},
+		{"v4 wrong secret", signed, "other", false},
+		{"v4 presigned", presigned, secret, true},
+		{"v4 expired", expired, secret, false},
+		{"v2", v2, secret, true},
+		{"v2 wrong secret", v2, "other", false},
+		{"unsigned", newRequest(), secret, false},
+	}
+	for _, tt := range tests {
+		if e := verifySignature(tt.r, tt.secret, now); (e == nil) != tt.valid {
+			t.Errorf("%s: verifySignature = %v, want valid %v", tt.name, e, tt.valid)
+		}
+	}
+}
@@ -2,7 +2,14 @@
 // Package s3 implements a fake s3 server for openlist
 package s3
 
-import "io"
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
 
 type noOpReadCloser struct{}
 
@@ -34,3 +41,101 @@ func (rwc *readerWithCloser) Close() error {
 	}
 	return nil
 }
+
+// chunkedReader decodes the body of aws-chunked encoding, the chunks are
+// "<hex size>[;chunk-signature=<signature>]\r\n<data>\r\n" and end with a
+// chunk of zero size, which may be followed by the trailers.
+// The signatures of the chunks are not verified, as gofakes3 doesn't either.
+type chunkedReader struct {
+	r      *bufio.Reader
+	remain int64
+	eof    bool
+}
+
+func newChunkedReader(r io.Reader) *chunkedReader {
+	return &chunkedReader{r: bufio.NewReader(r)}
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.eof {
+		return 0, io.EOF
+	}
+	if c.remain == 0 {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		size, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		c.remain, err = strconv.ParseInt(size, 16, 64)
+		if err != nil || c.remain < 0 {
+			return 0, fmt.Errorf("invalid chunk size %q", size)
+		}
+		if c.remain == 0 {
+			c.eof = true
+			return 0, io.EOF
+		}
+	}
+	if int64(len(p)) > c.remain {
+		p = p[:c.remain]
+	}
+	n, err := c.r.Read(p)
+	c.remain -= int64(n)
+	if err != nil {
+		return n, unexpectedEOF(err)
+	}
+	if c.remain == 0 {
+		// the "\r\n" after the data
+		if _, err = c.r.Discard(2); err != nil {
+			return n, unexpectedEOF(err)
+		}
+	}
+	return n, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// partsReader reads the files of the parts one after another, only one of
+// them is open at a time
+type partsReader struct {
+	paths []string
+	f     *os.File
+}
+
+func (pr *partsReader) Read(p []byte) (int, error) {
+	for {
+		if pr.f == nil {
+			if len(pr.paths) == 0 {
+				return 0, io.EOF
+			}
+			f, err := os.Open(pr.paths[0])
+			if err != nil {
+				return 0, err
+			}
+			pr.f, pr.paths = f, pr.paths[1:]
+		}
+		n, err := pr.f.Read(p)
+		if err == io.EOF {
+			_ = pr.f.Close()
+			pr.f = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (pr *partsReader) Close() error {
+	if pr.f == nil {
+		return nil
+	}
+	err := pr.f.Close()
+	pr.f = nil
+	return err
+}
@@ -0,0 +1,43 @@
+package s3
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestChunkedReader(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "signed",
+			body: "5;chunk-signature=" + strings.Repeat("a", 64) + "\r\nhello\r\n" +
+				"6;chunk-signature=" + strings.Repeat("b", 64) + "\r\n world\r\n" +
+				"0;chunk-signature=" + strings.Repeat("c", 64) + "\r\n\r\n",
+			want: "hello world",
+		},
+		{
+			name: "unsigned with trailer",
+			body: "b\r\nhello world\r\n0\r\nx-amz-checksum-crc32:DUoRhQ==\r\n\r\n",
+			want: "hello world",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(newChunkedReader(strings.NewReader(tt.body)))
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+	_, err := io.ReadAll(newChunkedReader(strings.NewReader("5\r\nhel")))
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated chunk: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
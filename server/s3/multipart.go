@@ -0,0 +1,507 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/pkg/cron"
+	"github.com/google/uuid"
+	"github.com/itsHenry35/gofakes3"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// multipartExpiry is how long the uploads that receive no part are kept
+	multipartExpiry = 7 * 24 * time.Hour
+	// completeKeepAlive is the interval of the whitespace sent while completing
+	completeKeepAlive = 10 * time.Second
+)
+
+var multipartCron *cron.Cron
+
+// multipartHandler handles the multipart uploads instead of gofakes3, which
+// assembles them in memory. The parts are staged in the temp dir and tracked
+// in the database, so the uploads can be resumed after restarts, and they are
+// streamed into the storage when the upload is completed
+type multipartHandler struct {
+	backend gofakes3.Backend
+	next    http.Handler
+}
+
+func newMultipartHandler(backend gofakes3.Backend, next http.Handler) http.Handler {
+	if multipartCron != nil {
+		multipartCron.Stop()
+	}
+	multipartCron = cron.NewCron(time.Hour)
+	multipartCron.Do(cleanMultipartUploads)
+	go cleanMultipartUploads()
+	return &multipartHandler{backend: backend, next: next}
+}
+
+func (m *multipartHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+	_, uploads := query["uploads"]
+	if uploadID == "" && !uploads {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+	bucketName, key, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+	var err error
+	switch {
+	case uploadID != "":
+		switch r.Method {
+		case http.MethodGet:
+			err = m.listParts(w, r, bucketName, key, uploadID)
+		case http.MethodPut:
+			err = m.uploadPart(w, r, bucketName, key, uploadID)
+		case http.MethodPost:
+			err = m.complete(w, r, bucketName, key, uploadID)
+		case http.MethodDelete:
+			err = m.abort(w, r, bucketName, key, uploadID)
+		default:
+			err = gofakes3.ErrMethodNotAllowed
+		}
+	case r.Method == http.MethodGet:
+		err = m.listUploads(w, r, bucketName)
+	case r.Method == http.MethodPost:
+		err = m.initiate(w, r, bucketName, key)
+	default:
+		err = gofakes3.ErrMethodNotAllowed
+	}
+	if err != nil {
+		writeError(w, r, err)
+	}
+}
+
+func (m *multipartHandler) initiate(w http.ResponseWriter, r *http.Request, bucketName, key string) error {
+	if _, err := getBucketByName(r.Context(), bucketName); err != nil {
+		return err
+	}
+	if key == "" {
+		return gofakes3.ErrInvalidURI
+	}
+	user := r.Context().Value(conf.UserKey).(*model.User)
+	now := time.Now()
+	upload := &model.S3MultipartUpload{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Bucket:    bucketName,
+		ObjectKey: key,
+		Meta:      metadataHeaders(r.Header),
+		Created:   now,
+		Updated:   now,
+	}
+	if err := db.CreateS3MultipartUpload(upload); err != nil {
+		return err
+	}
+	return encodeXML(w, gofakes3.InitiateMultipartUpload{
+		Bucket:   bucketName,
+		Key:      key,
+		UploadID: gofakes3.UploadID(upload.ID),
+	})
+}
+
+func (m *multipartHandler) uploadPart(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string) error {
+	partNumber, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || partNumber <= 0 || partNumber > gofakes3.MaxUploadPartNumber {
+		return gofakes3.ErrInvalidPart
+	}
+	if _, err = m.getUpload(r.Context(), bucketName, key, uploadID); err != nil {
+		return err
+	}
+	body, size := io.Reader(r.Body), r.ContentLength
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		body = newChunkedReader(r.Body)
+		size, err = strconv.ParseInt(r.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil {
+			return gofakes3.ErrMissingContentLength
+		}
+	}
+	if size < 0 {
+		return gofakes3.ErrMissingContentLength
+	}
+
+	dir := uploadDir(uploadID)
+	if err = os.MkdirAll(dir, 0o777); err != nil {
+		return errors.WithStack(err)
+	}
+	// the part is written to a temp file first, so that the part uploaded
+	// before is kept if this one fails
+	f, err := os.CreateTemp(dir, "part-*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	h := md5.New()
+	if _, err = io.CopyN(f, io.TeeReader(body, h), size); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return gofakes3.ErrIncompleteBody
+		}
+		return err
+	}
+	sum := h.Sum(nil)
+	if digest := r.Header.Get("Content-MD5"); digest != "" && digest != base64.StdEncoding.EncodeToString(sum) {
+		return gofakes3.ErrBadDigest
+	}
+	if err = f.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err = os.Rename(f.Name(), partPath(uploadID, partNumber)); err != nil {
+		return errors.WithStack(err)
+	}
+	etag := hex.EncodeToString(sum)
+	err = db.SaveS3MultipartPart(&model.S3MultipartPart{
+		UploadID:   uploadID,
+		PartNumber: partNumber,
+		Size:       size,
+		ETag:       etag,
+		Updated:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", `"`+etag+`"`)
+	return nil
+}
+
+func (m *multipartHandler) complete(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string) error {
+	upload, err := m.getUpload(r.Context(), bucketName, key, uploadID)
+	if err != nil {
+		return err
+	}
+	var in gofakes3.CompleteMultipartUploadRequest
+	if err = xml.NewDecoder(r.Body).Decode(&in); err != nil {
+		return gofakes3.ErrorMessage(gofakes3.ErrMalformedXML, err.Error())
+	}
+	if len(in.Parts) == 0 {
+		return gofakes3.ErrMalformedXML
+	}
+	parts, err := db.GetS3MultipartParts(uploadID)
+	if err != nil {
+		return err
+	}
+	uploaded := make(map[int]model.S3MultipartPart, len(parts))
+	for _, p := range parts {
+		uploaded[p.PartNumber] = p
+	}
+	var (
+		size  int64
+		paths = make([]string, 0, len(in.Parts))
+		sums  = make([]byte, 0, len(in.Parts)*md5.Size)
+	)
+	for i, p := range in.Parts {
+		if i > 0 && p.PartNumber <= in.Parts[i-1].PartNumber {
+			return gofakes3.ErrInvalidPartOrder
+		}
+		part, ok := uploaded[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, `"`) != part.ETag {
+			return gofakes3.ErrInvalidPart
+		}
+		sum, _ := hex.DecodeString(part.ETag)
+		sums = append(sums, sum...)
+		size += part.Size
+		paths = append(paths, partPath(uploadID, p.PartNumber))
+	}
+	rd := &partsReader{paths: paths}
+	defer rd.Close()
+
+	// putting a large object into the storage takes a while, so the response
+	// is started at once and kept alive by whitespace like S3 does, and the
+	// error of putting is sent in the body
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	stop := keepAlive(w)
+	_, err = m.backend.PutObject(r.Context(), bucketName, key, upload.Meta, rd, size)
+	stop()
+	if err != nil {
+		log.Errorf("s3: failed complete multipart upload %s: %+v", uploadID, err)
+		writeXML(w, errorResponse(err))
+		return nil
+	}
+	if err = removeUpload(uploadID); err != nil {
+		log.Errorf("s3: failed remove completed multipart upload %s: %+v", uploadID, err)
+	}
+	sum := md5.Sum(sums)
+	writeXML(w, gofakes3.CompleteMultipartUploadResult{
+		Bucket: bucketName,
+		Key:    key,
+		ETag:   fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(in.Parts)),
+	})
+	return nil
+}
+
+func (m *multipartHandler) abort(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string) error {
+	if _, err := m.getUpload(r.Context(), bucketName, key, uploadID); err != nil {
+		return err
+	}
+	if err := removeUpload(uploadID); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (m *multipartHandler) listParts(w http.ResponseWriter, r *http.Request, bucketName, key, uploadID string) error {
+	upload, err := m.getUpload(r.Context(), bucketName, key, uploadID)
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	marker, err := parseQueryInt(query.Get("part-number-marker"), 0, gofakes3.MaxUploadPartNumber)
+	if err != nil {
+		return err
+	}
+	maxParts, err := parseQueryInt(query.Get("max-parts"), gofakes3.DefaultMaxUploadParts, gofakes3.MaxUploadPartsLimit)
+	if err != nil {
+		return err
+	}
+	parts, err := db.GetS3MultipartParts(uploadID)
+	if err != nil {
+		return err
+	}
+	out := gofakes3.ListMultipartUploadPartsResult{
+		Bucket:           bucketName,
+		Key:              key,
+		UploadID:         gofakes3.UploadID(upload.ID),
+		PartNumberMarker: marker,
+		MaxParts:         int64(maxParts),
+	}
+	for _, p := range parts {
+		if p.PartNumber <= marker {
+			continue
+		}
+		if len(out.Parts) == maxParts {
+			out.IsTruncated = true
+			break
+		}
+		out.Parts = append(out.Parts, gofakes3.ListMultipartUploadPartItem{
+			PartNumber:   p.PartNumber,
+			LastModified: gofakes3.NewContentTime(p.Updated),
+			ETag:         `"` + p.ETag + `"`,
+			Size:         p.Size,
+		})
+		out.NextPartNumberMarker = p.PartNumber
+	}
+	return encodeXML(w, out)
+}
+
+func (m *multipartHandler) listUploads(w http.ResponseWriter, r *http.Request, bucketName string) error {
+	if _, err := getBucketByName(r.Context(), bucketName); err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	maxUploads, err := parseQueryInt(query.Get("max-uploads"), gofakes3.DefaultMaxUploads, gofakes3.MaxUploadsLimit)
+	if err != nil {
+		return err
+	}
+	user := r.Context().Value(conf.UserKey).(*model.User)
+	uploads, err := db.GetS3MultipartUploads(user.ID, bucketName)
+	if err != nil {
+		return err
+	}
+	out := gofakes3.ListMultipartUploadsResult{
+		Bucket:         bucketName,
+		KeyMarker:      query.Get("key-marker"),
+		UploadIDMarker: gofakes3.UploadID(query.Get("upload-id-marker")),
+		Prefix:         query.Get("prefix"),
+		MaxUploads:     int64(maxUploads),
+	}
+	for _, u := range uploads {
+		if !strings.HasPrefix(u.ObjectKey, out.Prefix) {
+			continue
+		}
+		if out.KeyMarker != "" && (u.ObjectKey < out.KeyMarker ||
+			u.ObjectKey == out.KeyMarker && (out.UploadIDMarker == "" || u.ID <= string(out.UploadIDMarker))) {
+			continue
+		}
+		if len(out.Uploads) == maxUploads {
+			out.IsTruncated = true
+			break
+		}
+		out.Uploads = append(out.Uploads, gofakes3.ListMultipartUploadItem{
+			Key:       u.ObjectKey,
+			UploadID:  gofakes3.UploadID(u.ID),
+			Initiated: gofakes3.NewContentTime(u.Created),
+		})
+		out.NextKeyMarker, out.NextUploadIDMarker = u.ObjectKey, gofakes3.UploadID(u.ID)
+	}
+	return encodeXML(w, out)
+}
+
+// getUpload returns the upload of id, which must be initiated by the user in
+// ctx for the object of key in the bucket
+func (m *multipartHandler) getUpload(ctx context.Context, bucketName, key, id string) (*model.S3MultipartUpload, error) {
+	if _, err := getBucketByName(ctx, bucketName); err != nil {
+		return nil, err
+	}
+	upload, err := db.GetS3MultipartUpload(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gofakes3.ErrNoSuchUpload
+		}
+		return nil, err
+	}
+	user := ctx.Value(conf.UserKey).(*model.User)
+	if upload.UserID != user.ID || upload.Bucket != bucketName || upload.ObjectKey != key {
+		return nil, gofakes3.ErrNoSuchUpload
+	}
+	return upload, nil
+}
+
+// cleanMultipartUploads removes the expired uploads and the staged parts
+// that belong to no upload
+func cleanMultipartUploads() {
+	uploads, err := db.GetS3MultipartUploadsBefore(time.Now().Add(-multipartExpiry))
+	if err != nil {
+		log.Errorf("s3: failed get expired multipart uploads: %+v", err)
+		return
+	}
+	for _, u := range uploads {
+		if err = removeUpload(u.ID); err != nil {
+			log.Errorf("s3: failed remove expired multipart upload %s: %+v", u.ID, err)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(conf.Conf.TempDir, conf.S3MultipartDir))
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		_, err = db.GetS3MultipartUpload(entry.Name())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = os.RemoveAll(filepath.Join(conf.Conf.TempDir, conf.S3MultipartDir, entry.Name()))
+		}
+	}
+}
+
+func removeUpload(id string) error {
+	if err := db.DeleteS3MultipartUpload(id); err != nil {
+		return err
+	}
+	return errors.WithStack(os.RemoveAll(uploadDir(id)))
+}
+
+func uploadDir(id string) string {
+	return filepath.Join(conf.Conf.TempDir, conf.S3MultipartDir, id)
+}
+
+func partPath(id string, partNumber int) string {
+	return filepath.Join(uploadDir(id), strconv.Itoa(partNumber))
+}
+
+// metadataHeaders returns the headers of the object in the request like gofakes3
+func metadataHeaders(header http.Header) map[string]string {
+	meta := make(map[string]string)
+	for k, v := range header {
+		if k == "Content-Length" || k == "Content-Md5" {
+			continue
+		}
+		if strings.HasPrefix(k, "X-Amz-") || strings.HasPrefix(k, "Content-") || k == "Cache-Control" {
+			meta[k] = v[0]
+		}
+	}
+	return meta
+}
+
+// keepAlive writes whitespace to w periodically until stop is called
+func keepAlive(w http.ResponseWriter) (stop func()) {
+	flush := func() {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	flush()
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(completeKeepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_, _ = w.Write([]byte(" "))
+				flush()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+func parseQueryInt(v string, def, max int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return 0, gofakes3.ErrInvalidURI
+	}
+	if i == 0 {
+		i = def
+	}
+	return min(i, max), nil
+}
+
+// errorResponse converts err to the error response of S3
+func errorResponse(err error) gofakes3.Error {
+	var e gofakes3.Error
+	if !errors.As(err, &e) {
+		e = gofakes3.ErrInternal
+	}
+	if code, ok := e.(gofakes3.ErrorCode); ok {
+		return &gofakes3.ErrorResponse{Code: code, Message: code.Message()}
+	}
+	return e
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	resp := errorResponse(err)
+	if resp.ErrorCode() == gofakes3.ErrInternal {
+		log.Errorf("s3: %+v", err)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(resp.ErrorCode().Status())
+	if r.Method != http.MethodHead {
+		_, _ = w.Write([]byte(xml.Header))
+		writeXML(w, resp)
+	}
+}
+
+func encodeXML(w http.ResponseWriter, v any) error {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(xml.Header))
+	writeXML(w, v)
+	return nil
+}
+
+func writeXML(w io.Writer, v any) {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Warnf("s3: failed write response: %v", err)
+	}
+}
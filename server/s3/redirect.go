@@ -0,0 +1,67 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// redirectGet answers GetObject with a 307 redirect to the link of the driver
+// if the s3 policy of the storage is 307_redirect, like the 302_redirect policy
+// of webdav, so the clients download from the storage directly. The other
+// requests, and the objects whose links can't be redirected to, go to next
+func redirectGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if u := redirectURL(r); u != "" {
+				w.Header().Set("Cache-Control", "max-age=0, no-cache, no-store, must-revalidate")
+				http.Redirect(w, r, u, http.StatusTemporaryRedirect)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func redirectURL(r *http.Request) string {
+	query := r.URL.Query()
+	if query.Has("versioning") || query.Has("versions") || query.Has("versionId") {
+		return ""
+	}
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if key == "" || strings.HasSuffix(key, "/") {
+		return ""
+	}
+	ctx := r.Context()
+	bucket, err := getBucketByName(ctx, bucketName)
+	if err != nil {
+		return ""
+	}
+	fp := path.Join(bucket.Path, key)
+	storage, err := fs.GetStorage(fp, &fs.GetStoragesArgs{})
+	if err != nil || !storage.GetStorage().S3Redirect() || storage.Config().MustProxy() {
+		return ""
+	}
+	meta, _ := op.GetNearestMeta(fp)
+	obj, err := fs.Get(context.WithValue(ctx, conf.MetaKey, meta), fp, &fs.GetArgs{})
+	if err != nil || obj.IsDir() {
+		return ""
+	}
+	link, _, err := fs.Link(ctx, fp, model.LinkArgs{IP: utils.ClientIP(r), Header: r.Header, Redirect: true})
+	if err != nil {
+		log.Warnf("s3: failed get link of %s, fall back to proxy: %v", fp, err)
+		return ""
+	}
+	defer link.Close()
+	// the failures are served by GetObject, which audits them
+	op.Audit(ctx, model.AuditDownload, nil, fp)
+	return link.URL
+}
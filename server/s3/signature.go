@@ -0,0 +1,266 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itsHenry35/gofakes3/signature"
+)
+
+// the signatures are verified here with the secret of each request, instead of
+// the key store of gofakes3, which is shared by all the requests
+
+const (
+	signV4Algorithm = "AWS4-HMAC-SHA256"
+	signV2Algorithm = "AWS"
+	iso8601Format   = "20060102T150405Z"
+	yyyymmdd        = "20060102"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	emptySHA256     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// defaultV4Expires is how long a V4 signed request without X-Amz-Expires is valid
+	defaultV4Expires = 15 * time.Minute
+)
+
+var (
+	errSignatureDoesNotMatch = signature.APIError{
+		Code:           "SignatureDoesNotMatch",
+		Description:    "The request signature we calculated does not match the signature you provided. Check your key and signing method.",
+		HTTPStatusCode: http.StatusForbidden,
+	}
+	errMalformedAuth = signature.APIError{
+		Code:           "AuthorizationHeaderMalformed",
+		Description:    "The authorization header is malformed.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+	errMissingDate = signature.APIError{
+		Code:           "AccessDenied",
+		Description:    "AWS authentication requires a valid Date or x-amz-date header",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+	errUnsignedHeaders = signature.APIError{
+		Code:           "AccessDenied",
+		Description:    "There were headers present in the request which were not signed",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+)
+
+// verifySignature verifies the V4 or V2 signature of r with secret, it returns
+// nil if the signature matches
+func verifySignature(r *http.Request, secret string, now time.Time) *signature.APIError {
+	auth := r.Header.Get("Authorization")
+	query := r.URL.Query()
+	switch {
+	case strings.HasPrefix(auth, signV4Algorithm) || (auth == "" && query.Has("X-Amz-Signature")):
+		return verifyV4(r, secret, now)
+	case strings.HasPrefix(auth, signV2Algorithm+" ") || (auth == "" && query.Has("Signature")):
+		return verifyV2(r, secret)
+	}
+	return &errAccessDenied
+}
+
+type signV4Values struct {
+	credential    string
+	signedHeaders string
+	signature     string
+}
+
+// parseSignV4 parses the V4 authorization header:
+//
+//	AWS4-HMAC-SHA256 Credential=<key>/<scope>, SignedHeaders=<headers>, Signature=<signature>
+func parseSignV4(auth string) (v signV4Values, ok bool) {
+	fields := strings.Split(strings.ReplaceAll(strings.TrimPrefix(auth, signV4Algorithm), " ", ""), ",")
+	if len(fields) != 3 {
+		return v, false
+	}
+	for i, name := range []string{"Credential", "SignedHeaders", "Signature"} {
+		value, found := strings.CutPrefix(fields[i], name+"=")
+		if !found || value == "" {
+			return v, false
+		}
+		fields[i] = value
+	}
+	return signV4Values{credential: fields[0], signedHeaders: fields[1], signature: fields[2]}, true
+}
+
+func verifyV4(r *http.Request, secret string, now time.Time) *signature.APIError {
+	query := r.URL.Query()
+	var (
+		v       signV4Values
+		date    string
+		payload string
+	)
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		var ok bool
+		if v, ok = parseSignV4(auth); !ok {
+			return &errMalformedAuth
+		}
+		if date = r.Header.Get("X-Amz-Date"); date == "" {
+			date = r.Header.Get("Date")
+		}
+		if payload = r.Header.Get("X-Amz-Content-Sha256"); payload == "" {
+			payload = emptySHA256
+		}
+	} else {
+		if query.Get("X-Amz-Algorithm") != signV4Algorithm {
+			return &errMalformedAuth
+		}
+		v = signV4Values{
+			credential:    query.Get("X-Amz-Credential"),
+			signedHeaders: query.Get("X-Amz-SignedHeaders"),
+			signature:     query.Get("X-Amz-Signature"),
+		}
+		date = query.Get("X-Amz-Date")
+		// the payloads of the presigned urls are never signed
+		payload = unsignedPayload
+	}
+	// the access key may contain slashes, the scope is the last 4 elements
+	creds := strings.Split(v.credential, "/")
+	if len(creds) < 5 || creds[len(creds)-2] != "s3" || creds[len(creds)-1] != "aws4_request" {
+		return &errMalformedAuth
+	}
+	scope := creds[len(creds)-4:]
+	scopeDate, err := time.Parse(yyyymmdd, scope[0])
+	if err != nil {
+		return &errMalformedAuth
+	}
+	if date == "" {
+		return &errMissingDate
+	}
+	t, err := time.Parse(iso8601Format, date)
+	if err != nil {
+		return &errMissingDate
+	}
+	expires := defaultV4Expires
+	if v := query.Get("X-Amz-Expires"); v != "" {
+		seconds, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return &errMalformedAuth
+		}
+		expires = time.Duration(seconds) * time.Second
+	}
+	if now.After(t.Add(expires)) {
+		return &errRequestExpired
+	}
+	headers, ok := canonicalHeadersV4(r, strings.Split(v.signedHeaders, ";"))
+	if !ok {
+		return &errUnsignedHeaders
+	}
+	query.Del("X-Amz-Signature")
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		encodePathV4(r.URL.Path),
+		strings.ReplaceAll(query.Encode(), "+", "%20"),
+		headers,
+		v.signedHeaders,
+		payload,
+	}, "\n")
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signV4Algorithm,
+		t.Format(iso8601Format),
+		strings.Join(scope, "/"),
+		hex.EncodeToString(hashed[:]),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+secret), []byte(scopeDate.Format(yyyymmdd)))
+	key = hmacSHA256(key, []byte(scope[1]))
+	key = hmacSHA256(key, []byte("s3"))
+	key = hmacSHA256(key, []byte("aws4_request"))
+	expected := hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(v.signature)) != 1 {
+		return &errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+// canonicalHeadersV4 returns the canonical headers of the signed headers of r,
+// host is required to be signed
+func canonicalHeadersV4(r *http.Request, signedHeaders []string) (string, bool) {
+	sort.Strings(signedHeaders)
+	query := r.URL.Query()
+	hasHost := false
+	var b strings.Builder
+	for _, name := range signedHeaders {
+		values, ok := r.Header[http.CanonicalHeaderKey(name)]
+		if !ok {
+			values, ok = query[name]
+		}
+		if !ok {
+			// the headers removed from the request by the http server
+			switch name {
+			case "host":
+				values = []string{r.Host}
+			case "expect":
+				values = []string{"100-continue"}
+			case "transfer-encoding":
+				values = r.TransferEncoding
+			case "content-length":
+				values = []string{strconv.FormatInt(r.ContentLength, 10)}
+			default:
+				return "", false
+			}
+		}
+		hasHost = hasHost || name == "host"
+		b.WriteString(name)
+		b.WriteByte(':')
+		for i, value := range values {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strings.Join(strings.Fields(value), " "))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String(), hasHost
+}
+
+// encodePathV4 encodes the path as the canonical uri of V4, all the bytes
+// except the unreserved chars and the slashes are percent-encoded
+func encodePathV4(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return b.String()
+}
+
+func verifyV2(r *http.Request, secret string) *signature.APIError {
+	var sig, expires string
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		cred := strings.TrimPrefix(auth, signV2Algorithm+" ")
+		var ok bool
+		if _, sig, ok = strings.Cut(cred, ":"); !ok || sig == "" {
+			return &errMalformedAuth
+		}
+	} else {
+		query := r.URL.Query()
+		sig, expires = query.Get("Signature"), query.Get("Expires")
+	}
+	resource := r.URL.RawPath
+	if resource == "" {
+		resource = r.URL.Path
+	}
+	expected := signature.CredentialsV2{SecretKey: secret}.SignV2(r.Method, resource, r.URL.RawQuery, r.Header, expires)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(sig)) != 1 {
+		return &errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
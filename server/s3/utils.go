@@ -5,6 +5,7 @@ package s3
 import (
 	"context"
 	"encoding/json"
+	stdpath "path"
 	"strings"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
@@ -13,6 +14,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
 	"github.com/itsHenry35/gofakes3"
 )
 
@@ -23,14 +25,22 @@ type Bucket struct {
 
 const emptyObjectName = "ThisIsAnEmptyFolderInTheS3Bucket"
 
-func getAndParseBuckets() ([]Bucket, error) {
-	var res []Bucket
-	err := json.Unmarshal([]byte(setting.GetStr(conf.S3Buckets)), &res)
-	return res, err
+func getAndParseBuckets(ctx context.Context) ([]Bucket, error) {
+	var buckets []Bucket
+	if err := json.Unmarshal([]byte(setting.GetStr(conf.S3Buckets)), &buckets); err != nil {
+		return nil, err
+	}
+	res := buckets[:0]
+	for _, b := range buckets {
+		if canAccessBucket(ctx, b) {
+			res = append(res, b)
+		}
+	}
+	return res, nil
 }
 
-func getBucketByName(name string) (Bucket, error) {
-	buckets, err := getAndParseBuckets()
+func getBucketByName(ctx context.Context, name string) (Bucket, error) {
+	buckets, err := getAndParseBuckets(ctx)
 	if err != nil {
 		return Bucket{}, err
 	}
@@ -42,8 +52,7 @@ func getBucketByName(name string) (Bucket, error) {
 	return Bucket{}, gofakes3.BucketNotFound(name)
 }
 
-func getDirEntries(path string) ([]model.Obj, error) {
-	ctx := context.Background()
+func getDirEntries(ctx context.Context, path string) ([]model.Obj, error) {
 	meta, _ := op.GetNearestMeta(path)
 	fi, err := fs.Get(context.WithValue(ctx, conf.MetaKey, meta), path, &fs.GetArgs{})
 	if errs.IsNotFoundError(err) {
@@ -52,7 +61,7 @@ func getDirEntries(path string) ([]model.Obj, error) {
 		return nil, gofakes3.ErrNoSuchKey
 	}
 
-	if !fi.IsDir() {
+	if !fi.IsDir() || !canRead(ctx, path) {
 		return nil, gofakes3.ErrNoSuchKey
 	}
 
@@ -61,7 +70,15 @@ func getDirEntries(path string) ([]model.Obj, error) {
 		return nil, err
 	}
 
-	return dirEntries, nil
+	// drop the hidden entries and the ones denied by the acl rules
+	user := ctx.Value(conf.UserKey).(*model.User)
+	res := dirEntries[:0:0]
+	for _, entry := range dirEntries {
+		if common.CanAccess(user, meta, stdpath.Join(path, entry.GetName()), "") {
+			res = append(res, entry)
+		}
+	}
+	return res, nil
 }
 
 // func getFileHashByte(node interface{}) []byte {
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/ftp"
 	"github.com/OpenListTeam/OpenList/v4/server/sftp"
@@ -64,6 +66,7 @@ func (d *SftpDriver) GetFileSystem(sc *ssh.ServerConn) (sftpd.FileSystem, error)
 	ctx = context.WithValue(ctx, conf.UserKey, userObj)
 	ctx = context.WithValue(ctx, conf.MetaPassKey, "")
 	ctx = context.WithValue(ctx, conf.ClientIPKey, sc.RemoteAddr().String())
+	ctx = context.WithValue(ctx, conf.ProtocolKey, "sftp")
 	ctx = context.WithValue(ctx, conf.ProxyHeaderKey, d.proxyHeader)
 	return &sftp.DriverAdapter{FtpDriver: ftp.NewAferoAdapter(ctx)}, nil
 }
@@ -79,8 +82,8 @@ func (d *SftpDriver) NoClientAuth(conn ssh.ConnMetadata) (*ssh.Permissions, erro
 	if err != nil {
 		return nil, err
 	}
-	if guest.Disabled || !guest.CanFTPAccess() {
-		return nil, errors.New("user is not allowed to access via SFTP")
+	if err = checkSFTPUser(conn, guest); err != nil {
+		return nil, err
 	}
 	return nil, nil
 }
@@ -88,25 +91,43 @@ func (d *SftpDriver) NoClientAuth(conn ssh.ConnMetadata) (*ssh.Permissions, erro
 func (d *SftpDriver) PasswordAuth(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
 	userObj, err := op.GetUserByName(conn.User())
 	if err != nil {
+		webhook.EmitLoginFailed(conn.User(), conn.RemoteAddr().String(), "sftp")
 		return nil, err
 	}
-	if userObj.Disabled || !userObj.CanFTPAccess() {
-		return nil, errors.New("user is not allowed to access via SFTP")
+	if err = checkSFTPUser(conn, userObj); err != nil {
+		return nil, err
 	}
 	passHash := model.StaticHash(string(password))
 	if err = userObj.ValidatePwdStaticHash(passHash); err != nil {
+		webhook.EmitLoginFailed(conn.User(), conn.RemoteAddr().String(), "sftp")
 		return nil, err
 	}
 	return nil, nil
 }
 
+// checkSFTPUser checks whether user can login via SFTP from the address of conn,
+// the FTP allowlist of user applies to SFTP too
+func checkSFTPUser(conn ssh.ConnMetadata, user *model.User) error {
+	if user.Disabled || !user.CanFTPAccess() {
+		return errors.New("user is not allowed to access via SFTP")
+	}
+	var ip net.IP
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		ip = addr.IP
+	}
+	if !user.FTPAllowsIP(ip) {
+		return errors.New("user is not allowed to access via SFTP from this address")
+	}
+	return nil
+}
+
 func (d *SftpDriver) PublicKeyAuth(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
 	userObj, err := op.GetUserByName(conn.User())
 	if err != nil {
 		return nil, err
 	}
-	if userObj.Disabled || !userObj.CanFTPAccess() {
-		return nil, errors.New("user is not allowed to access via SFTP")
+	if err = checkSFTPUser(conn, userObj); err != nil {
+		return nil, err
 	}
 	keys, _, err := op.GetSSHPublicKeyByUserId(userObj.ID, 1, -1)
 	if err != nil {
@@ -0,0 +1,210 @@
+package sftp
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
+	"github.com/OpenListTeam/sftpd-openlist"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// maxPacketSize is larger than the buffer of sftpd, which fails on larger packets anyway
+	maxPacketSize = 256 * 1024
+	// statVFSBlockSize is the block size reported by statvfs@openssh.com
+	statVFSBlockSize = 4096
+)
+
+var errBadPacket = errors.New("bad sftp packet")
+
+// ServeChannel is sftpd.ServeChannel, but the packets sftpd doesn't handle,
+// SSH_FXP_SYMLINK and the extensions of OpenSSH, are answered by fs
+func ServeChannel(c ssh.Channel, fs sftpd.FileSystem, debugf sftpd.DebugLogger) error {
+	if d, ok := fs.(*DriverAdapter); ok {
+		c = &extChannel{Channel: c, fs: d}
+	}
+	return sftpd.ServeChannel(c, fs, debugf)
+}
+
+// extChannel answers the packets which sftpd doesn't handle and passes the
+// others to sftpd. sftpd reads the next packet only after it has replied to
+// the previous one, so the replies of extChannel never interleave with its
+type extChannel struct {
+	ssh.Channel
+	fs *DriverAdapter
+	// buf is the rest of the packet being read by sftpd
+	buf []byte
+}
+
+func (c *extChannel) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		pkt, err := c.readPacket()
+		if err != nil {
+			return 0, err
+		}
+		handled, err := c.handle(pkt)
+		if err != nil {
+			return 0, err
+		}
+		if !handled {
+			c.buf = pkt
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// readPacket reads a whole packet including its length
+func (c *extChannel) readPacket() ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.Channel, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > maxPacketSize {
+		return nil, errBadPacket
+	}
+	pkt := make([]byte, 4+length)
+	copy(pkt, header[:])
+	if _, err := io.ReadFull(c.Channel, pkt[5:]); err != nil {
+		return nil, err
+	}
+	return pkt, nil
+}
+
+func (c *extChannel) handle(pkt []byte) (bool, error) {
+	p := &packetParser{buf: pkt[5:]}
+	switch pkt[4] {
+	case SSH_FXP_INIT:
+		// the version 3 of sftpd, with the extensions
+		reply := newPacket(SSH_FXP_VERSION).uint32(3).
+			string("statvfs@openssh.com").string("2")
+		return true, c.write(reply)
+	case SSH_FXP_SYMLINK:
+		// OpenSSH sends the target before the link path, against the draft
+		id, target, path := p.uint32(), p.string(), p.string()
+		if p.err() != nil {
+			return true, p.err()
+		}
+		return true, c.writeStatus(id, c.fs.CreateLink(path, target, 0))
+	case SSH_FXP_EXTENDED:
+		id, name := p.uint32(), p.string()
+		if p.err() != nil {
+			return true, p.err()
+		}
+		switch name {
+		case "statvfs@openssh.com":
+			path := p.string()
+			if p.err() != nil {
+				return true, p.err()
+			}
+			details, err := c.fs.StatVFS(path)
+			if err != nil {
+				return true, c.writeStatus(id, err)
+			}
+			blocks := details.TotalSpace / statVFSBlockSize
+			free := details.FreeSpace / statVFSBlockSize
+			reply := newPacket(SSH_FXP_EXTENDED_REPLY).uint32(id).
+				uint64(statVFSBlockSize).uint64(statVFSBlockSize). // f_bsize, f_frsize
+				uint64(blocks).uint64(free).uint64(free).          // f_blocks, f_bfree, f_bavail
+				uint64(0).uint64(0).uint64(0).                     // f_files, f_ffree, f_favail
+				uint64(0).uint64(0).uint64(255)                    // f_fsid, f_flag, f_namemax
+			return true, c.write(reply)
+		default:
+			// sftpd doesn't reply to the unknown packets, so the clients would hang
+			return true, c.writeStatus(id, errs.NotSupport)
+		}
+	}
+	return false, nil
+}
+
+func (c *extChannel) write(pkt *packet) error {
+	_, err := c.Channel.Write(pkt.bytes())
+	return err
+}
+
+func (c *extChannel) writeStatus(id uint32, err error) error {
+	var code uint32
+	switch {
+	case err == nil:
+		code = SSH_FX_OK
+	case errors.Is(err, errs.NotSupport) || errors.Is(err, errs.NotImplement):
+		code = SSH_FX_OP_UNSUPPORTED
+	case errs.IsObjectNotFound(err):
+		code = SSH_FX_NO_SUCH_FILE
+	case errors.Is(err, errs.PermissionDenied):
+		code = SSH_FX_PERMISSION_DENIED
+	default:
+		code = SSH_FX_FAILURE
+	}
+	// the error message and the language tag are empty like sftpd
+	return c.write(newPacket(SSH_FXP_STATUS).uint32(id).uint32(code).string("").string(""))
+}
+
+type packet struct {
+	buf []byte
+}
+
+func newPacket(typ byte) *packet {
+	return &packet{buf: []byte{0, 0, 0, 0, typ}}
+}
+
+func (p *packet) uint32(v uint32) *packet {
+	p.buf = binary.BigEndian.AppendUint32(p.buf, v)
+	return p
+}
+
+func (p *packet) uint64(v uint64) *packet {
+	p.buf = binary.BigEndian.AppendUint64(p.buf, v)
+	return p
+}
+
+func (p *packet) string(v string) *packet {
+	p.buf = binary.BigEndian.AppendUint32(p.buf, uint32(len(v)))
+	p.buf = append(p.buf, v...)
+	return p
+}
+
+// bytes returns the packet with its length filled
+func (p *packet) bytes() []byte {
+	binary.BigEndian.PutUint32(p.buf, uint32(len(p.buf)-4))
+	return p.buf
+}
+
+// packetParser reads the fields of a packet, err returns errBadPacket if any
+// field is out of the packet
+type packetParser struct {
+	buf []byte
+	bad bool
+}
+
+func (p *packetParser) uint32() uint32 {
+	if len(p.buf) < 4 {
+		p.bad = true
+		return 0
+	}
+	v := binary.BigEndian.Uint32(p.buf)
+	p.buf = p.buf[4:]
+	return v
+}
+
+func (p *packetParser) string() string {
+	n := p.uint32()
+	if uint64(len(p.buf)) < uint64(n) {
+		p.bad = true
+		return ""
+	}
+	v := string(p.buf[:n])
+	p.buf = p.buf[n:]
+	return v
+}
+
+func (p *packetParser) err() error {
+	if p.bad {
+		return errBadPacket
+	}
+	return nil
+}
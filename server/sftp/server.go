@@ -0,0 +1,104 @@
+package sftp
+
+import (
+	"net"
+	"sync"
+
+	"github.com/OpenListTeam/sftpd-openlist"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// Server is sftpd.SftpServer, but the channels are served by ServeChannel
+type Server struct {
+	driver   sftpd.SftpDriver
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+func NewServer(driver sftpd.SftpDriver) *Server {
+	return &Server{driver: driver}
+}
+
+func (s *Server) RunServer() error {
+	listener, err := net.Listen("tcp", s.driver.GetConfig().HostPort)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+	s.driver.Close()
+	return nil
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	config := s.driver.GetConfig()
+	sc, chans, reqs, err := ssh.NewServerConn(conn, &config.ServerConfig)
+	if err != nil {
+		s.logError("sftpd connection error:", err)
+		return
+	}
+	defer func() { _ = sc.Close() }()
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			s.logError("sftpd connection error:", err)
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := sftpd.IsSftpRequest(req)
+				if ok {
+					go s.serveChannel(sc, channel)
+				}
+				_ = req.Reply(ok, nil)
+			}
+		}()
+	}
+}
+
+func (s *Server) serveChannel(sc *ssh.ServerConn, channel ssh.Channel) {
+	config := s.driver.GetConfig()
+	debugf := config.DebugLogFunc
+	if debugf == nil {
+		debugf = func(string, ...interface{}) {}
+	}
+	fs, err := s.driver.GetFileSystem(sc)
+	if err == nil {
+		err = ServeChannel(channel, fs, debugf)
+	}
+	if err != nil {
+		s.logError("sftpd servechannel failed:", err)
+	}
+}
+
+func (s *Server) logError(v ...interface{}) {
+	if f := s.driver.GetConfig().ErrorLogFunc; f != nil {
+		f(v...)
+	}
+}
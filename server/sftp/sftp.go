@@ -12,6 +12,9 @@ import (
 
 type DriverAdapter struct {
 	FtpDriver *ftp.AferoAdapter
+	// uploads are the open uploads by path, the packets of a channel are
+	// handled one by one, so it needs no lock
+	uploads map[string]*ftp.FileUploadProxy
 }
 
 func (s *DriverAdapter) OpenFile(_ string, _ uint32, _ *sftpd.Attr) (sftpd.File, error) {
@@ -48,16 +51,31 @@ func (s *DriverAdapter) Stat(name string, _ bool) (*sftpd.Attr, error) {
 	return fileInfoToSftpAttr(stat), nil
 }
 
-func (s *DriverAdapter) SetStat(_ string, _ *sftpd.Attr) error {
-	return errs.NotSupport
+func (s *DriverAdapter) SetStat(name string, attr *sftpd.Attr) error {
+	// only the modification time can be kept by the drivers, the others are ignored
+	if attr.Flags&sftpd.ATTR_TIME == 0 {
+		return nil
+	}
+	if u, ok := s.uploads[utils.FixAndCleanPath(name)]; ok {
+		// OpenSSH sets the time of the uploaded file before closing it
+		u.SetModTime(attr.MTime)
+		return nil
+	}
+	return s.FtpDriver.Chtimes(name, attr.ATime, attr.MTime)
+}
+
+func (s *DriverAdapter) ReadLink(name string) (string, error) {
+	return s.FtpDriver.ReadlinkIfPossible(name)
 }
 
-func (s *DriverAdapter) ReadLink(_ string) (string, error) {
-	return "", errs.NotSupport
+func (s *DriverAdapter) CreateLink(path, target string, _ uint32) error {
+	return s.FtpDriver.SymlinkIfPossible(target, path)
 }
 
-func (s *DriverAdapter) CreateLink(_, _ string, _ uint32) error {
-	return errs.NotSupport
+// StatVFS returns the space of the storage which name is in, for the
+// statvfs@openssh.com extension
+func (s *DriverAdapter) StatVFS(name string) (*model.StorageDetails, error) {
+	return s.FtpDriver.StorageDetails(name)
 }
 
 func (s *DriverAdapter) RealPath(path string) (string, error) {
@@ -65,7 +83,34 @@ func (s *DriverAdapter) RealPath(path string) (string, error) {
 }
 
 func (s *DriverAdapter) GetHandle(name string, flags uint32, _ *sftpd.Attr, offset uint64) (sftpd.FileTransfer, error) {
-	return s.FtpDriver.GetHandle(name, sftpFlagToOpenMode(flags), int64(offset))
+	t, err := s.FtpDriver.GetHandle(name, sftpFlagToOpenMode(flags), int64(offset))
+	if err != nil {
+		return nil, err
+	}
+	u, ok := t.(*ftp.FileUploadProxy)
+	if !ok {
+		return t, nil
+	}
+	if s.uploads == nil {
+		s.uploads = make(map[string]*ftp.FileUploadProxy)
+	}
+	name = utils.FixAndCleanPath(name)
+	s.uploads[name] = u
+	return &uploadHandle{FileUploadProxy: u, name: name, adapter: s}, nil
+}
+
+// uploadHandle forgets the upload in DriverAdapter.uploads when it's closed
+type uploadHandle struct {
+	*ftp.FileUploadProxy
+	name    string
+	adapter *DriverAdapter
+}
+
+func (h *uploadHandle) Close() error {
+	if h.adapter.uploads[h.name] == h.FileUploadProxy {
+		delete(h.adapter.uploads, h.name)
+	}
+	return h.FileUploadProxy.Close()
 }
 
 func (s *DriverAdapter) ReadDir(name string) ([]sftpd.NamedAttr, error) {
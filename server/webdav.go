@@ -14,8 +14,10 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/setting"
+	"github.com/OpenListTeam/OpenList/v4/internal/webhook"
 	"github.com/OpenListTeam/OpenList/v4/server/webdav"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -24,7 +26,7 @@ var handler *webdav.Handler
 func WebDav(dav *gin.RouterGroup) {
 	handler = &webdav.Handler{
 		Prefix:     path.Join(conf.URL.Path, "/dav"),
-		LockSystem: webdav.NewMemLS(),
+		LockSystem: newLockSystem(),
 		Logger: func(request *http.Request, err error) {
 			log.Errorf("%s %s %+v", request.Method, request.URL.Path, err)
 		},
@@ -44,11 +46,46 @@ func WebDav(dav *gin.RouterGroup) {
 	dav.Handle("MOVE", "/*path", ServeWebDAV)
 }
 
+func newLockSystem() webdav.LockSystem {
+	switch conf.Conf.WebDAV.LockSystem {
+	case "database":
+		return webdav.NewDBLS()
+	case "", "memory":
+	default:
+		log.Warnf("unknown webdav lock system [%s], use memory instead", conf.Conf.WebDAV.LockSystem)
+	}
+	return webdav.NewMemLS()
+}
+
 func ServeWebDAV(c *gin.Context) {
 	handler.ServeHTTP(c.Writer, c.Request)
 }
 
+// webdavUser checks the password of username, an api token of the user is also
+// accepted as the password, and the username can be omitted for it
+func webdavUser(username, password string) (*model.User, error) {
+	if model.IsAPIToken(password) {
+		user, err := op.ValidateAPIToken(password)
+		if err != nil {
+			return nil, err
+		}
+		if username != "" && user.Username != username {
+			return nil, errors.New("api token doesn't belong to the user")
+		}
+		return user, nil
+	}
+	user, err := op.GetUserByName(username)
+	if err != nil {
+		return nil, err
+	}
+	if err = user.ValidateRawPassword(password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func WebDAVAuth(c *gin.Context) {
+	common.GinWithValue(c, conf.ProtocolKey, "webdav")
 	// check count of login
 	ip := c.ClientIP()
 	guest, _ := op.GetGuest()
@@ -83,25 +120,32 @@ func WebDAVAuth(c *gin.Context) {
 				c.Next()
 				return
 			}
+			// an api token is checked like a password, but without the username
+			if model.IsAPIToken(bt) {
+				password, ok = bt, true
+			}
 		}
-		if c.Request.Method == "OPTIONS" {
-			common.GinWithValue(c, conf.UserKey, guest)
-			c.Next()
+		if !ok {
+			if c.Request.Method == "OPTIONS" {
+				common.GinWithValue(c, conf.UserKey, guest)
+				c.Next()
+				return
+			}
+			c.Writer.Header()["WWW-Authenticate"] = []string{`Basic realm="openlist"`}
+			c.Status(http.StatusUnauthorized)
+			c.Abort()
 			return
 		}
-		c.Writer.Header()["WWW-Authenticate"] = []string{`Basic realm="openlist"`}
-		c.Status(http.StatusUnauthorized)
-		c.Abort()
-		return
 	}
-	user, err := op.GetUserByName(username)
-	if err != nil || user.ValidateRawPassword(password) != nil {
+	user, err := webdavUser(username, password)
+	if err != nil {
 		if c.Request.Method == "OPTIONS" {
 			common.GinWithValue(c, conf.UserKey, guest)
 			c.Next()
 			return
 		}
 		model.LoginCache.Set(ip, count+1)
+		webhook.EmitLoginFailed(username, ip, "webdav")
 		c.Status(http.StatusUnauthorized)
 		c.Abort()
 		return
@@ -118,25 +162,18 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	if (c.Request.Method == "PUT" || c.Request.Method == "MKCOL") && (!user.CanWebdavManage() || !user.CanWrite()) {
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
-	}
-	if c.Request.Method == "MOVE" && (!user.CanWebdavManage() || (!user.CanMove() && !user.CanRename())) {
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
-	}
-	if c.Request.Method == "COPY" && (!user.CanWebdavManage() || !user.CanCopy()) {
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
-	}
-	if c.Request.Method == "DELETE" && (!user.CanWebdavManage() || !user.CanRemove()) {
-		c.Status(http.StatusForbidden)
-		c.Abort()
-		return
+	// the permissions of the methods are checked with the acl rules of the paths
+	// by the handlers in server/webdav, since the rules may allow them to the
+	// user without the permissions: CanWrite of PUT, MKCOL, COPY and MOVE by
+	// canWrite, CanMove and CanRename of MOVE, CanCopy of COPY and CanRemove of
+	// DELETE by canOperate
+	switch c.Request.Method {
+	case "PUT", "MKCOL", "MOVE", "COPY", "DELETE":
+		if !user.CanWebdavManage() {
+			c.Status(http.StatusForbidden)
+			c.Abort()
+			return
+		}
 	}
 	if c.Request.Method == "PROPPATCH" && !user.CanWebdavManage() {
 		c.Status(http.StatusForbidden)
@@ -0,0 +1,177 @@
+package webdav
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NewDBLS returns a LockSystem that stores the locks in the database, so they
+// survive restarts and are shared by all instances using the same database.
+//
+// The locks held by Confirm are only tracked in memory, as they are released
+// at the end of each request.
+func NewDBLS() LockSystem {
+	return &dbLS{held: make(map[string]struct{})}
+}
+
+type dbLS struct {
+	mu   sync.Mutex
+	held map[string]struct{}
+}
+
+func (m *dbLS) Confirm(now time.Time, name0, name1 string, conditions ...Condition) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var t0, t1 string
+	var err error
+	if name0 != "" {
+		if t0, err = m.lookup(now, slashClean(name0), conditions...); err != nil || t0 == "" {
+			return nil, confirmErr(err)
+		}
+	}
+	if name1 != "" {
+		if t1, err = m.lookup(now, slashClean(name1), conditions...); err != nil || t1 == "" {
+			return nil, confirmErr(err)
+		}
+	}
+
+	// Don't hold the same lock twice.
+	if t1 == t0 {
+		t1 = ""
+	}
+
+	if t0 != "" {
+		m.held[t0] = struct{}{}
+	}
+	if t1 != "" {
+		m.held[t1] = struct{}{}
+	}
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.held, t0)
+		delete(m.held, t1)
+	}, nil
+}
+
+func noSuchLockErr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNoSuchLock
+	}
+	return err
+}
+
+func confirmErr(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrConfirmationFailed
+}
+
+// lookup returns the token of the lock that locks the named resource, provided
+// that the lock matches at least one of the given conditions and isn't held by
+// another party. Otherwise, it returns an empty token.
+func (m *dbLS) lookup(now time.Time, name string, conditions ...Condition) (string, error) {
+	// TODO: support Condition.Not and Condition.ETag.
+	for _, c := range conditions {
+		if c.Token == "" {
+			continue
+		}
+		if _, ok := m.held[c.Token]; ok {
+			continue
+		}
+		l, err := db.GetWebDAVLockByToken(c.Token, now)
+		if err != nil {
+			continue
+		}
+		if name == l.Root {
+			return l.Token, nil
+		}
+		if l.ZeroDepth {
+			continue
+		}
+		if l.Root == "/" || strings.HasPrefix(name, l.Root+"/") {
+			return l.Token, nil
+		}
+	}
+	return "", nil
+}
+
+func (m *dbLS) Create(now time.Time, details LockDetails) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	details.Root = slashClean(details.Root)
+	var ancestors []string
+	walkToRoot(details.Root, func(name0 string, first bool) bool {
+		if !first {
+			ancestors = append(ancestors, name0)
+		}
+		return true
+	})
+	l := &model.WebDAVLock{
+		Token:     "opaquelocktoken:" + uuid.NewString(),
+		Root:      details.Root,
+		Duration:  details.Duration,
+		OwnerXML:  details.OwnerXML,
+		ZeroDepth: details.ZeroDepth,
+	}
+	if l.Duration >= 0 {
+		l.Expiry = now.Add(l.Duration)
+	}
+	created, err := db.CreateWebDAVLock(l, ancestors, now)
+	if err != nil {
+		return "", err
+	}
+	if !created {
+		return "", ErrLocked
+	}
+	return l.Token, nil
+}
+
+func (m *dbLS) Refresh(now time.Time, token string, duration time.Duration) (LockDetails, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, err := db.GetWebDAVLockByToken(token, now)
+	if err != nil {
+		return LockDetails{}, noSuchLockErr(err)
+	}
+	if _, ok := m.held[token]; ok {
+		return LockDetails{}, ErrLocked
+	}
+	l.Duration = duration
+	if l.Duration >= 0 {
+		l.Expiry = now.Add(l.Duration)
+	}
+	if err = db.UpdateWebDAVLock(l); err != nil {
+		return LockDetails{}, err
+	}
+	return LockDetails{
+		Root:      l.Root,
+		Duration:  l.Duration,
+		OwnerXML:  l.OwnerXML,
+		ZeroDepth: l.ZeroDepth,
+	}, nil
+}
+
+func (m *dbLS) Unlock(now time.Time, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := db.GetWebDAVLockByToken(token, now); err != nil {
+		return noSuchLockErr(err)
+	}
+	if _, ok := m.held[token]; ok {
+		return ErrLocked
+	}
+	return db.DeleteWebDAVLockByToken(token)
+}
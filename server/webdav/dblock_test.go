@@ -0,0 +1,164 @@
+package webdav
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func initTestDB(t *testing.T) {
+	dB, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	// every connection of an in-memory sqlite has its own database
+	sqlDB, _ := dB.DB()
+	sqlDB.SetMaxOpenConns(1)
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestDBLSCanCreate(t *testing.T) {
+	initTestDB(t)
+	now := time.Unix(0, 0)
+	m := NewDBLS()
+
+	for _, name := range lockTestNames {
+		_, err := m.Create(now, LockDetails{
+			Root:      name,
+			Duration:  infiniteTimeout,
+			ZeroDepth: lockTestZeroDepth(name),
+		})
+		if err != nil {
+			t.Fatalf("creating lock for %q: %v", name, err)
+		}
+	}
+
+	wantCanCreate := func(name string, zeroDepth bool) bool {
+		for _, n := range lockTestNames {
+			switch {
+			case n == name:
+				return false
+			case strings.HasPrefix(n, name):
+				if !zeroDepth {
+					return false
+				}
+			case strings.HasPrefix(name, n):
+				if n[len(n)-1] == 'i' {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	var check func(int, string)
+	check = func(recursion int, name string) {
+		for _, zeroDepth := range []bool{false, true} {
+			token, err := m.Create(now, LockDetails{Root: name, Duration: infiniteTimeout, ZeroDepth: zeroDepth})
+			got := err == nil
+			if err != nil && err != ErrLocked {
+				t.Fatalf("creating lock for %q: %v", name, err)
+			}
+			if want := wantCanCreate(name, zeroDepth); got != want {
+				t.Errorf("canCreate name=%q zeroDepth=%t: got %t, want %t", name, zeroDepth, got, want)
+			}
+			if got {
+				if err = m.Unlock(now, token); err != nil {
+					t.Fatalf("unlocking %q: %v", name, err)
+				}
+			}
+		}
+		if recursion == 3 {
+			return
+		}
+		if name != "/" {
+			name += "/"
+		}
+		for _, c := range "_iz" {
+			check(recursion+1, name+string(c))
+		}
+	}
+	check(0, "/")
+}
+
+func TestDBLSExpiry(t *testing.T) {
+	initTestDB(t)
+	now := time.Unix(0, 0)
+	m := NewDBLS()
+
+	token, err := m.Create(now, LockDetails{Root: "/a", Duration: time.Minute})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	release, err := m.Confirm(now, "/a/b", "", Condition{Token: token})
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if _, err = m.Refresh(now, token, time.Minute); err != ErrLocked {
+		t.Fatalf("Refresh a held lock: got %v, want %v", err, ErrLocked)
+	}
+	release()
+	if _, err = m.Refresh(now.Add(30*time.Second), token, time.Minute); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if _, err = m.Create(now.Add(time.Minute), LockDetails{Root: "/a", Duration: time.Minute}); err != ErrLocked {
+		t.Fatalf("Create a locked name: got %v, want %v", err, ErrLocked)
+	}
+	if err = m.Unlock(now.Add(2*time.Minute), token); err != ErrNoSuchLock {
+		t.Fatalf("Unlock an expired lock: got %v, want %v", err, ErrNoSuchLock)
+	}
+	if _, err = m.Create(now.Add(2*time.Minute), LockDetails{Root: "/a", Duration: time.Minute}); err != nil {
+		t.Fatalf("Create after expiry: %v", err)
+	}
+}
+
+func TestDBLSCreateConcurrently(t *testing.T) {
+	// a file database, so that the instances below use their own connections
+	dsn := filepath.Join(t.TempDir(), "data.db") + "?_busy_timeout=5000"
+	dB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+	now := time.Unix(0, 0)
+	// the instances sharing the database
+	instances := []LockSystem{NewDBLS(), NewDBLS(), NewDBLS(), NewDBLS()}
+	// each of them conflicts with the others
+	roots := []string{"/a", "/a", "/a/b", "/a/b/c", "/"}
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := instances[i%len(instances)].Create(now, LockDetails{
+				Root:     roots[i%len(roots)],
+				Duration: time.Minute,
+			})
+			switch err {
+			case nil:
+				created.Add(1)
+			case ErrLocked:
+			default:
+				t.Errorf("Create: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := created.Load(); n != 1 {
+		t.Errorf("created %d conflicting locks, want 1", n)
+	}
+}
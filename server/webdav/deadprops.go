@@ -0,0 +1,106 @@
+package webdav
+
+import (
+	"encoding/xml"
+	"net/http"
+	"path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+// dbDeadProps holds the dead properties of the object at path in the database,
+// they are read through cache if it's not nil
+type dbDeadProps struct {
+	path  string
+	cache *deadPropsCache
+}
+
+// deadPropsCache loads the dead properties of a dir at once, so a PROPFIND
+// queries the database once per dir instead of once per object
+type deadPropsCache struct {
+	// the props by path by parent
+	dirs map[string]map[string][]model.WebDAVProp
+}
+
+func newDeadPropsCache() *deadPropsCache {
+	return &deadPropsCache{dirs: make(map[string]map[string][]model.WebDAVProp)}
+}
+
+func (c *deadPropsCache) get(name string) ([]model.WebDAVProp, error) {
+	parent := path.Dir(name)
+	dir, ok := c.dirs[parent]
+	if !ok {
+		props, err := op.GetWebDAVPropsByParent(parent)
+		if err != nil {
+			return nil, err
+		}
+		dir = make(map[string][]model.WebDAVProp)
+		for _, p := range props {
+			dir[p.Path] = append(dir[p.Path], p)
+		}
+		c.dirs[parent] = dir
+	}
+	return dir[name], nil
+}
+
+func (d dbDeadProps) DeadProps() (map[xml.Name]Property, error) {
+	var (
+		props []model.WebDAVProp
+		err   error
+	)
+	if d.cache != nil {
+		props, err = d.cache.get(d.path)
+	} else {
+		props, err = op.GetWebDAVProps(d.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(props) == 0 {
+		return nil, nil
+	}
+	ret := make(map[xml.Name]Property, len(props))
+	for _, p := range props {
+		name := xml.Name{Space: p.Space, Local: p.Local}
+		ret[name] = Property{
+			XMLName:  name,
+			Lang:     p.Lang,
+			InnerXML: []byte(p.InnerXML),
+		}
+	}
+	return ret, nil
+}
+
+func (d dbDeadProps) Patch(patches []Proppatch) ([]Propstat, error) {
+	var set, remove []model.WebDAVProp
+	pstat := Propstat{Status: http.StatusOK}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			prop := model.WebDAVProp{
+				Space: p.XMLName.Space,
+				Local: p.XMLName.Local,
+			}
+			if patch.Remove {
+				// a later remove wins over an earlier set of the same property
+				for i := len(set) - 1; i >= 0; i-- {
+					if set[i].Space == prop.Space && set[i].Local == prop.Local {
+						set = append(set[:i], set[i+1:]...)
+					}
+				}
+				remove = append(remove, prop)
+			} else {
+				prop.Lang = p.Lang
+				prop.InnerXML = string(p.InnerXML)
+				set = append(set, prop)
+			}
+			pstat.Props = append(pstat.Props, Property{XMLName: p.XMLName})
+		}
+	}
+	if err := op.PatchWebDAVProps(d.path, set, remove); err != nil {
+		return nil, err
+	}
+	return []Propstat{pstat}, nil
+}
+
+var _ DeadPropsHolder = dbDeadProps{}
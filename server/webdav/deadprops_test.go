@@ -0,0 +1,61 @@
+package webdav
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+)
+
+func TestDBDeadProps(t *testing.T) {
+	initTestDB(t)
+	foo := xml.Name{Space: "ns", Local: "foo"}
+	bar := xml.Name{Space: "ns", Local: "bar"}
+	_, err := dbDeadProps{path: "/a/b"}.Patch([]Proppatch{
+		{Props: []Property{{XMLName: foo, InnerXML: []byte("1")}, {XMLName: bar, InnerXML: []byte("2")}}},
+		{Remove: true, Props: []Property{{XMLName: bar}}},
+	})
+	if err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	if err = op.MoveWebDAVProps("/a", "/c"); err != nil {
+		t.Fatalf("MoveWebDAVProps: %v", err)
+	}
+	props, err := dbDeadProps{path: "/c/b"}.DeadProps()
+	if err != nil {
+		t.Fatalf("DeadProps: %v", err)
+	}
+	if len(props) != 1 || string(props[foo].InnerXML) != "1" {
+		t.Fatalf("DeadProps: got %v, want only %v", props, foo)
+	}
+	if props, err = (dbDeadProps{path: "/c/b", cache: newDeadPropsCache()}).DeadProps(); err != nil || len(props) != 1 {
+		t.Fatalf("DeadProps by the parent: got %v, %v, want only %v", props, err, foo)
+	}
+	if props, _ = (dbDeadProps{path: "/a/b"}).DeadProps(); len(props) != 0 {
+		t.Fatalf("DeadProps of the moved path: got %v, want none", props)
+	}
+	if err = op.DeleteWebDAVProps("/c"); err != nil {
+		t.Fatalf("DeleteWebDAVProps: %v", err)
+	}
+	if props, _ = (dbDeadProps{path: "/c/b"}).DeadProps(); len(props) != 0 {
+		t.Fatalf("DeadProps of the deleted path: got %v, want none", props)
+	}
+}
+
+func TestDBDeadPropsWildcards(t *testing.T) {
+	initTestDB(t)
+	foo := xml.Name{Space: "ns", Local: "foo"}
+	if _, err := (dbDeadProps{path: "/ab/c"}).Patch([]Proppatch{{Props: []Property{{XMLName: foo}}}}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	// the wildcards of LIKE in the paths are matched literally
+	if err := op.DeleteWebDAVProps("/a%"); err != nil {
+		t.Fatalf("DeleteWebDAVProps: %v", err)
+	}
+	if err := op.MoveWebDAVProps("/a_", "/d"); err != nil {
+		t.Fatalf("MoveWebDAVProps: %v", err)
+	}
+	if props, _ := (dbDeadProps{path: "/ab/c"}).DeadProps(); len(props) != 1 {
+		t.Fatalf("DeadProps: got %v, want only %v", props, foo)
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"path/filepath"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
 	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
 )
 
 // slashClean is equivalent to but slightly more efficient than
@@ -25,6 +27,33 @@ func slashClean(name string) string {
 	return path.Clean(name)
 }
 
+// canRead reports whether the user in ctx can read reqPath, by the acl rules
+// and the hides of the nearest meta. The passwords of the metas aren't checked,
+// since webdav can't ask for them
+func canRead(ctx context.Context, reqPath string) bool {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	if allowed, matched := op.CheckACL(user, reqPath, model.ACLRead); matched && !allowed {
+		return false
+	}
+	meta, _ := op.GetNearestMeta(reqPath)
+	return !common.IsHidden(user, meta, reqPath)
+}
+
+// canWrite reports whether the user in ctx can create reqPath in its parent
+func canWrite(ctx context.Context, reqPath string) bool {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	dir := path.Dir(reqPath)
+	meta, _ := op.GetNearestMeta(dir)
+	return canRead(ctx, reqPath) && common.CanWrite(user, meta, dir)
+}
+
+// canOperate reports whether the user in ctx can do operation on reqPath,
+// permitted is the permission of the user for it
+func canOperate(ctx context.Context, reqPath string, operation int32, permitted bool) bool {
+	user := ctx.Value(conf.UserKey).(*model.User)
+	return canRead(ctx, reqPath) && common.CanOperate(user, reqPath, operation, permitted)
+}
+
 // moveFiles moves files and/or directories from src to dst.
 //
 // See section 9.9.4 for when various HTTP status codes apply.
@@ -34,12 +63,16 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 	srcName := path.Base(src)
 	dstName := path.Base(dst)
 	user := ctx.Value(conf.UserKey).(*model.User)
-	if srcDir != dstDir && !user.CanMove() {
+	if srcDir != dstDir && (!canOperate(ctx, src, model.ACLMove, user.CanMove()) || !canWrite(ctx, dst)) {
 		return http.StatusForbidden, nil
 	}
-	if srcName != dstName && !user.CanRename() {
+	if srcName != dstName && !canOperate(ctx, src, model.ACLRename, user.CanRename()) {
 		return http.StatusForbidden, nil
 	}
+	existed, status, err := overwriteDst(ctx, dst, overwrite)
+	if status != 0 {
+		return status, err
+	}
 	if srcDir == dstDir {
 		err = fs.Rename(ctx, src, dstName)
 	} else {
@@ -54,7 +87,9 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
-	// TODO if there are no files copy, should return 204
+	if existed {
+		return http.StatusNoContent, nil
+	}
 	return http.StatusCreated, nil
 }
 
@@ -63,14 +98,49 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 // See section 9.8.5 for when various HTTP status codes apply.
 func copyFiles(ctx context.Context, src, dst string, overwrite bool) (status int, err error) {
 	dstDir := path.Dir(dst)
+	user := ctx.Value(conf.UserKey).(*model.User)
+	if !canOperate(ctx, src, model.ACLCopy, user.CanCopy()) || !canWrite(ctx, dst) {
+		return http.StatusForbidden, nil
+	}
+	existed, status, err := overwriteDst(ctx, dst, overwrite)
+	if status != 0 {
+		return status, err
+	}
 	_, err = fs.Copy(context.WithValue(ctx, conf.NoTaskKey, struct{}{}), src, dstDir)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
-	// TODO if there are no files copy, should return 204
+	if existed {
+		return http.StatusNoContent, nil
+	}
 	return http.StatusCreated, nil
 }
 
+// overwriteDst removes the existing dst before it's overwritten by a move or
+// copy, the status is non-zero if the move or copy can't go on. The dst is
+// removed permanently, since it's replaced rather than deleted by the user.
+//
+// See sections 9.8.4 and 9.9.3.
+func overwriteDst(ctx context.Context, dst string, overwrite bool) (existed bool, status int, err error) {
+	if _, err := fs.Get(ctx, dst, &fs.GetArgs{NoLog: true}); err != nil {
+		if errs.IsObjectNotFound(err) {
+			return false, 0, nil
+		}
+		return false, http.StatusInternalServerError, err
+	}
+	if !overwrite {
+		return true, http.StatusPreconditionFailed, nil
+	}
+	user := ctx.Value(conf.UserKey).(*model.User)
+	if !canOperate(ctx, dst, model.ACLRemove, user.CanRemove()) {
+		return true, http.StatusForbidden, nil
+	}
+	if err := fs.RemovePermanently(ctx, dst); err != nil {
+		return true, http.StatusInternalServerError, err
+	}
+	return true, 0, nil
+}
+
 // walkFS traverses filesystem fs starting at name up to depth levels.
 //
 // Allowed values for depth are 0, 1 or infiniteDepth. For each visited node,
@@ -121,3 +191,32 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	}
 	return nil
 }
+
+// walkVirtualRoot walks the virtual root of user, which is made up of its base paths
+func walkVirtualRoot(ctx context.Context, user *model.User, depth int, walkFn func(reqPath string, info model.Obj, err error) error) error {
+	err := walkFn("/", &model.Object{Name: "root", IsFolder: true}, nil)
+	if err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	if depth == 0 {
+		return nil
+	}
+	if depth == 1 {
+		depth = 0
+	}
+	for _, basePath := range user.BasePaths {
+		info, err := fs.Get(ctx, basePath, &fs.GetArgs{NoLog: true})
+		if err != nil {
+			// the base paths that don't exist are not shown
+			continue
+		}
+		err = walkFS(ctx, depth, basePath, info, walkFn)
+		if err != nil && (!info.IsDir() || err != filepath.SkipDir) {
+			return err
+		}
+	}
+	return nil
+}
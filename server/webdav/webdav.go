@@ -14,6 +14,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/OpenListTeam/OpenList/v4/internal/errs"
 	"github.com/OpenListTeam/OpenList/v4/internal/fs"
 	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
 	"github.com/OpenListTeam/OpenList/v4/internal/sign"
 	"github.com/OpenListTeam/OpenList/v4/pkg/utils"
 	"github.com/OpenListTeam/OpenList/v4/server/common"
@@ -197,6 +199,12 @@ func (h *Handler) handleOptions(w http.ResponseWriter, r *http.Request) (status
 	}
 	ctx := r.Context()
 	user := ctx.Value(conf.UserKey).(*model.User)
+	if user.IsVirtualRoot(reqPath) {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND")
+		w.Header().Set("DAV", "1, 2")
+		w.Header().Set("MS-Author-Via", "DAV")
+		return 0, nil
+	}
 	reqPath, err = user.JoinPath(reqPath)
 	if err != nil {
 		return 403, err
@@ -229,6 +237,9 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	if err != nil {
 		return http.StatusForbidden, err
 	}
+	if !canRead(ctx, reqPath) {
+		return http.StatusForbidden, nil
+	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
 	if err != nil {
 		return http.StatusNotFound, err
@@ -245,6 +256,7 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	downProxyUrl := storage.GetStorage().DownProxyUrl
 	if storage.GetStorage().WebdavNative() || (storage.GetStorage().WebdavProxy() && downProxyUrl == "") {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{Header: r.Header})
+		op.Audit(ctx, model.AuditDownload, err, reqPath)
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -268,6 +280,7 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		http.Redirect(w, r, u, http.StatusFound)
 	} else {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{IP: utils.ClientIP(r), Header: r.Header, Redirect: true})
+		op.Audit(ctx, model.AuditDownload, err, reqPath)
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -294,6 +307,9 @@ func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) (status i
 	if err != nil {
 		return 403, err
 	}
+	if !canOperate(ctx, reqPath, model.ACLRemove, user.CanRemove()) {
+		return http.StatusForbidden, nil
+	}
 	// TODO: return MultiStatus where appropriate.
 
 	// "godoc os RemoveAll" says that "If the path does not exist, RemoveAll
@@ -339,6 +355,9 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	if err != nil {
 		return http.StatusForbidden, err
 	}
+	if !canWrite(ctx, reqPath) {
+		return http.StatusForbidden, nil
+	}
 	obj := model.Object{
 		Name:     path.Base(reqPath),
 		Size:     r.ContentLength,
@@ -391,6 +410,9 @@ func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request) (status in
 	if err != nil {
 		return 403, err
 	}
+	if !canWrite(ctx, reqPath) {
+		return http.StatusForbidden, nil
+	}
 
 	if r.ContentLength > 0 {
 		return http.StatusUnsupportedMediaType, nil
@@ -635,16 +657,23 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	userAgent := r.Header.Get("User-Agent")
 	ctx = context.WithValue(ctx, conf.UserAgentKey, userAgent)
 	user := ctx.Value(conf.UserKey).(*model.User)
-	reqPath, err = user.JoinPath(reqPath)
-	if err != nil {
-		return 403, err
-	}
-	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
-	if err != nil {
-		if errs.IsNotFoundError(err) {
-			return http.StatusNotFound, err
+	virtualRoot := user.IsVirtualRoot(reqPath)
+	var fi model.Obj
+	if !virtualRoot {
+		reqPath, err = user.JoinPath(reqPath)
+		if err != nil {
+			return 403, err
+		}
+		if !canRead(ctx, reqPath) {
+			return http.StatusForbidden, nil
+		}
+		fi, err = fs.Get(ctx, reqPath, &fs.GetArgs{})
+		if err != nil {
+			if errs.IsNotFoundError(err) {
+				return http.StatusNotFound, err
+			}
+			return http.StatusMethodNotAllowed, err
 		}
-		return http.StatusMethodNotAllowed, err
 	}
 	depth := infiniteDepth
 	if hdr := r.Header.Get("Depth"); hdr != "" {
@@ -659,14 +688,22 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	}
 
 	mw := multistatusWriter{w: w}
+	dead := newDeadPropsCache()
 
 	walkFn := func(reqPath string, info model.Obj, err error) error {
 		if err != nil {
 			return err
 		}
+		if !canRead(ctx, reqPath) {
+			// hide the entries which the user can't read, and don't walk into them
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		var pstats []Propstat
 		if pf.Propname != nil {
-			pnames, err := propnames(ctx, h.LockSystem, info)
+			pnames, err := propnames(ctx, h.LockSystem, dead, reqPath, info)
 			if err != nil {
 				return err
 			}
@@ -676,21 +713,31 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 			}
 			pstats = append(pstats, pstat)
 		} else if pf.Allprop != nil {
-			pstats, err = allprop(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = allprop(ctx, h.LockSystem, dead, reqPath, info, pf.Prop)
 		} else {
-			pstats, err = props(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = props(ctx, h.LockSystem, dead, reqPath, info, pf.Prop)
 		}
 		if err != nil {
 			return err
 		}
-		href := path.Join(h.Prefix, strings.TrimPrefix(reqPath, user.BasePath))
+		relPath, ok := user.RelPath(reqPath)
+		if !ok {
+			// it's the virtual root
+			relPath = "/"
+		}
+		href := path.Join(h.Prefix, relPath)
 		if href != "/" && info.IsDir() {
 			href += "/"
 		}
 		return mw.write(makePropstatResponse(href, pstats))
 	}
 
-	walkErr := walkFS(ctx, depth, reqPath, fi, walkFn)
+	var walkErr error
+	if virtualRoot {
+		walkErr = walkVirtualRoot(ctx, user, depth, walkFn)
+	} else {
+		walkErr = walkFS(ctx, depth, reqPath, fi, walkFn)
+	}
 	closeErr := mw.close()
 	if walkErr != nil {
 		return http.StatusInternalServerError, walkErr
@@ -718,6 +765,9 @@ func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request) (statu
 	if err != nil {
 		return 403, err
 	}
+	if !canRead(ctx, reqPath) {
+		return http.StatusForbidden, nil
+	}
 	if _, err := fs.Get(ctx, reqPath, &fs.GetArgs{}); err != nil {
 		if errs.IsObjectNotFound(err) {
 			return http.StatusNotFound, err